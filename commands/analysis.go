@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"fmt"
+	"math/cmplx"
+	"sort"
+	"strconv"
+
+	"qmachine/quantum"
+)
+
+// entanglementPairsShown is how many of the most correlated qubit pairs entanglement-map lists
+const entanglementPairsShown = 5
+
+// HandleEntanglementMap prints the pairwise mutual information (in bits) between all qubits as a
+// matrix, followed by the most correlated pairs
+func (h *Handler) HandleEntanglementMap(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("entanglement-map is exclusive to VM execution mode")
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: entanglement-map")
+	}
+	info, err := h.machine.GetState().EntanglementMap()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Pairwise mutual information (bits; 2 = maximally entangled pair):")
+	fmt.Print("     ")
+	for b := range info {
+		fmt.Printf(" %6s", fmt.Sprintf("q%d", b))
+	}
+	fmt.Println()
+	for a, row := range info {
+		fmt.Printf("%5s", fmt.Sprintf("q%d", a))
+		for b, value := range row {
+			if a == b {
+				fmt.Printf(" %6s", "-")
+			} else {
+				fmt.Printf(" %6.3f", value)
+			}
+		}
+		fmt.Println()
+	}
+	printTopPairs(info)
+	return nil
+}
+
+// printTopPairs lists the qubit pairs with the highest mutual information, strongest first
+func printTopPairs(info [][]float64) {
+	type pair struct {
+		a, b  int
+		value float64
+	}
+	var pairs []pair
+	for a := range info {
+		for b := a + 1; b < len(info); b++ {
+			if info[a][b] > 1e-9 {
+				pairs = append(pairs, pair{a, b, info[a][b]})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		fmt.Println("No correlated qubit pairs: the state is a product state")
+		return
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].value > pairs[j].value })
+	if len(pairs) > entanglementPairsShown {
+		pairs = pairs[:entanglementPairsShown]
+	}
+	fmt.Println("Most correlated pairs:")
+	for _, p := range pairs {
+		fmt.Printf("  q%d-q%d: %.3f bits\n", p.a, p.b, p.value)
+	}
+}
+
+// HandleResourceEstimate reports the fault-tolerant cost metrics of the recorded circuit: the
+// T-count, CNOT count and total gate count
+func (h *Handler) HandleResourceEstimate(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("resource-estimate is exclusive to VM execution mode")
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: resource-estimate")
+	}
+	est := h.machine.EstimateResources()
+	fmt.Println("Resource estimate for the recorded circuit:")
+	fmt.Printf("  T-count:       %6d  (T and T†)\n", est.TCount)
+	fmt.Printf("  CNOT count:    %6d\n", est.CNOTCount)
+	fmt.Printf("  Clifford:      %6d  (other single-qubit X, Y, Z, H, S, S†)\n", est.Clifford)
+	fmt.Printf("  Rotations:     %6d  (arbitrary angles, many T gates each once synthesized)\n", est.Rotations)
+	fmt.Printf("  Other:         %6d  (other controlled gates)\n", est.Other)
+	fmt.Printf("  Total gates:   %6d\n", est.Total)
+	fmt.Printf("  Measurements:  %6d\n", est.Measurements)
+	return nil
+}
+
+// HandleStats reports the instructions executed, gates applied (by type) and measurements
+// performed since the machine was created or last reset
+func (h *Handler) HandleStats(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("stats is exclusive to VM execution mode")
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: stats")
+	}
+	stats := h.machine.GetStats()
+	fmt.Println("Execution statistics since the last reset:")
+	fmt.Printf("  Classical instructions: %8d\n", stats.ClassicalInstructions)
+	fmt.Printf("  Quantum instructions:   %8d\n", stats.QuantumInstructions)
+	fmt.Printf("  Gates applied:          %8d\n", stats.TotalGates())
+	names := make([]string, 0, len(stats.Gates))
+	for name := range stats.Gates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("    %-20s  %8d\n", name, stats.Gates[name])
+	}
+	fmt.Printf("  Measurements:           %8d\n", stats.Measurements)
+	return nil
+}
+
+// HandleArgmax reports the most probable basis state without collapsing the state or sampling
+func (h *Handler) HandleArgmax(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("argmax is exclusive to VM execution mode")
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: argmax")
+	}
+	state := h.machine.GetState()
+	if state.SupportSize() == 0 {
+		return fmt.Errorf("the state has no nonzero amplitudes")
+	}
+	basis, probability, ties := state.MostProbable()
+	uniqueness := "uniquely maximal"
+	if ties > 0 {
+		uniqueness = fmt.Sprintf("tied with %d other outcome(s)", ties)
+	}
+	fmt.Printf("Most probable outcome: %s with p=%.*f (%s)\n", h.ket(basis, state.NumQubits()), h.precision,
+		probability, uniqueness)
+	return nil
+}
+
+// defaultProbsShown is how many basis states probs lists unless given a count
+const defaultProbsShown = 16
+
+// HandleProbs lists the measurement probabilities |amp|^2 of the basis states, most probable
+// first, without collapsing or sampling. Amplitudes below the display threshold are skipped and
+// only the top k (default 16) states are shown, with a note of how many were omitted.
+func (h *Handler) HandleProbs(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("probs is exclusive to VM execution mode")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: probs [k]")
+	}
+	shown := defaultProbsShown
+	if len(args) == 1 {
+		k, err := strconv.Atoi(args[0])
+		if err != nil || k < 1 {
+			return fmt.Errorf("the number of states to show must be a positive integer")
+		}
+		shown = k
+	}
+
+	state := h.machine.GetState()
+	var listed []quantum.BasisAmplitude
+	for _, basis := range state.NonzeroAmplitudes() {
+		if cmplx.Abs(basis.Amplitude) >= h.threshold {
+			listed = append(listed, basis)
+		}
+	}
+	if len(listed) == 0 {
+		fmt.Println("No basis state above the display threshold")
+		return nil
+	}
+	probability := func(basis quantum.BasisAmplitude) float64 {
+		return real(basis.Amplitude * cmplx.Conj(basis.Amplitude))
+	}
+	// Most probable first; NonzeroAmplitudes is in index order, so the stable sort keeps ties by index
+	sort.SliceStable(listed, func(i, j int) bool { return probability(listed[i]) > probability(listed[j]) })
+
+	total := state.Norm()
+	for i, basis := range listed {
+		if i == shown {
+			fmt.Printf("  ... %d more state(s) omitted\n", len(listed)-shown)
+			break
+		}
+		fmt.Printf("  %s  p=%.*f\n", h.ket(basis.Basis, state.NumQubits()), h.precision, probability(basis)/total)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package commands
+
+import "testing"
+
+func TestHandleResourceEstimate(t *testing.T) {
+	runHandlerCases(t, "resource-estimate", 1, []handlerCase{
+		{nil, false},
+		{[]string{"all"}, true},
+	}, (*Handler).HandleResourceEstimate)
+}
+
+func TestHandleArgmax(t *testing.T) {
+	h := NewHandler(2)
+	if err := h.HandleGate([]string{"X", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	got := captureOutput(t, func() error { return h.HandleArgmax(nil) })
+	if want := "Most probable outcome: |10⟩ with p=1.000000 (uniquely maximal)\n"; got != want {
+		t.Errorf("argmax printed %q, want %q", got, want)
+	}
+	if err := h.HandleArgmax([]string{"1"}); err == nil {
+		t.Error("argmax accepted an argument")
+	}
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"qmachine/quantum"
+)
+
+// defaultBenchmarkQubits is the state size bench-gates uses when none is given
+const defaultBenchmarkQubits = 16
+
+// HandleBenchGates times gate application and measurement on a scratch state of the given size
+// (the machine state is not touched) and reports the allocations per operation
+func (h *Handler) HandleBenchGates(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: bench-gates [qubits]")
+	}
+	numQubits := defaultBenchmarkQubits
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid qubit count: %v", err)
+		}
+		numQubits = n
+	}
+	if numQubits < 2 || numQubits > quantum.MaxBenchmarkQubits {
+		return fmt.Errorf("benchmark qubit count must be between 2 and %d", quantum.MaxBenchmarkQubits)
+	}
+
+	fmt.Printf("Benchmarking on a %d-qubit state (%d amplitudes)...\n", numQubits, 1<<numQubits)
+	results, err := quantum.BenchmarkGates(numQubits)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-16s %10s %14s %12s %14s\n", "Operation", "Runs", "ns/op", "allocs/op", "bytes/op")
+	for _, r := range results {
+		fmt.Printf("%-16s %10d %14d %12d %14d\n", r.Name, r.Runs, r.NsPerOp, r.AllocsPerOp, r.BytesPerOp)
+	}
+	return nil
+}
+
+// HandleRandomizedBenchmark runs a randomized benchmarking sequence and reports the survival
+// probability: random Clifford layers followed by their inverse should return to |0...0⟩
+func (h *Handler) HandleRandomizedBenchmark(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("randomized-benchmark is exclusive to VM execution mode")
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: randomized-benchmark <length> <qubits>")
+	}
+	length, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid sequence length: %v", err)
+	}
+	qubits, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid qubit count: %v", err)
+	}
+	result, err := h.machine.RandomizedBenchmark(length, qubits)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Applied %d layers of random Clifford gates on %d qubit(s) (%d gates), then their inverse\n",
+		length, qubits, result.Gates)
+	fmt.Printf("Survival probability P(|%0*b⟩): %.*f\n", qubits, 0, h.precision, result.Survival)
+	verdict := "survived"
+	if result.Outcome != 0 {
+		verdict = "did not survive"
+	}
+	fmt.Printf("Measured |%0*b⟩: %s\n", qubits, result.Outcome, verdict)
+	return nil
+}
+
+// HandleNoise shows the per-gate noise model, or sets the strength of one channel
+func (h *Handler) HandleNoise(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("noise is exclusive to VM execution mode")
+	}
+	noise := h.machine.Noise()
+	if len(args) == 0 {
+		fmt.Printf("Noise after each gate: bit flip p=%g, amplitude damping gamma=%g\n",
+			noise.BitFlip, noise.AmplitudeDamping)
+		return nil
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: noise [bitflip|damping <p>]")
+	}
+	p, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid probability: %v", err)
+	}
+	switch args[0] {
+	case "bitflip":
+		noise.BitFlip = p
+	case "damping":
+		noise.AmplitudeDamping = p
+	default:
+		return fmt.Errorf("unknown noise channel %q (choose bitflip or damping)", args[0])
+	}
+	if err := h.machine.SetNoise(noise); err != nil {
+		return err
+	}
+	fmt.Printf("Noise after each gate: bit flip p=%g, amplitude damping gamma=%g\n",
+		noise.BitFlip, noise.AmplitudeDamping)
+	return nil
+}
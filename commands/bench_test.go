@@ -0,0 +1,50 @@
+package commands
+
+import "testing"
+
+func TestHandleRandomizedBenchmark(t *testing.T) {
+	runHandlerCases(t, "randomized-benchmark", 1, []handlerCase{
+		{[]string{"10", "2"}, false},
+		{[]string{"1", "1"}, false},
+		{nil, true},
+		{[]string{"10"}, true},
+		{[]string{"ten", "2"}, true},
+		{[]string{"10", "two"}, true},
+		{[]string{"0", "2"}, true},
+		{[]string{"10", "0"}, true},
+	}, (*Handler).HandleRandomizedBenchmark)
+}
+
+func TestHandleNoise(t *testing.T) {
+	runHandlerCases(t, "noise", 1, []handlerCase{
+		{nil, false},
+		{[]string{"bitflip", "0.1"}, false},
+		{[]string{"damping", "0"}, false},
+		{[]string{"damping", "1"}, false},
+		{[]string{"bitflip"}, true},
+		{[]string{"bitflip", "high"}, true},
+		{[]string{"bitflip", "1.5"}, true},
+		{[]string{"damping", "-0.1"}, true},
+		{[]string{"depolarizing", "0.1"}, true},
+		{[]string{"bitflip", "0.1", "0.2"}, true},
+	}, (*Handler).HandleNoise)
+
+	// Each channel is set on its own, keeping the other
+	h := NewHandler(1)
+	if err := h.HandleNoise([]string{"bitflip", "0.25"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleNoise([]string{"damping", "0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleNoise([]string{"damping", "2"}); err == nil {
+		t.Error("noise damping 2 succeeded")
+	}
+	if noise := h.machine.Noise(); noise.BitFlip != 0.25 || noise.AmplitudeDamping != 0.5 {
+		t.Errorf("noise model %+v, want bit flip 0.25 and damping 0.5", noise)
+	}
+	h.HandleMode()
+	if err := h.HandleNoise(nil); err == nil {
+		t.Error("noise in host mode succeeded")
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import "fmt"
+
+// HandleClassicalBits lists the classical bits that measurements have written ("measure q c0")
+func (h *Handler) HandleClassicalBits() {
+	bits := h.machine.ClassicalBits()
+	if len(bits) == 0 {
+		fmt.Println("No classical bits set (store a result with: measure <qubit> <cbit>)")
+		return
+	}
+	fmt.Println("Classical bits:")
+	for _, bit := range bits {
+		fmt.Printf("  %s = %d\n", bit.Name, bit.Value)
+	}
+}
+
+// splitCondition removes a trailing "if <cbit>" clause from a command's arguments and returns
+// the remaining arguments and the classical bit name ("" when there is no clause)
+func splitCondition(args []string) ([]string, string) {
+	if n := len(args); n >= 2 && args[n-2] == "if" {
+		return args[:n-2], args[n-1]
+	}
+	return args, ""
+}
+
+// skipConditionalGate reports whether a gate with an "if <cbit>" condition must be skipped
+// because the bit is 0, announcing the skip; there is nothing to skip without a condition
+func (h *Handler) skipConditionalGate(condition, gateType string, target int) (bool, error) {
+	if condition == "" {
+		return false, nil
+	}
+	met, err := h.classicalConditionMet(condition)
+	if err != nil || met {
+		return false, err
+	}
+	fmt.Printf("Skipped %s gate on qubit %d (%s = 0)\n", gateType, target, condition)
+	return true, nil
+}
+
+// classicalConditionMet reports whether the named classical bit is 1
+func (h *Handler) classicalConditionMet(name string) (bool, error) {
+	value, err := h.machine.ClassicalBitValue(name)
+	if err != nil {
+		return false, err
+	}
+	return value == 1, nil
+}
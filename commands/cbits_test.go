@@ -0,0 +1,61 @@
+package commands
+
+import "testing"
+
+func TestSplitCondition(t *testing.T) {
+	tests := []struct {
+		args          []string
+		wantArgs      int
+		wantCondition string
+	}{
+		{[]string{"X", "1"}, 2, ""},
+		{[]string{"X", "1", "if", "c0"}, 2, "c0"},
+		{[]string{"CNOT", "1", "0", "if", "flag"}, 3, "flag"},
+		{[]string{"if", "c0"}, 0, "c0"},
+		{[]string{"X", "if"}, 2, ""},
+	}
+	for _, tt := range tests {
+		args, condition := splitCondition(tt.args)
+		if len(args) != tt.wantArgs || condition != tt.wantCondition {
+			t.Errorf("splitCondition(%v) = %v, %q, want %d args and %q", tt.args, args, condition, tt.wantArgs,
+				tt.wantCondition)
+		}
+	}
+}
+
+func TestConditionalGate(t *testing.T) {
+	tests := []struct {
+		prepare bool    // X on qubit 0 before measuring it into c0
+		wantOne float64 // P(q1 = 1) after "gate X 1 if c0"
+	}{
+		{true, 1},
+		{false, 0},
+	}
+	for _, tt := range tests {
+		h := NewHandler(2)
+		if tt.prepare {
+			if err := h.HandleGate([]string{"X", "0"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := h.HandleMeasure([]string{"0", "c0"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := h.HandleGate([]string{"X", "1", "if", "c0"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := h.machine.GetState().MeasureProbability(1, 1); got != tt.wantOne {
+			t.Errorf("c0 prepared %v: P(q1 = 1) = %g, want %g", tt.prepare, got, tt.wantOne)
+		}
+	}
+
+	runHandlerCases(t, "gate", 2, []handlerCase{
+		{[]string{"X", "1", "if", "c9"}, true}, // Bit never measured
+		{[]string{"if", "c0"}, true},
+	}, (*Handler).HandleGate)
+	runHandlerCases(t, "measure", 2, []handlerCase{
+		{[]string{"0", "c0"}, false},
+		{[]string{"0", "9c"}, true},
+		{[]string{"0", "c0", "c1"}, true},
+	}, (*Handler).HandleMeasure)
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// applyRotationGate handles "gate RX|RY|RZ <target> <theta> [controls...] [if <cbit>]": args
+// holds the angle in radians followed by the control qubits
+func (h *Handler) applyRotationGate(gateType string, target int, args []string, condition string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gate %s <target> <theta> [controls...]", gateType)
+	}
+	theta, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid angle: %v", err)
+	}
+	controls, err := h.parseControlQubits(args[1:])
+	if err != nil {
+		return err
+	}
+	if skip, err := h.skipConditionalGate(condition, gateType, target); skip || err != nil {
+		return err
+	}
+
+	if err := h.machine.ApplyRotation(gateType, theta, target, controls); err != nil {
+		return err
+	}
+	fmt.Printf("Applied %s(%g) gate to qubit %d\n", gateType, theta, target)
+	return nil
+}
+
+// applySwapGate handles "gate SWAP <q1> <q2> [if <cbit>]"; args holds the second qubit
+func (h *Handler) applySwapGate(first int, args []string, condition string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: gate SWAP <q1> <q2>")
+	}
+	second, err := h.parseQubitIndex(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid qubit index: %v", err)
+	}
+	if skip, err := h.skipConditionalGate(condition, "SWAP", first); skip || err != nil {
+		return err
+	}
+
+	if err := h.machine.ApplySWAP(first, second); err != nil {
+		return err
+	}
+	fmt.Printf("Swapped qubits %d and %d\n", first, second)
+	return nil
+}
+
+// HandleBell prepares the Bell state |Φ+⟩ on two qubits (H on q1, then CNOT from q1 to q2)
+func (h *Handler) HandleBell(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("bell is exclusive to VM execution mode")
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: bell <q1> <q2>")
+	}
+
+	qubits, err := parseQubitList(args)
+	if err != nil {
+		return err
+	}
+
+	if err := h.machine.PrepareBell(qubits[0], qubits[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Prepared Bell state |Φ+⟩ on qubits %d and %d\n", qubits[0], qubits[1])
+	return nil
+}
+
+// HandleGHZ prepares the GHZ state (|0...0⟩+|1...1⟩)/√2 on the listed qubits
+func (h *Handler) HandleGHZ(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("ghz is exclusive to VM execution mode")
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("usage: ghz <q1> <q2> ... <qn>")
+	}
+
+	qubits, err := parseQubitList(args)
+	if err != nil {
+		return err
+	}
+
+	if err := h.machine.PrepareGHZ(qubits...); err != nil {
+		return err
+	}
+	fmt.Printf("Prepared GHZ state on qubits %v\n", qubits)
+	return nil
+}
+
+// HandleQFT applies the quantum Fourier transform, or with inverse its inverse, to the listed
+// qubits, the first being the least significant
+func (h *Handler) HandleQFT(args []string, inverse bool) error {
+	name := "qft"
+	if inverse {
+		name = "iqft"
+	}
+	if h.useHost {
+		return fmt.Errorf("%s is exclusive to VM execution mode", name)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s <q0> <q1> ... <qn>", name)
+	}
+	qubits, err := parseQubitList(args)
+	if err != nil {
+		return err
+	}
+	if err := h.machine.ApplyQFT(qubits, inverse); err != nil {
+		return err
+	}
+	if inverse {
+		fmt.Printf("Applied the inverse QFT to qubits %v\n", qubits)
+	} else {
+		fmt.Printf("Applied the QFT to qubits %v\n", qubits)
+	}
+	return nil
+}
+
+// HandleZZInteraction applies e^{-iγ Z_a Z_b} to a qubit pair, the QAOA cost-layer rotation
+func (h *Handler) HandleZZInteraction(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("zz-interaction is exclusive to VM execution mode")
+	}
+	if len(args) != 3 {
+		return fmt.Errorf("usage: zz-interaction <qubitA> <qubitB> <gamma>")
+	}
+
+	qubits, err := parseQubitList(args[:2])
+	if err != nil {
+		return err
+	}
+	gamma, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return fmt.Errorf("invalid angle: %v", err)
+	}
+
+	if err := h.machine.ZZInteraction(qubits[0], qubits[1], gamma); err != nil {
+		return err
+	}
+	fmt.Printf("Applied ZZ interaction (gamma=%g) to qubits %d and %d\n", gamma, qubits[0], qubits[1])
+	return nil
+}
+
+// HandleMixer applies the QAOA mixing layer RX(2β) to every qubit
+func (h *Handler) HandleMixer(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("mixer is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: mixer <beta>")
+	}
+
+	beta, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid angle: %v", err)
+	}
+
+	if err := h.machine.ApplyMixer(beta); err != nil {
+		return err
+	}
+	fmt.Printf("Applied mixer RX(%g) to all %d qubits\n", 2*beta, h.machine.GetState().NumQubits())
+	return nil
+}
+
+// parseQubitList parses qubit indices given as separate arguments
+func parseQubitList(args []string) ([]int, error) {
+	qubits := make([]int, len(args))
+	for i, arg := range args {
+		q, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid qubit index: %v", err)
+		}
+		qubits[i] = q
+	}
+	return qubits, nil
+}
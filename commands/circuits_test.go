@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestHandleBell(t *testing.T) {
+	runHandlerCases(t, "bell", 3, []handlerCase{
+		{[]string{"0", "1"}, false},
+		{[]string{"2", "0"}, false},
+		{[]string{"0"}, true},
+		{[]string{"0", "0"}, true},
+		{[]string{"0", "3"}, true},
+		{[]string{"a", "1"}, true},
+	}, (*Handler).HandleBell)
+
+	h := NewHandler(2)
+	if err := h.HandleBell([]string{"0", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	state := h.machine.GetState()
+	if p := state.MeasureProbability(0, 1); p < 0.5-1e-12 || p > 0.5+1e-12 {
+		t.Errorf("after bell 0 1, P(q0=1) = %g, want 0.5", p)
+	}
+	if state.GetAmplitude(1) != 0 || state.GetAmplitude(2) != 0 {
+		t.Error("after bell 0 1 the qubits disagree with nonzero probability")
+	}
+}
+
+func TestHandleGHZ(t *testing.T) {
+	runHandlerCases(t, "ghz", 4, []handlerCase{
+		{[]string{"0", "1"}, false},
+		{[]string{"0", "1", "2", "3"}, false},
+		{[]string{"3", "1", "0"}, false},
+		{[]string{"0"}, true},
+		{[]string{"0", "1", "1"}, true},
+		{[]string{"0", "4"}, true},
+	}, (*Handler).HandleGHZ)
+}
+
+func TestHandleQFT(t *testing.T) {
+	for _, inverse := range []bool{false, true} {
+		handle := func(h *Handler, args []string) error { return h.HandleQFT(args, inverse) }
+		runHandlerCases(t, "qft", 3, []handlerCase{
+			{[]string{"0"}, false},
+			{[]string{"0", "1", "2"}, false},
+			{[]string{"2", "0"}, false},
+			{nil, true},
+			{[]string{"0", "0"}, true},
+			{[]string{"0", "3"}, true},
+			{[]string{"q0"}, true},
+		}, handle)
+	}
+
+	h := NewHandler(3)
+	if err := h.HandleQFT([]string{"0", "1", "2"}, false); err != nil {
+		t.Fatal(err)
+	}
+	// The QFT of |000⟩ is the uniform superposition
+	for i, amp := range h.machine.GetState().GetAmplitudes() {
+		if cmplx.Abs(amp-complex(1/math.Sqrt(8), 0)) > 1e-9 {
+			t.Errorf("after qft 0 1 2, amplitude %d = %v, want 1/sqrt(8)", i, amp)
+		}
+	}
+	if err := h.HandleQFT([]string{"0", "1", "2"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if amp := h.machine.GetState().GetAmplitude(0); cmplx.Abs(amp-1) > 1e-9 {
+		t.Errorf("after qft and iqft, amplitude of |000⟩ = %v, want 1", amp)
+	}
+}
+
+func TestHandleZZInteraction(t *testing.T) {
+	runHandlerCases(t, "zz-interaction", 3, []handlerCase{
+		{[]string{"0", "1", "0.5"}, false},
+		{[]string{"2", "0", "-1"}, false},
+		{[]string{"0", "1"}, true},
+		{[]string{"0", "0", "0.5"}, true},
+		{[]string{"0", "1", "gamma"}, true},
+		{[]string{"0", "5", "0.5"}, true},
+	}, (*Handler).HandleZZInteraction)
+}
+
+func TestHandleMixer(t *testing.T) {
+	runHandlerCases(t, "mixer", 2, []handlerCase{
+		{[]string{"0.4"}, false},
+		{[]string{"-1"}, false},
+		{nil, true},
+		{[]string{"beta"}, true},
+		{[]string{"1", "2"}, true},
+	}, (*Handler).HandleMixer)
+	runHandlerCases(t, "mixer", 30, []handlerCase{{[]string{"0.4"}, true}}, (*Handler).HandleMixer)
+
+	h := NewHandler(2)
+	if err := h.HandleMixer([]string{"0.4"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(h.machine.GetGateHistory()); got != 2 {
+		t.Errorf("mixer on 2 qubits recorded %d gates, want 2", got)
+	}
+}
@@ -2,6 +2,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -10,11 +11,24 @@ import (
 	"qmachine/quantum"
 )
 
+// defaultPrecision is the number of decimal places used to display amplitudes and phases
+const defaultPrecision = 6
+
+// defaultThreshold is the amplitude magnitude below which Dirac displays omit a term
+const defaultThreshold = 1e-6
+
+// maxDisplayQubits bounds the state size that per-amplitude displays will list
+const maxDisplayQubits = 16
+
 // Handler handles REPL command execution
 type Handler struct {
 	machine     *quantum.QuantumRISCVMachine
 	hostMachine *quantum.HostQuantumMachine
 	useHost     bool
+	precision   int     // Decimal places for amplitude and phase displays
+	threshold   float64 // Amplitude magnitude below which Dirac displays omit a term
+	freshRun    bool    // run resets the quantum state first (see HandleRunState)
+	regDiff     string  // Register diff format after riscv and run ("" when off, see HandleDiffRegisters)
 }
 
 // NewHandler creates a new command handler
@@ -23,6 +37,8 @@ func NewHandler(numQubits int) *Handler {
 		machine:     quantum.NewQuantumRISCVMachine(numQubits),
 		hostMachine: quantum.NewHostQuantumMachine(numQubits),
 		useHost:     false,
+		precision:   defaultPrecision,
+		threshold:   defaultThreshold,
 	}
 }
 
@@ -40,8 +56,9 @@ func (h *Handler) HandleGate(args []string) error {
 	if h.useHost {
 		return fmt.Errorf("gate commands are exclusive to VM execution mode")
 	}
+	args, condition := splitCondition(args)
 	if len(args) < 2 {
-		return fmt.Errorf("usage: gate <type> <target> [controls...]")
+		return fmt.Errorf("usage: gate <type> <target> [controls...] [if <cbit>]")
 	}
 
 	target, err := h.parseQubitIndex(args[1])
@@ -49,23 +66,137 @@ func (h *Handler) HandleGate(args []string) error {
 		return fmt.Errorf("invalid target qubit: %v", err)
 	}
 
+	gateType := strings.ToUpper(args[0])
+	if quantum.IsRotationGate(gateType) {
+		return h.applyRotationGate(gateType, target, args[2:], condition)
+	}
+	if gateType == "SWAP" {
+		return h.applySwapGate(target, args[2:], condition)
+	}
 	controls, err := h.parseControlQubits(args[2:])
 	if err != nil {
 		return err
 	}
 
-	instruction, err := h.createGateInstruction(strings.ToUpper(args[0]), target, controls)
+	instruction, err := h.createGateInstruction(gateType, target, controls)
+	if err != nil {
+		return err
+	}
+	if gateType == "CNOT" {
+		if err := quantum.RequireQubits(gateType, 2, h.machine.GetState().NumQubits()); err != nil {
+			return err
+		}
+	}
+	if skip, err := h.skipConditionalGate(condition, gateType, target); skip || err != nil {
+		return err
+	}
+
+	if err := h.machine.ExecuteInstruction(instruction); err != nil {
+		return err
+	}
+	fmt.Printf("Applied %s gate to qubit %d\n", gateType, target)
+	return nil
+}
+
+// HandleGlobalPhase multiplies the whole quantum state by e^{iθ}
+func (h *Handler) HandleGlobalPhase(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("global-phase is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: global-phase <theta>")
+	}
+
+	theta, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid angle: %v", err)
+	}
+
+	h.machine.ApplyGlobalPhase(theta)
+	fmt.Printf("Applied global phase e^(i*%g)\n", theta)
+	return nil
+}
+
+// HandleProbPattern prints the probability that the listed qubits take the given values,
+// e.g. "prob-pattern q0=1,q3=0", without collapsing the state
+func (h *Handler) HandleProbPattern(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("prob-pattern is exclusive to VM execution mode")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: prob-pattern <q0=1,q3=0,...>")
+	}
+
+	spec := strings.Join(args, ",")
+	pattern, err := h.parseBitPattern(spec)
 	if err != nil {
 		return err
 	}
 
-	return h.machine.ExecuteRISCInstruction(fmt.Sprintf("qapply x%d, x%d, %d", instruction.Target, instruction.Controls[0], instruction.Opcode))
+	p := h.machine.GetState().PatternProbability(pattern)
+	fmt.Printf("P(%s) = %.6f\n", spec, p)
+	return nil
+}
+
+// HandleNorm prints the state's total probability at full precision so small drifts from 1 are visible
+func (h *Handler) HandleNorm() error {
+	if h.useHost {
+		return fmt.Errorf("norm is exclusive to VM execution mode")
+	}
+	norm := h.machine.GetState().Norm()
+	fmt.Printf("Norm: %.17g (deviation from 1: %.3g)\n", norm, norm-1)
+	return h.HandleSupportSize()
+}
+
+// SetMachines replaces the machines the handler drives, e.g. with ones configured from
+// command-line flags. The handler's run mode (see HandleRunState) carries over.
+func (h *Handler) SetMachines(machine *quantum.QuantumRISCVMachine, hostMachine *quantum.HostQuantumMachine) {
+	h.machine, h.hostMachine = machine, hostMachine
+	h.machine.SetFreshRun(h.freshRun)
+	h.hostMachine.SetFreshRun(h.freshRun)
+}
+
+// HandleSupportSize prints how many basis states have a nonzero amplitude
+func (h *Handler) HandleSupportSize() error {
+	if h.useHost {
+		return fmt.Errorf("support-size is exclusive to VM execution mode")
+	}
+	state := h.machine.GetState()
+	fmt.Printf("Support size: %d of 2^%d basis states\n", state.SupportSize(), state.NumQubits())
+	return nil
+}
+
+// HandleSeed re-initializes the measurement RNG of both execution modes from a known seed
+func (h *Handler) HandleSeed(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: seed <value>")
+	}
+
+	seed, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid seed: %v", err)
+	}
+
+	h.SetSeed(seed)
+	fmt.Printf("Measurement RNG re-seeded with %d\n", seed)
+	return nil
+}
+
+// SetSeed re-initializes the measurement RNG of both execution modes without printing anything
+func (h *Handler) SetSeed(seed int64) {
+	h.machine.SetSeed(seed)
+	h.hostMachine.SetSeed(seed)
+}
+
+// Seed returns the seed the VM measurement RNG was last initialized from
+func (h *Handler) Seed() int64 {
+	return h.machine.Seed()
 }
 
 // HandleMeasure processes qubit measurement commands
 func (h *Handler) HandleMeasure(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("usage: measure <qubit>")
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("usage: measure <qubit> [cbit]")
 	}
 
 	qubit, err := h.parseQubitIndex(args[0])
@@ -73,23 +204,94 @@ func (h *Handler) HandleMeasure(args []string) error {
 		return fmt.Errorf("invalid qubit index: %v", err)
 	}
 
-	// Convert uint8 to int for MeasureQubit
-	result := h.machine.MeasureQubit(int(qubit))
-	fmt.Printf("Measurement result: %d\n", result)
+	if len(args) == 2 {
+		result, err := h.machine.MeasureToBit(qubit, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Measurement result: %d (p=%.6f), stored in %s\n", result.Outcome, result.Probability, args[1])
+		return nil
+	}
+	result, err := h.machine.MeasureQubit(qubit)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Measurement result: %d (p=%.6f)\n", result.Outcome, result.Probability)
 	return nil
 }
 
-// HandleState displays the current quantum state
-func (h *Handler) HandleState() error {
-	// Since GetQuantumState is not available, we'll show register state instead
+// HandleForceCollapse projects the state onto the basis state given as a bitstring, read like a
+// ket: the rightmost character is qubit 0 and omitted leading qubits are 0
+func (h *Handler) HandleForceCollapse(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("force-collapse is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: force-collapse <bitstring>")
+	}
+
+	bitstring := args[0]
+	if len(bitstring) > h.machine.GetState().NumQubits() {
+		return fmt.Errorf("bitstring %s has more bits than the machine's %d qubits",
+			bitstring, h.machine.GetState().NumQubits())
+	}
+	index, err := strconv.ParseUint(bitstring, 2, 62)
+	if err != nil {
+		return fmt.Errorf("invalid bitstring %s: expected only 0s and 1s", bitstring)
+	}
+
+	if err := h.machine.ForceCollapse(int(index)); err != nil {
+		return err
+	}
+	fmt.Printf("State collapsed to |%s>\n", bitstring)
+	return nil
+}
+
+// HandleState displays the current quantum state; "state dirac" prints it as a ket sum
+func (h *Handler) HandleState(args []string) error {
+	if len(args) == 1 && args[0] == "dirac" {
+		return h.printDirac()
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: state [dirac]")
+	}
+	if !h.useHost && h.machine.GetState().NumQubits() <= maxStateVectorQubits {
+		return h.printStateVector()
+	}
+	// Larger states are summarized by the register state instead
 	h.HandleRegisters()
 	return nil
 }
 
 // HandleReset resets the quantum state
 func (h *Handler) HandleReset() error {
-	// Since Reset is not available, we'll recreate the machine
-	h.machine = quantum.NewQuantumRISCVMachine(2000) // Using default size
+	h.machine.Reset() // In place, so the seed, XLEN, noise and state settings survive
+	return nil
+}
+
+// HandleCircuit lists the gates applied so far, in order
+func (h *Handler) HandleCircuit() {
+	history := h.machine.GetGateHistory()
+	if len(history) == 0 {
+		fmt.Println("No gates applied")
+		return
+	}
+	fmt.Println("Circuit:")
+	for i, record := range history {
+		fmt.Printf("  %3d: %s\n", i, record)
+	}
+}
+
+// HandleInvert uncomputes the recorded circuit by applying every gate's inverse in reverse order
+func (h *Handler) HandleInvert() error {
+	if h.useHost {
+		return fmt.Errorf("invert is exclusive to VM execution mode")
+	}
+	count, err := h.machine.InvertCircuit()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Applied the inverse of %d recorded gate(s)\n", count)
 	return nil
 }
 
@@ -100,7 +302,26 @@ func (h *Handler) HandleRISC(args []string) error {
 	}
 
 	instruction := strings.Join(args, " ")
-	return h.machine.ExecuteRISCInstruction(instruction)
+	return h.withRegisterDiff(h.machine.GetRegisters, func() error {
+		return h.machine.ExecuteRISCInstruction(instruction)
+	})
+}
+
+// HandleCalc evaluates "calc xD = <expression>" over registers and integers (+, -, *, parentheses)
+// and stores the result in xD
+func (h *Handler) HandleCalc(args []string) error {
+	dest, expr, ok := strings.Cut(strings.Join(args, " "), "=")
+	if !ok || strings.TrimSpace(dest) == "" {
+		return fmt.Errorf("usage: calc <rd> = <expression>")
+	}
+	dest = strings.TrimSpace(dest)
+
+	value, sequence, err := h.machine.Calc(dest, expr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s = %d (via: %s)\n", dest, value, strings.Join(sequence, "; "))
+	return nil
 }
 
 // HandleLoad loads a RISC-V program from a file
@@ -109,12 +330,150 @@ func (h *Handler) HandleLoad(args []string) error {
 		return fmt.Errorf("usage: load <file>")
 	}
 
-	return h.machine.LoadRISCProgram(args[0])
+	if err := h.machine.LoadRISCProgram(args[0]); err != nil {
+		return err
+	}
+	return h.shareProgram()
+}
+
+// shareProgram shares the program assembled by the VM machine with the host machine so both
+// modes can run and step it
+func (h *Handler) shareProgram() error {
+	h.hostMachine.LoadProgram(h.machine.GetRISCProgram(), h.machine.EntryPC())
+	return h.hostMachine.LoadData(h.machine.DataSegment())
 }
 
-// HandleRun executes the loaded RISC-V program
+// HandleRun executes the loaded RISC-V program in the current execution mode
 func (h *Handler) HandleRun() error {
-	return h.machine.ExecuteRISCProgram()
+	return h.withRegisterDiff(h.registers, func() error {
+		if h.useHost {
+			return h.hostMachine.ExecuteProgram()
+		}
+		return h.machine.ExecuteRISCProgram()
+	})
+}
+
+// HandleRunState shows or sets whether run starts from a fresh |0...0⟩ quantum state ("fresh")
+// or continues evolving the current one ("current", the interactive default)
+func (h *Handler) HandleRunState(args []string) error {
+	if len(args) > 1 || (len(args) == 1 && args[0] != "fresh" && args[0] != "current") {
+		return fmt.Errorf("usage: run-state [fresh|current]")
+	}
+	if len(args) == 1 {
+		h.freshRun = args[0] == "fresh"
+		h.machine.SetFreshRun(h.freshRun)
+		h.hostMachine.SetFreshRun(h.freshRun)
+	}
+	if h.freshRun {
+		fmt.Println("run starts from a fresh |0...0⟩ quantum state")
+	} else {
+		fmt.Println("run continues from the current quantum state")
+	}
+	return nil
+}
+
+// HandleRunHost switches to host-native mode (if needed) and runs the loaded program there
+func (h *Handler) HandleRunHost() error {
+	if !h.useHost {
+		h.HandleMode()
+	}
+	return h.HandleRun()
+}
+
+// HandleStep executes the next n (default 1) instructions of the loaded program in the current
+// execution mode, printing each one and the registers it changed
+func (h *Handler) HandleStep(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: step [count]")
+	}
+	count := 1
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("step count must be a positive integer")
+		}
+		count = n
+	}
+
+	stepper := h.stepper()
+	program := stepper.GetRISCProgram()
+	for i := 0; i < count && !stepper.Finished(); i++ {
+		pc, before := stepper.PC(), h.registers()
+		fmt.Printf("  %s\n", program[pc].Position(pc))
+		if err := stepper.StepOnce(); err != nil {
+			return err
+		}
+		h.printRegisterChanges(before, h.registers(), "    ")
+	}
+	if stepper.Finished() {
+		fmt.Println("Program finished")
+	} else {
+		fmt.Printf("Next: %s\n", program[stepper.PC()].Position(stepper.PC()))
+	}
+	return nil
+}
+
+// HandleRunValue runs the loaded program and prints one register as the program's result
+func (h *Handler) HandleRunValue(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("run-value is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: run-value <register>")
+	}
+
+	value, signed, err := h.machine.RunValue(args[0])
+	if err != nil {
+		return err
+	}
+	if signed < 0 {
+		fmt.Printf("Result (%s): %d (signed: %d)\n", args[0], value, signed)
+	} else {
+		fmt.Printf("Result (%s): %d\n", args[0], value)
+	}
+	return nil
+}
+
+// HandleListOps lists every supported instruction and gate, as a table or as JSON ("list-ops json")
+func (h *Handler) HandleListOps(args []string) error {
+	ops := quantum.SupportedOps()
+	if len(args) == 1 && args[0] == "json" {
+		data, err := json.MarshalIndent(ops, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: list-ops [json]")
+	}
+
+	for _, op := range ops {
+		fmt.Printf("%-8s %-10s %-20s %s\n", op.Kind, op.Name, op.Operands, op.Description)
+	}
+	return nil
+}
+
+// HandleDescribe prints the syntax, meaning and an example of one instruction or gate
+func (h *Handler) HandleDescribe(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: describe <instruction|gate>")
+	}
+	op, ok := quantum.DescribeOp(args[0])
+	if !ok {
+		return fmt.Errorf("unknown instruction or gate: %s (see list-ops)", args[0])
+	}
+
+	syntax := op.Name + " " + op.Operands
+	if op.Kind == "gate" {
+		syntax = "gate " + syntax
+	}
+	fmt.Printf("%s (%s)\n", op.Name, op.Kind)
+	fmt.Printf("  Syntax:  %s\n", syntax)
+	fmt.Printf("  Meaning: %s\n", op.Description)
+	fmt.Printf("  Example: %s\n", op.Example)
+	return nil
 }
 
 // HandleMode toggles between VM and host-native execution
@@ -129,31 +488,48 @@ func (h *Handler) HandleMode() {
 
 // HandleRegisters displays the current register state
 func (h *Handler) HandleRegisters() {
-	var registers [128]uint64
-	if h.useHost {
-		registers = h.hostMachine.GetRegisters()
-	} else {
-		registers = h.machine.GetRegisters()
-	}
-
 	fmt.Println("Register state:")
-	for i, reg := range registers {
+	for i, reg := range h.registers() {
 		fmt.Printf("  x%d: %d\n", i, reg)
 	}
 }
 
 // Helper functions
 
-func (h *Handler) parseQubitIndex(s string) (uint8, error) {
-	index, err := strconv.Atoi(s)
-	if err != nil {
-		return 0, err
+// parseQubitIndex parses a qubit index; the machine checks it against its qubit count
+func (h *Handler) parseQubitIndex(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+// parseBitPattern parses "q0=1,q3=0" (the q prefix is optional) into qubit -> value requirements
+func (h *Handler) parseBitPattern(spec string) (map[int]int, error) {
+	numQubits := h.machine.GetState().NumQubits()
+	pattern := make(map[int]int)
+	for _, term := range strings.Split(spec, ",") {
+		if term == "" {
+			continue
+		}
+		parts := strings.SplitN(term, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid pattern term '%s', expected qN=0 or qN=1", term)
+		}
+		qubit, err := strconv.Atoi(strings.TrimPrefix(parts[0], "q"))
+		if err != nil || qubit < 0 || qubit >= numQubits {
+			return nil, fmt.Errorf("invalid qubit in pattern term '%s'", term)
+		}
+		if parts[1] != "0" && parts[1] != "1" {
+			return nil, fmt.Errorf("invalid value in pattern term '%s', expected 0 or 1", term)
+		}
+		pattern[qubit] = int(parts[1][0] - '0')
+	}
+	if len(pattern) == 0 {
+		return nil, fmt.Errorf("empty pattern")
 	}
-	return uint8(index), nil
+	return pattern, nil
 }
 
-func (h *Handler) parseControlQubits(args []string) ([]uint8, error) {
-	var controls []uint8
+func (h *Handler) parseControlQubits(args []string) ([]int, error) {
+	var controls []int
 	for _, arg := range args {
 		control, err := h.parseQubitIndex(arg)
 		if err != nil {
@@ -164,7 +540,7 @@ func (h *Handler) parseControlQubits(args []string) ([]uint8, error) {
 	return controls, nil
 }
 
-func (h *Handler) createGateInstruction(gateType string, target uint8, controls []uint8) (quantum.Instruction, error) {
+func (h *Handler) createGateInstruction(gateType string, target int, controls []int) (quantum.Instruction, error) {
 	var opcode uint8
 	switch gateType {
 	case "X":
@@ -179,6 +555,17 @@ func (h *Handler) createGateInstruction(gateType string, target uint8, controls
 		opcode = 0x04
 	case "T":
 		opcode = 0x05
+	case "I":
+		opcode = 0x08
+	case "SDG":
+		opcode = 0x09
+	case "TDG":
+		opcode = 0x0A
+	case "CCNOT": // Toffoli: an X with two controls
+		if len(controls) != 2 {
+			return quantum.Instruction{}, fmt.Errorf("CCNOT gate requires exactly two control qubits")
+		}
+		opcode = 0x00
 	case "CNOT":
 		if len(controls) != 1 {
 			return quantum.Instruction{}, fmt.Errorf("CNOT gate requires exactly one control qubit")
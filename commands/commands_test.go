@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// handlerCase is one REPL handler invocation and whether it should be rejected
+type handlerCase struct {
+	args    []string
+	wantErr bool
+}
+
+// runHandlerCases calls handle on a fresh numQubits handler for each case and checks the error
+func runHandlerCases(t *testing.T, name string, numQubits int, cases []handlerCase,
+	handle func(*Handler, []string) error) {
+	t.Helper()
+	for _, c := range cases {
+		h := NewHandler(numQubits)
+		err := handle(h, c.args)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s %v: error %v, want error %v", name, c.args, err, c.wantErr)
+		}
+	}
+}
+
+func TestHandleGlobalPhase(t *testing.T) {
+	runHandlerCases(t, "global-phase", 2, []handlerCase{
+		{[]string{"1.5"}, false},
+		{[]string{"-3"}, false},
+		{nil, true},
+		{[]string{"pi"}, true},
+		{[]string{"1", "2"}, true},
+	}, (*Handler).HandleGlobalPhase)
+
+	h := NewHandler(1)
+	if err := h.HandleGlobalPhase([]string{"1.5"}); err != nil {
+		t.Fatal(err)
+	}
+	want := cmplx.Exp(1.5i)
+	if got := h.machine.GetState().GetAmplitude(0); cmplx.Abs(got-want) > 1e-12 {
+		t.Errorf("after global-phase 1.5, amplitude of |0⟩ = %v, want %v", got, want)
+	}
+	if p := h.machine.GetState().MeasureProbability(0, 0); math.Abs(p-1) > 1e-12 {
+		t.Errorf("global phase changed P(q0=0) to %g", p)
+	}
+}
+
+func TestHandleGateRequiresQubits(t *testing.T) {
+	tests := []struct {
+		numQubits int
+		args      []string
+		wantErr   bool
+	}{
+		{1, []string{"CNOT", "0", "1"}, true},
+		{1, []string{"X", "0"}, false},
+		{2, []string{"CNOT", "1", "0"}, false},
+		{2, []string{"CNOT", "1", "1"}, true},
+	}
+	for _, tt := range tests {
+		runHandlerCases(t, "gate", tt.numQubits, []handlerCase{{tt.args, tt.wantErr}}, (*Handler).HandleGate)
+	}
+}
+
+func TestHandleIdentityGate(t *testing.T) {
+	runHandlerCases(t, "gate", 2, []handlerCase{
+		{[]string{"I", "0"}, false},
+		{[]string{"i", "1"}, false},
+		{[]string{"I", "2"}, true},
+	}, (*Handler).HandleGate)
+
+	h := NewHandler(2)
+	for _, args := range [][]string{{"H", "0"}, {"I", "1"}} {
+		if err := h.HandleGate(args); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if history := h.machine.GetGateHistory(); len(history) != 2 || history[1].Name != "I" {
+		t.Errorf("circuit after H and I is %v", history)
+	}
+}
+
+func TestHandleGateWideMachine(t *testing.T) {
+	runHandlerCases(t, "gate", 2000, []handlerCase{
+		{[]string{"X", "300"}, false},
+		{[]string{"X", "1000"}, false},
+		{[]string{"CNOT", "1999", "1000"}, false},
+		{[]string{"X", "2000"}, true},
+	}, (*Handler).HandleGate)
+
+	h := NewHandler(2000)
+	for _, args := range [][]string{{"X", "1000"}, {"CNOT", "1999", "1000"}} {
+		if err := h.HandleGate(args); err != nil {
+			t.Fatalf("gate %v: %v", args, err)
+		}
+	}
+	state := h.machine.GetState()
+	for _, q := range []int{1000, 1999} {
+		if p := state.MeasureProbability(q, 1); p != 1 {
+			t.Errorf("P(q%d = 1) = %g after X 1000 and CNOT 1999 1000, want 1", q, p)
+		}
+	}
+}
+
+func TestHandleInvert(t *testing.T) {
+	h := NewHandler(2)
+	for _, args := range [][]string{{"H", "0"}, {"CNOT", "1", "0"}, {"T", "1"}} {
+		if err := h.HandleGate(args); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.HandleInvert(); err != nil {
+		t.Fatal(err)
+	}
+	state := h.machine.GetState()
+	if p := state.MeasureProbability(0, 0) * state.MeasureProbability(1, 0); math.Abs(p-1) > 1e-9 {
+		t.Errorf("invert did not return to |00⟩: P = %g", p)
+	}
+	if _, err := h.machine.MeasureQubit(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleInvert(); err == nil {
+		t.Error("invert succeeded after a measurement")
+	}
+
+	// A forced collapse is recorded, so invert cannot pretend the H alone made the state
+	h = NewHandler(2)
+	if err := h.HandleGate([]string{"H", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleForceCollapse([]string{"01"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleInvert(); err == nil {
+		t.Error("invert succeeded after force-collapse")
+	}
+	if amp := h.machine.GetState().GetAmplitude(1); math.Abs(real(amp)-1) > 1e-12 {
+		t.Errorf("a refused invert changed the collapsed state: amplitude of |01⟩ = %v", amp)
+	}
+}
+
+func TestHandleStep(t *testing.T) {
+	runHandlerCases(t, "step", 1, []handlerCase{
+		{nil, false}, // Nothing loaded: reports the program finished
+		{[]string{"3"}, false},
+		{[]string{"0"}, true},
+		{[]string{"-1"}, true},
+		{[]string{"two"}, true},
+		{[]string{"1", "2"}, true},
+	}, (*Handler).HandleStep)
+
+	h := NewHandler(1)
+	if err := h.machine.LoadRISCSource("addi x5, x0, 1\naddi x6, x0, 2\naddi x7, x0, 3\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.shareProgram(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleStep([]string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+	if pc := h.machine.PC(); pc != 2 {
+		t.Errorf("VM PC after step 2 = %d, want 2", pc)
+	}
+	h.HandleMode()
+	if err := h.HandleStep(nil); err != nil {
+		t.Fatal(err)
+	}
+	if pc := h.hostMachine.PC(); pc != 1 {
+		t.Errorf("host PC after step = %d, want 1", pc)
+	}
+	if regs := h.registers(); regs[5] != 1 || regs[6] != 0 {
+		t.Errorf("host registers after one step: x5 = %d, x6 = %d, want 1 and 0", regs[5], regs[6])
+	}
+	if err := h.HandleStep([]string{"10"}); err != nil {
+		t.Errorf("stepping past the end: %v", err)
+	}
+}
+
+func TestHandleRunState(t *testing.T) {
+	runHandlerCases(t, "run-state", 1, []handlerCase{
+		{nil, false},
+		{[]string{"fresh"}, false},
+		{[]string{"current"}, false},
+		{[]string{"new"}, true},
+		{[]string{"fresh", "current"}, true},
+	}, (*Handler).HandleRunState)
+
+	h := NewHandler(1)
+	if err := h.HandleRunState([]string{"fresh"}); err != nil {
+		t.Fatal(err)
+	}
+	if !h.machine.FreshRun() || !h.hostMachine.FreshRun() {
+		t.Error("run-state fresh did not reach both machines")
+	}
+	if err := h.HandleRunState([]string{"current"}); err != nil {
+		t.Fatal(err)
+	}
+	if h.machine.FreshRun() || h.hostMachine.FreshRun() {
+		t.Error("run-state current did not reach both machines")
+	}
+}
+
+func TestHandleSupportSize(t *testing.T) {
+	h := NewHandler(3)
+	if err := h.HandleBell([]string{"0", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := captureOutput(t, h.HandleSupportSize), "Support size: 2 of 2^3 basis states\n"; got != want {
+		t.Errorf("support-size printed %q, want %q", got, want)
+	}
+	h.HandleMode()
+	if err := h.HandleSupportSize(); err == nil {
+		t.Error("support-size ran in host-native mode")
+	}
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"qmachine/quantum"
+)
+
+// stepper is the debugging API of a machine: both execution modes step through the shared
+// program and stop at their own breakpoints
+type stepper interface {
+	PC() uint32
+	Finished() bool
+	StepOnce() error
+	GetRISCProgram() []quantum.RISCInstruction
+	SetBreakpoint(pc uint32) error
+	ClearBreakpoint(pc uint32) bool
+	Breakpoints() []uint32
+	Continue() (bool, error)
+}
+
+// stepper returns the machine of the current execution mode
+func (h *Handler) stepper() stepper {
+	if h.useHost {
+		return h.hostMachine
+	}
+	return h.machine
+}
+
+// HandleBreak sets a breakpoint at an instruction index ("break 4"), removes one ("break clear
+// 4"), or lists them ("break") on the machine of the current execution mode
+func (h *Handler) HandleBreak(args []string) error {
+	machine := h.stepper()
+	if len(args) == 0 {
+		breakpoints := machine.Breakpoints()
+		if len(breakpoints) == 0 {
+			fmt.Println("No breakpoints set")
+			return nil
+		}
+		program := machine.GetRISCProgram()
+		for _, pc := range breakpoints {
+			fmt.Printf("  %s\n", program[pc].Position(pc))
+		}
+		return nil
+	}
+	remove := args[0] == "clear"
+	if remove {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: break [[clear] <index>]")
+	}
+	index, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid instruction index: %s", args[0])
+	}
+	pc := uint32(index)
+
+	if remove {
+		if !machine.ClearBreakpoint(pc) {
+			return fmt.Errorf("no breakpoint at index %d", pc)
+		}
+		fmt.Printf("Cleared the breakpoint at index %d\n", pc)
+		return nil
+	}
+	if err := machine.SetBreakpoint(pc); err != nil {
+		return err
+	}
+	fmt.Printf("Breakpoint set at %s\n", machine.GetRISCProgram()[pc].Position(pc))
+	return nil
+}
+
+// HandleContinue runs the loaded program in the current execution mode from the PC to the next
+// breakpoint or to the end, printing the registers that changed on the way
+func (h *Handler) HandleContinue() error {
+	machine := h.stepper()
+	if machine.Finished() {
+		return fmt.Errorf("program has finished; load it again to restart")
+	}
+	before := h.registers()
+	stopped, err := machine.Continue()
+	h.printRegisterChanges(before, h.registers(), "  ")
+	if err != nil {
+		return err
+	}
+	if stopped {
+		pc := machine.PC()
+		fmt.Printf("Breakpoint: %s\n", machine.GetRISCProgram()[pc].Position(pc))
+	} else {
+		fmt.Println("Program finished")
+	}
+	return nil
+}
+
+// HandleList prints the loaded program as canonical assembly with its instruction indices, marking
+// the current PC with "=>" and breakpoints with "*"
+func (h *Handler) HandleList() error {
+	machine := h.stepper()
+	program, pc, finished := machine.GetRISCProgram(), machine.PC(), machine.Finished()
+	breakpoints := map[uint32]bool{}
+	for _, index := range machine.Breakpoints() {
+		breakpoints[index] = true
+	}
+	lines := h.machine.Disassemble()
+	if h.useHost {
+		lines = make([]string, len(program))
+		for i, inst := range program {
+			lines[i] = inst.String()
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("no program loaded")
+	}
+
+	for i, line := range lines {
+		index := uint32(i)
+		mark := "  "
+		if index == pc && !finished {
+			mark = "=>"
+		}
+		if breakpoints[index] {
+			mark = "*" + mark[1:]
+		}
+		if inst := program[i]; inst.LineNumber != 0 {
+			line = fmt.Sprintf("%-28s # line %d: %s", line, inst.LineNumber, inst.Source)
+		}
+		fmt.Printf("%s %4d  %s\n", mark, index, line)
+	}
+	if finished {
+		fmt.Println("   (program finished)")
+	}
+	return nil
+}
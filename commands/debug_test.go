@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandleList(t *testing.T) {
+	if err := NewHandler(1).HandleList(); err == nil {
+		t.Error("list with no program loaded succeeded")
+	}
+
+	h := NewHandler(1)
+	if err := h.machine.LoadRISCSource("li a0, 5\nloop:\naddi a0, a0, -1\nbne a0, zero, loop\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.shareProgram(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleStep(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.machine.SetBreakpoint(2); err != nil {
+		t.Fatal(err)
+	}
+	out := captureOutput(t, h.HandleList)
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	tests := []struct {
+		prefix string // Marks and index
+		text   string // Canonical instruction and source
+	}{
+		{"      0  ", "addi x10, x0, 5"},
+		{"=>    1  ", "addi x10, x10, -1"},
+		{"*     2  ", "bne x10, x0, -1"},
+	}
+	if len(lines) != len(tests) {
+		t.Fatalf("list printed %d lines, want %d:\n%s", len(lines), len(tests), out)
+	}
+	for i, tt := range tests {
+		if !strings.HasPrefix(lines[i], tt.prefix+tt.text) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], tt.prefix+tt.text)
+		}
+	}
+	if !strings.Contains(lines[2], "bne a0, zero, loop") {
+		t.Errorf("line 2 = %q, want the source text", lines[2])
+	}
+
+	// The host machine lists its own copy of the program, with its own (empty) breakpoints
+	h.HandleMode()
+	out = captureOutput(t, h.HandleList)
+	if !strings.HasPrefix(out, "=>    0  addi x10, x0, 5") || strings.Contains(out, "*") {
+		t.Errorf("host list:\n%s", out)
+	}
+	if err := h.HandleRun(); err != nil {
+		t.Fatal(err)
+	}
+	if out = captureOutput(t, h.HandleList); !strings.HasSuffix(out, "(program finished)\n") || strings.Contains(out, "=>") {
+		t.Errorf("list after the run:\n%s", out)
+	}
+}
+
+func TestHandleBreakContinue(t *testing.T) {
+	source := "addi x5, x0, 1\naddi x6, x0, 2\naddi x7, x0, 3\naddi x8, x0, 4\n"
+	for _, mode := range []string{"VM", "host"} {
+		h := NewHandler(1)
+		if err := h.machine.LoadRISCSource(source); err != nil {
+			t.Fatal(err)
+		}
+		if err := h.shareProgram(); err != nil {
+			t.Fatal(err)
+		}
+		if mode == "host" {
+			h.HandleMode()
+		}
+
+		for _, args := range [][]string{{"2"}, {"3"}, {"clear", "3"}} {
+			if err := h.HandleBreak(args); err != nil {
+				t.Fatalf("%s: break %v: %v", mode, args, err)
+			}
+		}
+		for _, args := range [][]string{{"4"}, {"clear", "3"}, {"x"}} {
+			if err := h.HandleBreak(args); err == nil {
+				t.Errorf("%s: break %v succeeded", mode, args)
+			}
+		}
+		if out := captureOutput(t, func() error { return h.HandleBreak(nil) }); !strings.Contains(out, "addi x7") ||
+			strings.Contains(out, "addi x8") {
+			t.Errorf("%s: breakpoint list:\n%s", mode, out)
+		}
+
+		if err := h.HandleContinue(); err != nil {
+			t.Fatalf("%s: continue: %v", mode, err)
+		}
+		if pc := h.stepper().PC(); pc != 2 {
+			t.Errorf("%s: PC after continue = %d, want the breakpoint at 2", mode, pc)
+		}
+		if regs := h.registers(); regs[6] != 2 || regs[7] != 0 {
+			t.Errorf("%s: x6 = %d, x7 = %d at the breakpoint, want 2 and 0", mode, regs[6], regs[7])
+		}
+		if err := h.HandleContinue(); err != nil {
+			t.Fatalf("%s: second continue: %v", mode, err)
+		}
+		if regs := h.registers(); !h.stepper().Finished() || regs[8] != 4 {
+			t.Errorf("%s: after the second continue finished = %v, x8 = %d, want true and 4", mode,
+				h.stepper().Finished(), regs[8])
+		}
+		if err := h.HandleContinue(); err == nil {
+			t.Errorf("%s: continue after the program finished succeeded", mode)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+
+	"qmachine/quantum"
+)
+
+// HandlePrecision shows or sets the number of decimal places used by amplitude displays
+func (h *Handler) HandlePrecision(args []string) error {
+	if len(args) == 0 {
+		fmt.Printf("Display precision: %d decimal places\n", h.precision)
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: precision [digits]")
+	}
+
+	digits, err := strconv.Atoi(args[0])
+	if err != nil || digits < 0 || digits > 17 {
+		return fmt.Errorf("precision must be an integer from 0 to 17")
+	}
+	h.precision = digits
+	fmt.Printf("Display precision set to %d decimal places\n", digits)
+	return nil
+}
+
+// HandlePhases lists the magnitude and phase of every nonzero amplitude, in degrees (default) or
+// radians, because relative phases are easy to miss in rectangular amplitudes
+func (h *Handler) HandlePhases(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("phases is exclusive to VM execution mode")
+	}
+	unit := "deg"
+	if len(args) == 1 {
+		unit = args[0]
+	}
+	if len(args) > 1 || (unit != "deg" && unit != "rad") {
+		return fmt.Errorf("usage: phases [deg|rad]")
+	}
+
+	state, err := h.displayState()
+	if err != nil {
+		return err
+	}
+	amplitudes := state.NonzeroAmplitudes()
+	if len(amplitudes) == 0 {
+		fmt.Println("No nonzero amplitudes")
+		return nil
+	}
+	for _, basis := range amplitudes {
+		phase, symbol := cmplx.Phase(basis.Amplitude), " rad"
+		if unit == "deg" {
+			phase, symbol = phase*180/math.Pi, "°"
+		}
+		fmt.Printf("  %s  magnitude %.*f  phase %.*f%s\n", h.ket(basis.Basis, state.NumQubits()),
+			h.precision, cmplx.Abs(basis.Amplitude), h.precision, phase, symbol)
+	}
+	return nil
+}
+
+// displayState returns the VM state if it is small enough to list amplitude by amplitude
+func (h *Handler) displayState() (*quantum.QuantumState, error) {
+	state := h.machine.GetState()
+	if state.NumQubits() > maxDisplayQubits {
+		return nil, fmt.Errorf("state has %d qubits; amplitude displays are limited to %d",
+			state.NumQubits(), maxDisplayQubits)
+	}
+	return state, nil
+}
+
+// ket formats a basis state as |q(n-1)...q0⟩
+func (h *Handler) ket(basis quantum.BasisState, numQubits int) string {
+	return "|" + basis.Bits(numQubits) + "⟩"
+}
+
+// diracTermsPerLine is how many terms a Dirac display puts on one line before it switches to
+// one term per line
+const diracTermsPerLine = 8
+
+// HandleThreshold shows or sets the amplitude magnitude below which Dirac displays omit a term
+func (h *Handler) HandleThreshold(args []string) error {
+	if len(args) == 0 {
+		fmt.Printf("Display threshold: %g\n", h.threshold)
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: threshold [value]")
+	}
+	value, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || value < 0 || value >= 1 {
+		return fmt.Errorf("threshold must be a number from 0 up to (not including) 1")
+	}
+	h.threshold = value
+	fmt.Printf("Display threshold set to %g\n", value)
+	return nil
+}
+
+// maxStateVectorQubits bounds the state size that state lists amplitude by amplitude; larger
+// states are summarized instead
+const maxStateVectorQubits = 12
+
+// printStateVector lists every basis state whose amplitude magnitude reaches the display
+// threshold as "|0101⟩: 0.707+0.000i (p=0.500)"
+func (h *Handler) printStateVector() error {
+	state := h.machine.GetState()
+	listed := 0
+	for index, amp := range state.GetAmplitudes() {
+		if cmplx.Abs(amp) < h.threshold {
+			continue
+		}
+		p := real(amp * cmplx.Conj(amp))
+		fmt.Printf("%s: %.*f%+.*fi (p=%.*f)\n", h.ket(quantum.NewBasisState(index), state.NumQubits()), h.precision,
+			real(amp), h.precision, imag(amp), h.precision, p)
+		listed++
+	}
+	if listed == 0 {
+		fmt.Println("No amplitude above the display threshold")
+	}
+	return nil
+}
+
+// printDirac prints the state as a sum of kets, e.g. "0.707107|00⟩ + 0.707107|11⟩", omitting
+// terms whose amplitude magnitude is below the threshold
+func (h *Handler) printDirac() error {
+	if h.useHost {
+		return fmt.Errorf("state dirac is exclusive to VM execution mode")
+	}
+	state, err := h.displayState()
+	if err != nil {
+		return err
+	}
+	var terms []string
+	for _, basis := range state.NonzeroAmplitudes() {
+		if cmplx.Abs(basis.Amplitude) >= h.threshold {
+			terms = append(terms, h.diracTerm(basis.Amplitude)+h.ket(basis.Basis, state.NumQubits()))
+		}
+	}
+	if len(terms) == 0 {
+		fmt.Println("0 (no amplitude above the display threshold)")
+		return nil
+	}
+
+	if len(terms) > diracTermsPerLine {
+		fmt.Println(strings.Join(terms, "\n"))
+		return nil
+	}
+	// Every term starts with "+ " or "- ": the first drops a "+ " and writes "- " as a minus sign
+	if strings.HasPrefix(terms[0], "- ") {
+		terms[0] = "-" + terms[0][2:]
+	} else {
+		terms[0] = strings.TrimPrefix(terms[0], "+ ")
+	}
+	fmt.Println(strings.Join(terms, " "))
+	return nil
+}
+
+// diracTerm formats an amplitude as a signed coefficient: "+ 0.707", "- 0.5i", or, when the phase
+// is not a multiple of π/2, "+ 0.707·e^(iπ/4)"
+func (h *Handler) diracTerm(amp complex128) string {
+	magnitude, phase := cmplx.Abs(amp), cmplx.Phase(amp)
+	digits := h.precision
+	switch {
+	case samePhase(phase, 0):
+		return fmt.Sprintf("+ %.*f", digits, magnitude)
+	case samePhase(phase, math.Pi):
+		return fmt.Sprintf("- %.*f", digits, magnitude)
+	case samePhase(phase, math.Pi/2):
+		return fmt.Sprintf("+ %.*fi", digits, magnitude)
+	case samePhase(phase, -math.Pi/2):
+		return fmt.Sprintf("- %.*fi", digits, magnitude)
+	}
+	sign := ""
+	if phase < 0 {
+		sign, phase = "-", -phase
+	}
+	angle := fmt.Sprintf("%.*f", digits, phase)
+	if quarters := math.Round(phase / (math.Pi / 4)); samePhase(phase, quarters*math.Pi/4) {
+		angle = quarterPhaseNames[quarters]
+	}
+	return fmt.Sprintf("+ %.*f·e^(%si%s)", digits, magnitude, sign, angle)
+}
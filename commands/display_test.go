@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"io"
+	"math"
+	"os"
+	"testing"
+)
+
+// captureOutput returns what f prints to standard output
+func captureOutput(t *testing.T, f func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	ferr := f()
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ferr != nil {
+		t.Fatal(ferr)
+	}
+	return string(out)
+}
+
+func TestStateDiracGolden(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision int
+		threshold float64
+		prepare   func(h *Handler) error
+		want      string
+	}{
+		{"bell", 6, defaultThreshold, func(h *Handler) error { return h.HandleBell([]string{"0", "1"}) },
+			"0.707107|00⟩ + 0.707107|11⟩\n"},
+		{"bell at 3 digits", 3, defaultThreshold, func(h *Handler) error { return h.HandleBell([]string{"0", "1"}) },
+			"0.707|00⟩ + 0.707|11⟩\n"},
+		{"ground", 6, defaultThreshold, func(h *Handler) error { return nil }, "1.000000|00⟩\n"},
+		{"minus", 3, defaultThreshold, func(h *Handler) error {
+			if err := h.HandleGate([]string{"X", "0"}); err != nil {
+				return err
+			}
+			return h.HandleGate([]string{"H", "0"})
+		}, "0.707|00⟩ - 0.707|01⟩\n"},
+		{"phase", 3, defaultThreshold, func(h *Handler) error {
+			if err := h.HandleGate([]string{"H", "0"}); err != nil {
+				return err
+			}
+			return h.HandleGate([]string{"T", "0"})
+		}, "0.707|00⟩ + 0.707·e^(iπ/4)|01⟩\n"},
+		{"above threshold", 3, 0.8, func(h *Handler) error { return h.HandleBell([]string{"0", "1"}) },
+			"0 (no amplitude above the display threshold)\n"},
+	}
+	for _, tt := range tests {
+		h := NewHandler(2)
+		h.precision, h.threshold = tt.precision, tt.threshold
+		if err := tt.prepare(h); err != nil {
+			t.Fatal(err)
+		}
+		got := captureOutput(t, func() error { return h.HandleState([]string{"dirac"}) })
+		if got != tt.want {
+			t.Errorf("%s: state dirac printed %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiracTerm(t *testing.T) {
+	h := NewHandler(1)
+	h.precision = 2
+	r := math.Sqrt2 / 2
+	tests := []struct {
+		amp  complex128
+		want string
+	}{
+		{complex(0.5, 0), "+ 0.50"},
+		{complex(-0.5, 0), "- 0.50"},
+		{complex(0, 0.5), "+ 0.50i"},
+		{complex(0, -0.5), "- 0.50i"},
+		{complex(-r, -r), "+ 1.00·e^(-i3π/4)"},
+		{complex(math.Cos(1), math.Sin(1)), "+ 1.00·e^(i1.00)"},
+	}
+	for _, tt := range tests {
+		if got := h.diracTerm(tt.amp); got != tt.want {
+			t.Errorf("diracTerm(%v) = %q, want %q", tt.amp, got, tt.want)
+		}
+	}
+}
+
+func TestHandleThreshold(t *testing.T) {
+	runHandlerCases(t, "threshold", 1, []handlerCase{
+		{nil, false},
+		{[]string{"0"}, false},
+		{[]string{"0.25"}, false},
+		{[]string{"1"}, true},
+		{[]string{"-0.1"}, true},
+		{[]string{"tiny"}, true},
+		{[]string{"0.1", "0.2"}, true},
+	}, (*Handler).HandleThreshold)
+}
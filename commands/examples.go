@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"fmt"
+
+	"qmachine/examples"
+)
+
+// HandleExamples lists the bundled example programs with a one-line description of each
+func (h *Handler) HandleExamples() {
+	fmt.Println("Bundled examples (load with load-example <name>, then run):")
+	for _, name := range examples.Names() {
+		fmt.Printf("  %-10s %s\n", name, examples.Description(name))
+	}
+}
+
+// HandleLoadExample loads a bundled example program as if it had been loaded from a file
+func (h *Handler) HandleLoadExample(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: load-example <name>")
+	}
+	source, err := examples.Source(args[0])
+	if err != nil {
+		return err
+	}
+	if err := h.machine.LoadRISCSource(source); err != nil {
+		return fmt.Errorf("example %s: %v", args[0], err)
+	}
+	if err := h.shareProgram(); err != nil {
+		return err
+	}
+	fmt.Printf("Loaded example %s (%d instructions)\n", args[0], len(h.machine.GetRISCProgram()))
+	return nil
+}
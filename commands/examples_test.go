@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"testing"
+
+	"qmachine/examples"
+)
+
+func TestHandleLoadExample(t *testing.T) {
+	cases := []handlerCase{{nil, true}, {[]string{"nope"}, true}, {[]string{"bell", "adder"}, true}}
+	for _, name := range examples.Names() {
+		cases = append(cases, handlerCase{[]string{name}, false})
+	}
+	runHandlerCases(t, "load-example", 3, cases, (*Handler).HandleLoadExample)
+
+	// The example is shared with the host machine, so it runs in either mode
+	h := NewHandler(3)
+	if err := h.HandleLoadExample([]string{"fibonacci"}); err != nil {
+		t.Fatal(err)
+	}
+	h.HandleMode()
+	if err := h.HandleRun(); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.registers()[10]; got != 6765 {
+		t.Errorf("fibonacci on the host: x10 = %d, want 6765", got)
+	}
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"sort"
+	"strings"
+
+	"qmachine/quantum"
+)
+
+// explainTolerance is how close probabilities and phases must be to count as equal, and the
+// probability below which a basis state is treated as absent
+const explainTolerance = 1e-9
+
+// quarterPhaseNames names the phases that are multiples of π/4, keyed by the multiple
+var quarterPhaseNames = map[float64]string{
+	0: "0", 1: "π/4", 2: "π/2", 3: "3π/4", 4: "π", -1: "-π/4", -2: "-π/2", -3: "-3π/4", -4: "π",
+}
+
+// explainComponentsShown is how many basis states explain-state lists for an unrecognized state
+const explainComponentsShown = 8
+
+// HandleExplainState describes the current superposition in plain English. The recognition
+// heuristics, tried in order, are deliberately simple:
+//   - one basis state: a classical basis state;
+//   - all 2^n basis states with equal probability: a uniform superposition (|+...+⟩ when all
+//     phases agree);
+//   - two equally likely basis states: the qubits where they differ are in a Bell state (two
+//     qubits) or a GHZ-class state (three or more), or a single qubit is in superposition, while
+//     the remaining qubits hold fixed values;
+//   - anything else: the number of components and the most likely ones with their probabilities.
+func (h *Handler) HandleExplainState(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("explain-state is exclusive to VM execution mode")
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: explain-state")
+	}
+	state, err := h.displayState()
+	if err != nil {
+		return err
+	}
+
+	var components []quantum.BasisAmplitude
+	for _, basis := range state.NonzeroAmplitudes() {
+		if probability(basis) > explainTolerance {
+			components = append(components, basis)
+		}
+	}
+	n := state.NumQubits()
+	switch {
+	case len(components) == 0:
+		fmt.Println("The state has no nonzero amplitudes; reset the machine to start from |0...0⟩.")
+	case len(components) == 1:
+		fmt.Printf("The system is in the basis state %s: every qubit has a definite value, "+
+			"so measuring gives this result with certainty.\n", h.ket(components[0].Basis, n))
+	case len(components) == 1<<n && equalProbabilities(components):
+		h.explainUniform(components, n)
+	case len(components) == 2 && equalProbabilities(components):
+		h.explainPair(components[0], components[1], n)
+	default:
+		h.explainComponents(components, n)
+	}
+	return nil
+}
+
+// explainUniform narrates an equal-weight superposition of every basis state
+func (h *Handler) explainUniform(components []quantum.BasisAmplitude, n int) {
+	fmt.Printf("The system is in a uniform superposition of all %d basis states, each with probability %.*f.\n",
+		len(components), h.precision, probability(components[0]))
+	for _, basis := range components[1:] {
+		if !samePhase(relativePhase(components[0], basis), 0) {
+			fmt.Println("The basis states carry different relative phases, so interference can still change the " +
+				"outcome probabilities.")
+			return
+		}
+	}
+	fmt.Printf("All phases agree: this is the |%s⟩ state, H applied to every qubit of |%s⟩.\n",
+		strings.Repeat("+", n), strings.Repeat("0", n))
+}
+
+// explainPair narrates an equal superposition of two basis states and names Bell and GHZ states
+func (h *Handler) explainPair(a, b quantum.BasisAmplitude, n int) {
+	phase := relativePhase(a, b)
+	fmt.Printf("The system is in an equal superposition of %s and %s with a relative phase of %s",
+		h.ket(a.Basis, n), h.ket(b.Basis, n), phaseText(phase))
+
+	differing := a.Index ^ b.Index
+	qubits := qubitList(differing)
+	// Restricted to the differing qubits, a's bits are all equal for |00⟩+|11⟩-like states
+	aligned := a.Index&differing == 0 || a.Index&differing == differing
+	sign := ""
+	if samePhase(phase, 0) {
+		sign = "+"
+	} else if samePhase(phase, math.Pi) {
+		sign = "-"
+	}
+	switch {
+	case len(qubits) == 1:
+		fmt.Printf(" — only qubit %s is in superposition", qubits[0])
+	case len(qubits) == 2 && sign != "" && aligned:
+		fmt.Printf(" — qubits %s are in the Bell state |Φ%s⟩", strings.Join(qubits, ", "), sign)
+	case len(qubits) == 2 && sign != "":
+		fmt.Printf(" — qubits %s are in the Bell state |Ψ%s⟩", strings.Join(qubits, ", "), sign)
+	case len(qubits) == 2:
+		fmt.Printf(" — qubits %s are maximally entangled (a Bell state up to a phase gate)", strings.Join(qubits, ", "))
+	case aligned:
+		fmt.Printf(" — qubits %s are in a GHZ state", strings.Join(qubits, ", "))
+	default:
+		fmt.Printf(" — qubits %s are in a GHZ-class state (a GHZ state up to X flips)", strings.Join(qubits, ", "))
+	}
+	if fixed := n - len(qubits); fixed > 0 {
+		fmt.Printf("; the other %d qubit(s) have definite values", fixed)
+	}
+	fmt.Println(".")
+}
+
+// explainComponents lists the most likely basis states of a state no heuristic recognized
+func (h *Handler) explainComponents(components []quantum.BasisAmplitude, n int) {
+	weighting := "unequal"
+	if equalProbabilities(components) {
+		weighting = "equal"
+	}
+	fmt.Printf("The system is in a superposition of %d basis states with %s probabilities.\n", len(components), weighting)
+	sort.SliceStable(components, func(i, j int) bool { return probability(components[i]) > probability(components[j]) })
+	if len(components) > explainComponentsShown {
+		fmt.Printf("The %d most likely outcomes:\n", explainComponentsShown)
+		components = components[:explainComponentsShown]
+	}
+	for _, basis := range components {
+		fmt.Printf("  %s  probability %.*f  phase %s\n", h.ket(basis.Basis, n), h.precision, probability(basis),
+			phaseText(cmplx.Phase(basis.Amplitude)))
+	}
+}
+
+// probability returns the Born-rule probability |amplitude|^2 of a basis state
+func probability(basis quantum.BasisAmplitude) float64 {
+	return real(basis.Amplitude * cmplx.Conj(basis.Amplitude))
+}
+
+// equalProbabilities reports whether every component is equally likely
+func equalProbabilities(components []quantum.BasisAmplitude) bool {
+	for _, basis := range components[1:] {
+		if math.Abs(probability(basis)-probability(components[0])) > explainTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// relativePhase returns the phase of b relative to a, in (-π, π]
+func relativePhase(a, b quantum.BasisAmplitude) float64 {
+	return cmplx.Phase(b.Amplitude * cmplx.Conj(a.Amplitude))
+}
+
+// samePhase compares two phases modulo 2π
+func samePhase(a, b float64) bool {
+	d := math.Mod(math.Abs(a-b), 2*math.Pi)
+	return d < 1e-6 || 2*math.Pi-d < 1e-6
+}
+
+// phaseText formats a phase as a multiple of π/4 where possible (e.g. "π/2"), else in degrees
+func phaseText(phase float64) string {
+	quarters := math.Round(phase / (math.Pi / 4))
+	if !samePhase(phase, quarters*math.Pi/4) {
+		return fmt.Sprintf("%.2f°", phase*180/math.Pi)
+	}
+	return quarterPhaseNames[quarters]
+}
+
+// qubitList names the qubits whose bits are set in mask, e.g. "q0"
+func qubitList(mask int) []string {
+	var qubits []string
+	for mask != 0 {
+		q := bits.TrailingZeros(uint(mask))
+		qubits = append(qubits, fmt.Sprintf("q%d", q))
+		mask &^= 1 << q
+	}
+	return qubits
+}
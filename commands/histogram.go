@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// histogramMaxBars caps how many outcomes a histogram draws; the rest are summarized in one line
+const histogramMaxBars = 32
+
+// defaultTerminalWidth is the chart width used when $COLUMNS does not give the terminal width
+const defaultTerminalWidth = 80
+
+// histogramBar is one outcome of a sampled distribution
+type histogramBar struct {
+	label string
+	count int
+}
+
+// HandleHistogram samples one qubit `shots` times and charts the 0/1 outcome distribution
+func (h *Handler) HandleHistogram(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("histogram is exclusive to VM execution mode")
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: histogram <qubit> <shots>")
+	}
+	qubit, err := h.parseQubitIndex(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid qubit index: %v", err)
+	}
+	if qubit < 0 || qubit >= h.machine.GetState().NumQubits() {
+		return fmt.Errorf("invalid qubit number: %d", qubit)
+	}
+	shots, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid shot count: %v", err)
+	}
+	counts, err := h.machine.Sample(shots)
+	if err != nil {
+		return err
+	}
+
+	var ones int
+	for index, count := range counts {
+		ones += (index >> qubit & 1) * count
+	}
+	fmt.Printf("Qubit %d over %d shots:\n", qubit, shots)
+	printHistogram([]histogramBar{{"0", shots - ones}, {"1", ones}}, shots)
+	return nil
+}
+
+// HandleMeasureShots measures a qubit on `shots` clones of the state and prints the 0/1 counts
+// with a histogram, leaving the live state untouched
+func (h *Handler) HandleMeasureShots(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("measure-shots is exclusive to VM execution mode")
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: measure-shots <qubit> <shots>")
+	}
+	qubit, err := h.parseQubitIndex(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid qubit index: %v", err)
+	}
+	shots, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid shot count: %v", err)
+	}
+	ones, err := h.machine.MeasureShots(qubit, shots)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Qubit %d measured %d times: %d zeros, %d ones\n", qubit, shots, shots-ones, ones)
+	printHistogram([]histogramBar{{"0", shots - ones}, {"1", ones}}, shots)
+	return nil
+}
+
+// HandleHistogramAll samples every qubit `shots` times and charts the basis state distribution
+func (h *Handler) HandleHistogramAll(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("histogram-all is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: histogram-all <shots>")
+	}
+	shots, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid shot count: %v", err)
+	}
+	counts, err := h.machine.Sample(shots)
+	if err != nil {
+		return err
+	}
+
+	n := h.machine.GetState().NumQubits()
+	indices := make([]int, 0, len(counts))
+	for index := range counts {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	bars := make([]histogramBar, len(indices))
+	for i, index := range indices {
+		bars[i] = histogramBar{fmt.Sprintf("%0*b", n, index), counts[index]}
+	}
+	fmt.Printf("%d outcome(s) over %d shots:\n", len(bars), shots)
+	printHistogram(bars, shots)
+	return nil
+}
+
+// printHistogram draws one horizontal bar per outcome, e.g. "00 |####### 0.710 (710)", scaled so
+// the most frequent outcome fills the terminal width. Beyond histogramMaxBars outcomes only the
+// most frequent are drawn, in their original order.
+func printHistogram(bars []histogramBar, shots int) {
+	hidden := 0
+	if len(bars) > histogramMaxBars {
+		byCount := append([]histogramBar(nil), bars...)
+		sort.SliceStable(byCount, func(i, j int) bool { return byCount[i].count > byCount[j].count })
+		cutoff := byCount[histogramMaxBars-1].count
+		kept := bars[:0:0]
+		for _, bar := range bars {
+			if bar.count >= cutoff && len(kept) < histogramMaxBars {
+				kept = append(kept, bar)
+			}
+		}
+		hidden, bars = len(bars)-len(kept), kept
+	}
+
+	labelWidth, maxCount := 0, 0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, len(bar.label))
+		maxCount = max(maxCount, bar.count)
+	}
+	countWidth := len(strconv.Itoa(shots))
+	// Room left for the bar after "label |" and " 0.000 (count)"
+	barWidth := max(terminalWidth()-labelWidth-countWidth-12, 10)
+	for _, bar := range bars {
+		length := 0
+		if maxCount > 0 {
+			length = bar.count * barWidth / maxCount
+		}
+		fmt.Printf("%*s |%-*s %.3f (%*d)\n", labelWidth, bar.label, barWidth, strings.Repeat("#", length),
+			float64(bar.count)/float64(shots), countWidth, bar.count)
+	}
+	if hidden > 0 {
+		fmt.Printf("... %d less frequent outcome(s) not shown\n", hidden)
+	}
+}
+
+// terminalWidth returns the terminal width from $COLUMNS, or defaultTerminalWidth
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return defaultTerminalWidth
+}
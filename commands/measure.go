@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HandleParity measures the joint Z-basis parity of the listed qubits. Only the XOR of their
+// values is revealed, so superpositions within the observed parity subspace are preserved.
+func (h *Handler) HandleParity(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("parity is exclusive to VM execution mode")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: parity <q1> <q2> ...")
+	}
+
+	qubits := make([]int, 0, len(args))
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		qubit, err := h.parseQubitIndex(arg)
+		if err != nil {
+			return fmt.Errorf("invalid qubit index: %v", err)
+		}
+		qubits = append(qubits, qubit)
+		names = append(names, fmt.Sprintf("q%d", qubit))
+	}
+
+	parity, probability, err := h.machine.MeasureParity(qubits...)
+	if err != nil {
+		return err
+	}
+	kind := "even"
+	if parity == 1 {
+		kind = "odd"
+	}
+	fmt.Printf("Parity of %s: %d (%s, p=%.6f)\n", strings.Join(names, ", "), parity, kind, probability)
+	return nil
+}
+
+// HandleResetQubits resets each listed qubit to |0⟩ by measuring it and correcting a 1 with X,
+// so ancilla qubits can be reused between sub-computations
+func (h *Handler) HandleResetQubits(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("reset-qubits is exclusive to VM execution mode")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: reset-qubits <q1> <q2> ...")
+	}
+	qubits := make([]int, 0, len(args))
+	for _, arg := range args {
+		qubit, err := h.parseQubitIndex(arg)
+		if err != nil {
+			return fmt.Errorf("invalid qubit index: %v", err)
+		}
+		if qubit < 0 || qubit >= h.machine.GetState().NumQubits() {
+			return fmt.Errorf("invalid qubit number: %d", qubit)
+		}
+		qubits = append(qubits, qubit)
+	}
+
+	collapsed := false
+	for _, qubit := range qubits {
+		result, err := h.machine.ResetQubit(qubit)
+		if err != nil {
+			return err
+		}
+		action := "already |0⟩"
+		if result.Outcome == 1 {
+			action = "flipped back to |0⟩"
+		}
+		fmt.Printf("Reset qubit %d (measured %d with p=%.6f, %s)\n", qubit, result.Outcome, result.Probability, action)
+		collapsed = collapsed || result.Probability < 1-1e-9
+	}
+	if collapsed {
+		fmt.Println("Note: reset measures each qubit, so qubits entangled with a reset qubit have collapsed too")
+	}
+	return nil
+}
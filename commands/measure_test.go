@@ -0,0 +1,43 @@
+package commands
+
+import "testing"
+
+func TestHandleParity(t *testing.T) {
+	runHandlerCases(t, "parity", 3, []handlerCase{
+		{[]string{"0", "1"}, false},
+		{[]string{"2"}, false},
+		{[]string{"0", "1", "2"}, false},
+		{nil, true},
+		{[]string{"0", "0"}, true},
+		{[]string{"0", "3"}, true},
+		{[]string{"q0"}, true},
+	}, (*Handler).HandleParity)
+
+	h := NewHandler(2)
+	h.HandleMode()
+	if err := h.HandleParity([]string{"0", "1"}); err == nil {
+		t.Error("parity ran in host-native mode")
+	}
+}
+
+func TestHandleResetQubits(t *testing.T) {
+	runHandlerCases(t, "reset-qubits", 3, []handlerCase{
+		{[]string{"0"}, false},
+		{[]string{"0", "2"}, false},
+		{nil, true},
+		{[]string{"3"}, true},
+		{[]string{"-1"}, true},
+		{[]string{"one"}, true},
+	}, (*Handler).HandleResetQubits)
+
+	h := NewHandler(2)
+	if err := h.HandleGate([]string{"X", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleResetQubits([]string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	if p := h.machine.GetState().MeasureProbability(1, 0); p != 1 {
+		t.Errorf("P(q1 = 0) = %g after reset-qubits 1, want 1", p)
+	}
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+
+	"qmachine/quantum"
+)
+
+// registerDiffFormats are the value formats diff-registers accepts
+var registerDiffFormats = map[string]bool{"dec": true, "hex": true, "signed": true}
+
+// HandleDiffRegisters turns the register diff printed after each riscv and run command on (in
+// decimal, hex or signed form) or off; without arguments it shows the current setting
+func (h *Handler) HandleDiffRegisters(args []string) error {
+	if len(args) > 1 || (len(args) == 1 && args[0] != "off" && args[0] != "on" && !registerDiffFormats[args[0]]) {
+		return fmt.Errorf("usage: diff-registers [on|off|dec|hex|signed]")
+	}
+	if len(args) == 1 {
+		switch args[0] {
+		case "off":
+			h.regDiff = ""
+		case "on":
+			h.regDiff = "dec"
+		default:
+			h.regDiff = args[0]
+		}
+	}
+	if h.regDiff == "" {
+		fmt.Println("Register diffs are off")
+	} else {
+		fmt.Printf("Register diffs are on (%s)\n", h.regDiff)
+	}
+	return nil
+}
+
+// registers returns the register file of the current execution mode
+func (h *Handler) registers() [quantum.NumRegisters]uint64 {
+	if h.useHost {
+		return h.hostMachine.GetRegisters()
+	}
+	return h.machine.GetRegisters()
+}
+
+// withRegisterDiff runs exec and, when register diffs are on, prints every register it changed
+// as "x5: 0 -> 42". The diff is printed even if exec fails part way.
+func (h *Handler) withRegisterDiff(snapshot func() [quantum.NumRegisters]uint64, exec func() error) error {
+	if h.regDiff == "" {
+		return exec()
+	}
+	before := snapshot()
+	err := exec()
+	if !h.printRegisterChanges(before, snapshot(), "") {
+		fmt.Println("(no registers changed)")
+	}
+	return err
+}
+
+// printRegisterChanges prints every register that differs between two snapshots as
+// "x5: 0 -> 42" after indent, and reports whether any did
+func (h *Handler) printRegisterChanges(before, after [quantum.NumRegisters]uint64, indent string) bool {
+	changed := false
+	for i := range before {
+		if before[i] != after[i] {
+			fmt.Printf("%sx%d: %s -> %s\n", indent, i, h.formatRegister(before[i]), h.formatRegister(after[i]))
+			changed = true
+		}
+	}
+	return changed
+}
+
+// formatRegister renders a register value in the diff-registers format (decimal when diffs are off)
+func (h *Handler) formatRegister(value uint64) string {
+	switch h.regDiff {
+	case "hex":
+		return fmt.Sprintf("0x%X", value)
+	case "signed":
+		shift := 64 - h.machine.GetXLEN() // Sign-extend from bit XLEN-1
+		return fmt.Sprintf("%d", int64(value<<shift)>>shift)
+	default:
+		return fmt.Sprintf("%d", value)
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"qmachine/quantum"
+)
+
+// HandleExportState writes the nonzero amplitudes of the current state to a JSON or CSV file
+func (h *Handler) HandleExportState(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("export-state is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: export-state <file.json|file.csv>")
+	}
+	state := h.machine.GetState()
+	if err := quantum.ExportState(state, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d nonzero amplitudes to %s\n", len(state.NonzeroAmplitudes()), args[0])
+	return nil
+}
+
+// HandleImportState replaces the current state with one read from a JSON or CSV file
+func (h *Handler) HandleImportState(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("import-state is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: import-state <file.json|file.csv>")
+	}
+	state, err := quantum.ImportState(args[0], h.machine.GetState().NumQubits())
+	if err != nil {
+		return err
+	}
+	if err := h.machine.LoadState(state); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d nonzero amplitudes from %s\n", len(state.NonzeroAmplitudes()), args[0])
+	return nil
+}
+
+// HandleExportQASM writes the recorded gate history to a file as an OpenQASM 2.0 program
+func (h *Handler) HandleExportQASM(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("export-qasm is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: export-qasm <file>")
+	}
+	file, err := os.Create(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to create QASM file: %v", err)
+	}
+	err = h.machine.ExportQASM(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(args[0]) // Do not leave a partial program behind
+		return err
+	}
+	fmt.Printf("Exported %d recorded operation(s) to %s\n", len(h.machine.GetGateHistory()), args[0])
+	return nil
+}
+
+// HandleSaveState checkpoints the current state to a binary file that load-state restores exactly
+func (h *Handler) HandleSaveState(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("save-state is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: save-state <file>")
+	}
+	if err := h.machine.GetState().SaveState(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Saved the state to %s\n", args[0])
+	return nil
+}
+
+// HandleLoadState replaces the current state with a checkpoint written by save-state
+func (h *Handler) HandleLoadState(args []string) error {
+	if h.useHost {
+		return fmt.Errorf("load-state is exclusive to VM execution mode")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: load-state <file>")
+	}
+	state := new(quantum.QuantumState)
+	if err := state.LoadState(args[0]); err != nil {
+		return err
+	}
+	if err := h.machine.LoadState(state); err != nil {
+		return err
+	}
+	fmt.Printf("Loaded the state from %s\n", args[0])
+	return nil
+}
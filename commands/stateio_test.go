@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleExportImportState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bell.csv")
+	h := NewHandler(2)
+	if err := h.HandleBell([]string{"0", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleExportState([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewHandler(2)
+	if err := other.HandleImportState([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+	for i, amp := range h.machine.GetState().GetAmplitudes() {
+		if got := other.machine.GetState().GetAmplitudes()[i]; got != amp {
+			t.Errorf("imported amplitude %d = %v, want %v", i, got, amp)
+		}
+	}
+
+	runHandlerCases(t, "export-state", 2, []handlerCase{
+		{nil, true},
+		{[]string{"state.txt"}, true},
+		{[]string{"a.json", "b.json"}, true},
+	}, (*Handler).HandleExportState)
+	runHandlerCases(t, "import-state", 3, []handlerCase{
+		{nil, true},
+		{[]string{path}, false}, // A CSV file has no qubit count, and |00⟩ + |11⟩ fits 3 qubits
+		{[]string{filepath.Join(filepath.Dir(path), "missing.json")}, true},
+	}, (*Handler).HandleImportState)
+}
+
+func TestHandleSaveLoadState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ghz.bin")
+	h := NewHandler(3)
+	if err := h.HandleGHZ([]string{"0", "1", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HandleSaveState([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewHandler(3)
+	if err := other.HandleLoadState([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+	for i, amp := range h.machine.GetState().GetAmplitudes() {
+		if got := other.machine.GetState().GetAmplitudes()[i]; got != amp {
+			t.Errorf("loaded amplitude %d = %v, want %v", i, got, amp)
+		}
+	}
+
+	runHandlerCases(t, "save-state", 1, []handlerCase{
+		{nil, true},
+		{[]string{"a.bin", "b.bin"}, true},
+	}, (*Handler).HandleSaveState)
+	runHandlerCases(t, "load-state", 2, []handlerCase{
+		{nil, true},
+		{[]string{path}, true}, // Saved from 3 qubits
+		{[]string{filepath.Join(filepath.Dir(path), "missing.bin")}, true},
+	}, (*Handler).HandleLoadState)
+}
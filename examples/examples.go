@@ -0,0 +1,48 @@
+// Package examples bundles ready-to-run Q-RISC-V programs, so new users can run a real program
+// without hunting for files
+package examples
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// programs holds the bundled example sources, one <name>.riscq file per example
+//
+//go:embed *.riscq
+var programs embed.FS
+
+// extension is the file extension of the bundled programs
+const extension = ".riscq"
+
+// Names lists the bundled examples in sorted order
+func Names() []string {
+	entries, _ := programs.ReadDir(".") // The embedded root always exists
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), extension))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the program text of the named example
+func Source(name string) (string, error) {
+	data, err := programs.ReadFile(name + extension)
+	if err != nil {
+		return "", fmt.Errorf("unknown example '%s' (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return string(data), nil
+}
+
+// Description returns the first line of an example's leading comment, e.g. "Bell state: ..."
+func Description(name string) string {
+	source, err := Source(name)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(source, "\n")
+	return strings.TrimSpace(strings.TrimPrefix(line, "#"))
+}
@@ -0,0 +1,93 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"qmachine/quantum"
+)
+
+// expectedRegisters is what each bundled example leaves in its result registers, whatever the
+// measurement outcomes
+var expectedRegisters = map[string]map[int]uint64{
+	"adder":     {10: 150},
+	"bell":      {12: 0},
+	"fibonacci": {10: 6765},
+	"grover":    {10: 1, 11: 1},
+}
+
+func TestExamplesRun(t *testing.T) {
+	for _, name := range Names() {
+		want, ok := expectedRegisters[name]
+		if !ok {
+			t.Errorf("example %s has no expected result in this test", name)
+			continue
+		}
+		source, err := Source(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for seed := int64(0); seed < 8; seed++ {
+			result, err := quantum.RunProgram(source, quantum.Options{Seed: seed})
+			if err != nil {
+				t.Fatalf("%s (seed %d): %v", name, seed, err)
+			}
+			for reg, value := range want {
+				if got := result.Registers[reg]; got != value {
+					t.Errorf("%s (seed %d): x%d = %d, want %d", name, seed, reg, got, value)
+				}
+			}
+		}
+	}
+}
+
+// TestExamplesDisassemble checks that every example reassembles from its disassembly to the same
+// instructions
+func TestExamplesDisassemble(t *testing.T) {
+	for _, name := range Names() {
+		source, err := Source(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := quantum.NewQuantumRISCVMachine(4)
+		if err := m.LoadRISCSource(source); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		again := quantum.NewQuantumRISCVMachine(4)
+		if err := again.LoadRISCSource(strings.Join(m.Disassemble(), "\n") + "\n"); err != nil {
+			t.Fatalf("%s: reassembling the disassembly: %v", name, err)
+		}
+		want, got := m.GetRISCProgram(), again.GetRISCProgram()
+		if len(got) != len(want) {
+			t.Fatalf("%s: %d instructions after the round trip, want %d", name, len(got), len(want))
+		}
+		for pc := range want {
+			w, g := want[pc], got[pc]
+			w.Source, w.LineNumber, g.Source, g.LineNumber = "", 0, "", 0
+			if g != w {
+				t.Errorf("%s: PC %d is %+v after the round trip, want %+v", name, pc, g, w)
+			}
+		}
+	}
+}
+
+func TestSourceAndDescription(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"bell", false},
+		{"grover", false},
+		{"teleport", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		_, err := Source(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Source(%q): error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if description := Description(tt.name); (description == "") != tt.wantErr {
+			t.Errorf("Description(%q) = %q", tt.name, description)
+		}
+	}
+}
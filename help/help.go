@@ -5,19 +5,77 @@ package help
 func GetBasicCommands() string {
 	return `Available commands:
   gate <type> <target> [controls...] - Apply a quantum gate
-  measure <qubit>                    - Measure a qubit
-  state                              - Show current quantum state
+  gate <type> <target> ... if <cbit> - Apply the gate only if classical bit cbit is 1
+  gate RX|RY|RZ <target> <theta> ... - Rotate a qubit by theta radians about the X, Y or Z axis
+  gate P <target> <theta> ...        - Phase shift: multiply |1> by e^(i*theta)
+  gate SDG|TDG <target> [controls]   - Apply S† or T†, the inverses of S and T
+  gate SWAP <q1> <q2>                - Exchange the states of two qubits
+  gate CCNOT <target> <c1> <c2>      - Toffoli: flip target when both controls are 1
+  bell <q1> <q2>                     - Prepare Bell state (|00>+|11>)/sqrt2 (H q1, CNOT q1->q2)
+  ghz <q1> <q2> ... <qn>             - Prepare GHZ state (|0..0>+|1..1>)/sqrt2 (H q1, CNOT chain)
+  qft <q0> <q1> ... <qn>             - Quantum Fourier transform, q0 least significant
+  iqft <q0> <q1> ... <qn>            - Inverse quantum Fourier transform
+  zz-interaction <qA> <qB> <gamma>   - Apply exp(-i*gamma*Za*Zb) (QAOA cost term)
+  mixer <beta>                       - Apply RX(2*beta) to every qubit (QAOA mixer layer)
+  global-phase <theta>               - Multiply the whole state by e^(i*theta)
+  measure <qubit> [cbit]             - Measure a qubit, optionally storing the result in a classical bit
+  reset-qubits <q1> <q2> ...         - Reset qubits to |0> by measuring and correcting (collapses partners)
+  parity <q1> <q2> ...               - Measure the joint parity (XOR) of qubits, collapsing only the parity
+  cbits                              - List the classical bits set by measurements
+  force-collapse <bitstring>         - Collapse onto a basis state (debug, rightmost bit is q0)
+  norm                               - Show total probability sum |amp|^2 (should be ~1) and the support size
+  support-size                       - Count the basis states with nonzero amplitude
+  argmax                             - Most probable basis state and its probability (no collapse)
+  probs [k]                          - Basis state probabilities, most probable first (top k, default 16)
+  histogram <qubit> <shots>          - Sample a qubit and chart the 0/1 distribution as ASCII bars
+  histogram-all <shots>              - Sample every qubit and chart the basis state distribution
+  measure-shots <qubit> <shots>      - Measure a qubit on fresh copies of the state and count 0s and 1s
+  prob-pattern <q0=1,q3=0,...>       - Probability of a qubit value pattern (no collapse)
+  seed <value>                       - Re-seed the measurement RNG (alias: reset-seed)
+  state                              - List the amplitudes of up to 12 qubits (else the register summary)
+  state dirac                        - Print the state as a ket sum, e.g. 0.707107|00⟩ + 0.707107|11⟩
+  export-qasm <file>                 - Write the applied gates as an OpenQASM 2.0 program
+  export-state <file>                - Write the nonzero amplitudes to a .json or .csv file (index, re, im)
+  import-state <file>                - Replace the state with one read from a .json or .csv file
+  save-state <file>                  - Checkpoint the state to a binary file, bit for bit
+  load-state <file>                  - Restore a state saved with save-state (clears the gate history)
+  threshold [value]                  - Show or set the magnitude below which state displays omit terms
+  phases [deg|rad]                   - Magnitude and phase of each nonzero amplitude
+  entanglement-map                   - Pairwise mutual information of all qubits (up to 16 qubits)
+  explain-state                      - Describe the current superposition in plain English
+  precision [digits]                 - Show or set decimal places for amplitude displays
+  bench-gates [qubits]               - Time gates and measurement and count allocations per operation
+  randomized-benchmark <len> <n>     - Random Clifford layers plus their inverse; report survival probability
+  noise [bitflip|damping <p>]        - Show the per-gate noise, or set a channel's strength (0 turns it off)
   reset                              - Reset quantum state
+  circuit                            - List the gates applied so far
+  resource-estimate                  - T-count, CNOT count and total gate count of the recorded circuit
+  stats                              - Instructions executed, gates applied by type and measurements since reset
+  invert                             - Apply the inverse of the recorded circuit (uncompute)
   riscv <instruction>                - Execute RISC-V instruction
+  calc <rd> = <expression>           - Compute e.g. "x1 * 2 + x2" (+ - * parens) into rd
   load <file>                        - Load RISC-V program from file
-  run                                - Run loaded RISC-V program
+  examples                           - List the bundled example programs
+  load-example <name>                - Load a bundled example program (e.g. bell, grover)
+  run                                - Run loaded RISC-V program in the current execution mode
+  run-state [fresh|current]          - Start run from |0...0⟩ or from the current state (default: current)
+  step [count]                       - Execute the next instruction(s), printing each and the registers changed
+  break [[clear] <index>]            - Set, clear or list breakpoints at instruction indices
+  continue                           - Run the loaded program to the next breakpoint or the end
+  list                               - Disassemble the loaded program, marking the PC (=>) and breakpoints (*)
+  run-value <register>               - Run loaded program and print the register as its result
   run-host                           - Run loaded program using host-native execution
   mode                               - Toggle between VM and host-native execution
   registers                          - Show RISC-V registers
-  help                               - Show this help message
+  diff-registers [on|off|hex|signed] - Print the registers each riscv/run command changed
+  list-ops [json]                    - List every supported instruction and gate with its operands
+  describe <instruction|gate>        - Show the syntax, meaning and an example of one instruction or gate
+  export-session <file>              - Save this session's commands as a replayable script
+  replay <file>                      - Run the commands of a script saved by export-session
+  help [instruction|gate]            - Show this help message, or describe one instruction or gate
   exit                               - Exit REPL
 
-Available gates: X, Y, Z, H, S, T, CNOT`
+Available gates: X, Y, Z, H, S, T, CNOT, I (identity/wait)`
 }
 
 // GetQuantumInstructions returns help text for quantum RISC-V instructions
@@ -26,7 +84,13 @@ func GetQuantumInstructions() string {
   qinit rd                          - Initialize quantum register with |0⟩
   qapply rd, rs1, imm              - Apply quantum gate (imm: 0=X, 1=Y, 2=Z, 3=H, 4=S, 5=T, 6=CNOT)
   qmeasure rd, rs1                 - Measure quantum register
-  qentangle rd, rs1, rs2          - Entangle two quantum registers`
+  qentangle rd, rs1, rs2          - Entangle two quantum registers
+  qmov rd, rs1                    - Move quantum register rs1 to rd (rs1 becomes uninitialized)
+  qgate.if rs, GATE, target        - Apply a single-qubit gate to a machine qubit only if rs != 0
+  qgate.r GATE, rs                 - Apply a single-qubit gate to the machine qubit whose index is in rs
+  qgate.c GATE, control, target    - Apply a single-qubit gate to a machine qubit, controlled by another qubit
+  qgate.mc GATE, target, rs1, rs2  - Apply a gate controlled by the rs2 qubit indices (words) at address rs1
+  qmeas.mem qubit, rs              - Measure a machine qubit and store the 0/1 result as a byte at address rs`
 }
 
 // GetRISCVInstructions returns help text for standard RISC-V instructions
@@ -53,6 +117,7 @@ func GetRISCVInstructions() string {
   sltiu rd, rs1, imm  - Set if less than immediate unsigned
   lui rd, imm         - Load upper immediate
   auipc rd, imm       - Add upper immediate to PC
+  li rd, imm          - Load any 64-bit constant (pseudo-instruction, expands to lui/addi/slli)
   jal rd, offset      - Jump and link
   jalr rd, rs1, offset - Jump and link register
   beq rs1, rs2, offset - Branch if equal
@@ -69,5 +134,17 @@ func GetRISCVInstructions() string {
   lbu rd, offset(rs1)  - Load byte unsigned
   sw rs2, offset(rs1)  - Store word
   sh rs2, offset(rs1)  - Store halfword
-  sb rs2, offset(rs1)  - Store byte`
+  sb rs2, offset(rs1)  - Store byte
+
+Labels: "name:" marks the next instruction; branches and jal accept a label in place of the offset.
+Offsets and the PC count instructions: jal/jalr save pc + 1 as the return address.
+Defining the same label twice is reported as an error when the program is loaded.
+Directives: ".globl sym" declares a global symbol; run with -entry=<label> to start at a label.
+Unimplemented directives (.file, .type, .size, .cfi_*, ...) are skipped; ".section .text"/".rodata" switch sections.
+".data" holds .byte/.half/.word/.dword values (loaded at 0x10000); ".text" switches back to code.
+".skip n" / ".space n" reserves n zero bytes in .data ("buf: .skip 64"); ".skip n, fill" uses another byte.
+PC-relative data: "L: auipc rd, %pcrel_hi(sym)" then "lw rd2, %pcrel_lo(L)(rd)" or "addi rd, rd, %pcrel_lo(L)".
+Character literals: an immediate may be written as 'A' (65), '\n', '\t', '\0', '\\' or '\x41'.
+12-bit immediates (I-type, load/store, jalr and branch offsets): decimal values must fit -2048..2047;
+hex/binary/octal values (0x, 0b, 0o) are raw bits 0x000..0xFFF, sign-extended (0xFFF = -1, 0x800 = -2048).`
 }
@@ -4,44 +4,150 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"qmachine/quantum"
 	"qmachine/repl"
 )
 
+// quiet suppresses banners and register dumps in the file-execution modes
+var quiet bool
+
+// skipUnknown loads unknown instructions as no-ops (with a warning) instead of aborting
+var skipUnknown bool
+
+// entryLabel names the label where program execution starts (empty for instruction 0)
+var entryLabel string
+
+// detectLoops stops a run with an error when a backward jump repeats an earlier machine state
+var detectLoops bool
+
+// rngAlgorithm selects the measurement RNG (see quantum.NewRNG)
+var rngAlgorithm string
+
+// stackTop is the initial stack pointer (x2) in the file-execution modes
+var stackTop uint64
+
+// warnDirectives prints a warning for each assembler directive a program uses that is skipped
+var warnDirectives bool
+
+// polarPhases applies phase gates in polar form in VM mode
+var polarPhases bool
+
+// renormalizeEvery renormalizes the VM state after every n gates (0: only after measurements)
+var renormalizeEvery int
+
+// stateBackend selects dense or sparse amplitude storage for the VM state (see quantum.SetBackend)
+var stateBackend string
+
+// noise is the per-gate noise model set with -bit-flip and -amplitude-damping (VM mode)
+var noise quantum.NoiseModel
+
+// seed is the measurement RNG seed given with -seed; seedSet reports whether it was given (runs
+// are otherwise seeded from the clock)
+var (
+	seed    int64
+	seedSet bool
+)
+
+// showMeasurements prints every measurement with its outcome and probability as it happens
+var showMeasurements bool
+
 func main() {
 	// Define command-line flags
 	numQubits := flag.Int("qubits", 2000, "Number of qubits for the quantum computer")
 	quantumFile := flag.String("quantum", "", "Path to quantum RISC-V file to execute")
 	hostQuantumFile := flag.String("host-quantum", "", "Path to quantum RISC-V file to execute on host")
+	scriptFile := flag.String("script", "", "Run the REPL commands in this file non-interactively, then exit")
+	continueOnError := flag.Bool("continue-on-error", false,
+		"With -script, report failing commands and keep going instead of stopping at the first one")
+	xlen := flag.Int("xlen", quantum.DefaultXLEN, "Register width in bits: 32 (RV32) or 64 (RV64)")
+	flag.BoolVar(&quiet, "quiet", false, "Suppress non-essential output (banners, register dumps) in file-execution modes")
+	flag.BoolVar(&skipUnknown, "skip-unknown", false,
+		"Warn about unknown instructions and execute them as no-ops instead of aborting")
+	flag.StringVar(&entryLabel, "entry", "", "Label to start execution at (e.g. _start); defaults to the first instruction")
+	compareWith := flag.String("compare-with", "",
+		"Run the -quantum program and this reference program, and exit nonzero if their results differ")
+	flag.BoolVar(&polarPhases, "polar-phases", false,
+		"Apply phase gates (Z, S, T, global phase) in polar form so amplitude magnitudes never drift (VM mode)")
+	flag.StringVar(&stateBackend, "state-backend", quantum.BackendAuto,
+		fmt.Sprintf("VM state storage %v: dense slice, sparse map, or auto (by qubit count and populated states)",
+			quantum.StateBackends()))
+	flag.IntVar(&renormalizeEvery, "renormalize-every", 0,
+		"Renormalize the state after every n gates to curb rounding drift in long circuits (0: only after measurements)")
+	flag.Float64Var(&noise.BitFlip, "bit-flip", 0,
+		"Flip each qubit a gate touches with this probability after the gate (VM mode noise model)")
+	flag.Float64Var(&noise.AmplitudeDamping, "amplitude-damping", 0,
+		"Amplitude-damping (T1 decay) probability for each qubit a gate touches (VM mode noise model)")
+	flag.BoolVar(&detectLoops, "detect-loops", false,
+		"Stop with an error when a program revisits a PC with an identical machine state (infinite loop)")
+	flag.BoolVar(&warnDirectives, "warn-directives", false,
+		"Warn about each unimplemented assembler directive (.file, .cfi_*, ...) that is skipped while loading")
+	flag.BoolVar(&showMeasurements, "show-measurements", false,
+		"Print each measurement with its outcome and probability during a run")
+	flag.Uint64Var(&stackTop, "stack-top", quantum.DefaultStackTop,
+		"Initial stack pointer (x2); the stack grows down from this address")
+	flag.StringVar(&rngAlgorithm, "rng", quantum.DefaultRNG,
+		fmt.Sprintf("Measurement RNG algorithm %v; splitmix64 gives identical outcomes on every platform",
+			quantum.RNGAlgorithms()))
+	flag.Int64Var(&seed, "seed", 0, "Seed the measurement RNG so runs are reproducible (default: seeded from the clock)")
 	flag.Parse()
+	flag.Visit(func(f *flag.Flag) {
+		seedSet = seedSet || f.Name == "seed"
+	})
 
-	// Create the quantum computer REPL
+	// Create the quantum computer REPL, with machines configured like the file-execution modes
 	replInstance := repl.New(*numQubits)
+	if err := configureREPL(replInstance, *numQubits, *xlen); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Handle file execution modes
 	if *hostQuantumFile != "" {
-		fmt.Printf("Executing quantum RISC-V file on host: %s\n", *hostQuantumFile)
-		if err := executeHostQuantumFile(*hostQuantumFile, *numQubits); err != nil {
+		logf("Executing quantum RISC-V file on host: %s\n", *hostQuantumFile)
+		if err := executeHostQuantumFile(*hostQuantumFile, *numQubits, *xlen); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		logf("Quantum RISC-V program executed successfully using host-native execution\n")
+		os.Exit(0)
+	}
+
+	if *compareWith != "" {
+		if *quantumFile == "" {
+			fmt.Println("Error: -compare-with needs the program to check in -quantum")
+			os.Exit(1)
+		}
+		match, err := compareQuantumFiles(*quantumFile, *compareWith, *numQubits, *xlen)
+		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Quantum RISC-V program executed successfully using host-native execution")
+		if !match {
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
 	if *quantumFile != "" {
-		fmt.Printf("Executing quantum RISC-V file in VM mode: %s\n", *quantumFile)
-		machine := quantum.NewQuantumRISCVMachine(*numQubits)
+		logf("Executing quantum RISC-V file in VM mode: %s\n", *quantumFile)
+		machine, err := newVMMachine(*numQubits, *xlen)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Load and execute the program
 		if err := machine.LoadRISCProgram(*quantumFile); err != nil {
 			fmt.Printf("Error loading quantum RISC-V program: %v\n", err)
 			os.Exit(1)
 		}
+		warnUnknownInstructions(machine.GetRISCProgram())
+		warnIgnoredDirectives(machine.IgnoredDirectives())
 
 		// Print initial state
-		fmt.Printf("\nInitial register state:\n")
+		logf("\nInitial register state:\n")
 		printRegisters(machine.GetRegisters())
 
 		// Execute the program
@@ -50,11 +156,21 @@ func main() {
 			os.Exit(1)
 		}
 
+		reportSkipped(machine.SkippedInstructions())
+
 		// Print final state
-		fmt.Printf("\nFinal register state:\n")
+		logf("\nFinal register state:\n")
 		printRegisters(machine.GetRegisters())
 
-		fmt.Println("\nQuantum RISC-V program executed successfully")
+		logf("\nQuantum RISC-V program executed successfully\n")
+		os.Exit(0)
+	}
+
+	if *scriptFile != "" {
+		if err := replInstance.RunScript(*scriptFile, *continueOnError); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
@@ -62,216 +178,210 @@ func main() {
 	replInstance.Start()
 }
 
+// newVMMachine creates a VM machine configured from the command-line flags for a batch run
+func newVMMachine(numQubits, xlen int) (*quantum.QuantumRISCVMachine, error) {
+	machine := quantum.NewQuantumRISCVMachine(numQubits)
+	if showMeasurements {
+		machine.SetMeasurementObserver(measurementPrinter("q"))
+	}
+	if err := machine.SetXLEN(xlen); err != nil {
+		return nil, err
+	}
+	if err := machine.SetRNGAlgorithm(rngAlgorithm); err != nil {
+		return nil, err
+	}
+	if seedSet {
+		machine.SetSeed(seed)
+	}
+	if err := machine.SetStackTop(stackTop); err != nil {
+		return nil, err
+	}
+	machine.SetSkipUnknown(skipUnknown)
+	machine.SetEntry(entryLabel)
+	machine.SetLoopDetection(detectLoops)
+	machine.GetState().SetPolarPhases(polarPhases)
+	if err := machine.GetState().RenormalizeEvery(renormalizeEvery); err != nil {
+		return nil, err
+	}
+	if err := machine.GetState().SetBackend(stateBackend); err != nil {
+		return nil, err
+	}
+	if err := machine.SetNoise(noise); err != nil {
+		return nil, err
+	}
+	machine.SetFreshRun(true) // Batch runs start from |0...0⟩
+	return machine, nil
+}
+
+// newHostMachine creates a host machine configured from the command-line flags
+func newHostMachine(numQubits, xlen int) (*quantum.HostQuantumMachine, error) {
+	hostMachine := quantum.NewHostQuantumMachine(numQubits)
+	if showMeasurements {
+		hostMachine.SetMeasurementObserver(measurementPrinter("x"))
+	}
+	if err := hostMachine.SetXLEN(xlen); err != nil {
+		return nil, err
+	}
+	if err := hostMachine.SetRNGAlgorithm(rngAlgorithm); err != nil {
+		return nil, err
+	}
+	if seedSet {
+		hostMachine.SetSeed(seed)
+	}
+	if err := hostMachine.SetStackTop(stackTop); err != nil {
+		return nil, err
+	}
+	hostMachine.SetLoopDetection(detectLoops)
+	return hostMachine, nil
+}
+
+// configureREPL gives the REPL (interactive or -script) machines configured from the same flags
+// as the file-execution modes
+func configureREPL(replInstance *repl.REPL, numQubits, xlen int) error {
+	machine, err := newVMMachine(numQubits, xlen)
+	if err != nil {
+		return err
+	}
+	hostMachine, err := newHostMachine(numQubits, xlen)
+	if err != nil {
+		return err
+	}
+	replInstance.SetMachines(machine, hostMachine) // Keeps the REPL's run mode rather than fresh runs
+	return nil
+}
+
+// runVMFile loads and runs a program file on a fresh flag-configured VM machine seeded with seed
+func runVMFile(filename string, numQubits, xlen int, seed int64) (*quantum.QuantumRISCVMachine, error) {
+	machine, err := newVMMachine(numQubits, xlen)
+	if err != nil {
+		return nil, err
+	}
+	machine.SetSeed(seed)
+	if err := machine.LoadRISCProgram(filename); err != nil {
+		return nil, fmt.Errorf("error loading %s: %v", filename, err)
+	}
+	warnUnknownInstructions(machine.GetRISCProgram())
+	warnIgnoredDirectives(machine.IgnoredDirectives())
+	if err := machine.ExecuteRISCProgram(); err != nil {
+		return nil, fmt.Errorf("error executing %s: %v", filename, err)
+	}
+	return machine, nil
+}
+
+// compareQuantumFiles runs a program and a reference program on identically configured (and
+// identically seeded) machines and reports the first register or measurement probability where
+// their final states differ. It returns whether they match.
+func compareQuantumFiles(program, reference string, numQubits, xlen int) (bool, error) {
+	logf("Comparing %s against reference %s\n", program, reference)
+	shared := time.Now().UnixNano() // Shared, so both programs see the same measurement outcomes
+	if seedSet {
+		shared = seed
+	}
+	a, err := runVMFile(program, numQubits, xlen, shared)
+	if err != nil {
+		return false, err
+	}
+	b, err := runVMFile(reference, numQubits, xlen, shared)
+	if err != nil {
+		return false, err
+	}
+	comparison, err := quantum.CompareMachines(a, b)
+	if err != nil {
+		return false, err
+	}
+
+	if comparison.Register >= 0 {
+		fmt.Printf("Mismatch: register x%d is %d in %s but %d in %s\n", comparison.Register,
+			comparison.Values[0], program, comparison.Values[1], reference)
+	}
+	if comparison.Diverged {
+		fmt.Printf("Mismatch: measurement distributions diverge at |%s⟩: p=%.9f in %s but p=%.9f in %s\n",
+			comparison.BasisState.Bits(a.GetState().NumQubits()), comparison.Probabilities[0], program,
+			comparison.Probabilities[1], reference)
+	}
+	if comparison.Match() {
+		fmt.Println("Programs match: final registers and measurement distributions are identical")
+	}
+	return comparison.Match(), nil
+}
+
 // executeHostQuantumFile executes a quantum RISC-V file using host-native execution
-func executeHostQuantumFile(filename string, numQubits int) error {
+func executeHostQuantumFile(filename string, numQubits, xlen int) error {
 	// Create a VM just to parse the program
 	machine := quantum.NewQuantumRISCVMachine(numQubits)
+	if err := machine.SetXLEN(xlen); err != nil {
+		return err
+	}
+	machine.SetSkipUnknown(skipUnknown)
+	machine.SetEntry(entryLabel)
 	if err := machine.LoadRISCProgram(filename); err != nil {
 		return fmt.Errorf("error loading quantum RISC-V program: %v", err)
 	}
+	warnUnknownInstructions(machine.GetRISCProgram())
+	warnIgnoredDirectives(machine.IgnoredDirectives())
 
 	// Create host machine for native execution
-	hostMachine := quantum.NewHostQuantumMachine(numQubits)
+	hostMachine, err := newHostMachine(numQubits, xlen)
+	if err != nil {
+		return err
+	}
+	if err := hostMachine.LoadData(machine.DataSegment()); err != nil {
+		return err
+	}
+
+	// Run the parsed program with host-native execution
+	hostMachine.LoadProgram(machine.GetRISCProgram(), machine.EntryPC())
+	hostMachine.SetFreshRun(true) // Batch runs start from |0...0⟩
+	defer func() { reportSkipped(hostMachine.SkippedInstructions()) }()
+	return hostMachine.ExecuteProgram()
+}
 
-	// Program counter for control flow
-	pc := uint32(0)
-	program := machine.GetRISCProgram()
-
-	// Execute instructions until we reach the end of the program
-	for pc < uint32(len(program)) {
-		inst := program[pc]
-
-		if isQuantumInstruction(inst.Opcode) {
-			// Execute quantum instructions using host-native execution
-			if err := hostMachine.ExecuteQuantumRISCV(inst); err != nil {
-				return fmt.Errorf("error executing quantum instruction on host at PC %d: %v", pc, err)
-			}
-			pc++
-		} else {
-			// Execute classical RISC-V instructions
-			switch inst.Opcode {
-			case "add", "sub", "and", "or", "xor", "sll", "srl", "sra", "slt", "sltu":
-				// R-type instructions
-				rs1 := hostMachine.GetRegister(inst.Rs1)
-				rs2 := hostMachine.GetRegister(inst.Rs2)
-				var result uint64
-				switch inst.Opcode {
-				case "add":
-					result = rs1 + rs2
-				case "sub":
-					result = rs1 - rs2
-				case "and":
-					result = rs1 & rs2
-				case "or":
-					result = rs1 | rs2
-				case "xor":
-					result = rs1 ^ rs2
-				case "sll":
-					result = rs1 << rs2
-				case "srl":
-					result = rs1 >> rs2
-				case "sra":
-					result = uint64(int64(rs1) >> rs2)
-				case "slt":
-					if int64(rs1) < int64(rs2) {
-						result = 1
-					}
-				case "sltu":
-					if rs1 < rs2 {
-						result = 1
-					}
-				}
-				hostMachine.SetRegister(inst.Rd, result)
-				pc++
-
-			case "addi", "slli", "srli", "srai", "andi", "ori", "xori", "slti", "sltiu":
-				// I-type instructions
-				rs1 := hostMachine.GetRegister(inst.Rs1)
-				var result uint64
-				switch inst.Opcode {
-				case "addi":
-					result = rs1 + uint64(inst.Imm)
-				case "slli":
-					result = rs1 << uint64(inst.Imm)
-				case "srli":
-					result = rs1 >> uint64(inst.Imm)
-				case "srai":
-					result = uint64(int64(rs1) >> uint64(inst.Imm))
-				case "andi":
-					result = rs1 & uint64(inst.Imm)
-				case "ori":
-					result = rs1 | uint64(inst.Imm)
-				case "xori":
-					result = rs1 ^ uint64(inst.Imm)
-				case "slti":
-					if int64(rs1) < inst.Imm {
-						result = 1
-					}
-				case "sltiu":
-					if rs1 < uint64(inst.Imm) {
-						result = 1
-					}
-				}
-				hostMachine.SetRegister(inst.Rd, result)
-				pc++
-
-			case "lui", "auipc":
-				// U-type instructions
-				switch inst.Opcode {
-				case "lui":
-					hostMachine.SetRegister(inst.Rd, uint64(inst.Imm<<12))
-				case "auipc":
-					hostMachine.SetRegister(inst.Rd, uint64(pc)+uint64(inst.Imm<<12))
-				}
-				pc++
-
-			case "jal":
-				// J-type instruction
-				hostMachine.SetRegister(inst.Rd, uint64(pc+1))
-				pc = uint32(int64(pc) + inst.Offset)
-
-			case "jalr":
-				// I-type jump instruction
-				nextPc := uint32(int64(hostMachine.GetRegister(inst.Rs1)) + inst.Offset)
-				hostMachine.SetRegister(inst.Rd, uint64(pc+1))
-				pc = nextPc
-
-			case "beq", "bne", "blt", "bge", "bltu", "bgeu":
-				// B-type instructions
-				rs1 := hostMachine.GetRegister(inst.Rs1)
-				rs2 := hostMachine.GetRegister(inst.Rs2)
-				var taken bool
-				switch inst.Opcode {
-				case "beq":
-					taken = rs1 == rs2
-				case "bne":
-					taken = rs1 != rs2
-				case "blt":
-					taken = int64(rs1) < int64(rs2)
-				case "bge":
-					taken = int64(rs1) >= int64(rs2)
-				case "bltu":
-					taken = rs1 < rs2
-				case "bgeu":
-					taken = rs1 >= rs2
-				}
-				if taken {
-					pc = uint32(int64(pc) + inst.Offset)
-				} else {
-					pc++
-				}
-
-			case "lw", "lh", "lb", "lwu", "lhu", "lbu":
-				// Load instructions
-				addr := uint32(int64(hostMachine.GetRegister(inst.Rs1)) + inst.Offset)
-				var size uint8
-				var signExtend bool
-				switch inst.Opcode {
-				case "lw":
-					size = 4
-					signExtend = true
-				case "lh":
-					size = 2
-					signExtend = true
-				case "lb":
-					size = 1
-					signExtend = true
-				case "lwu":
-					size = 4
-					signExtend = false
-				case "lhu":
-					size = 2
-					signExtend = false
-				case "lbu":
-					size = 1
-					signExtend = false
-				}
-				val, err := hostMachine.LoadMemory(addr, size)
-				if err != nil {
-					return fmt.Errorf("error at PC %d: %v", pc, err)
-				}
-				if signExtend {
-					switch size {
-					case 1:
-						val = uint64(int8(val))
-					case 2:
-						val = uint64(int16(val))
-					case 4:
-						val = uint64(int32(val))
-					}
-				}
-				hostMachine.SetRegister(inst.Rd, val)
-				pc++
-
-			case "sw", "sh", "sb":
-				// Store instructions
-				addr := uint32(int64(hostMachine.GetRegister(inst.Rs1)) + inst.Offset)
-				val := hostMachine.GetRegister(inst.Rs2)
-				var size uint8
-				switch inst.Opcode {
-				case "sw":
-					size = 4
-				case "sh":
-					size = 2
-				case "sb":
-					size = 1
-				}
-				if err := hostMachine.StoreMemory(addr, val, size); err != nil {
-					return fmt.Errorf("error at PC %d: %v", pc, err)
-				}
-				pc++
-
-			default:
-				return fmt.Errorf("unknown instruction type at PC %d: %s", pc, inst.Opcode)
-			}
+// warnUnknownInstructions warns about each unknown instruction that -skip-unknown let through
+func warnUnknownInstructions(program []quantum.RISCInstruction) {
+	for pc, inst := range program {
+		if !quantum.IsKnownInstruction(inst.Opcode) {
+			fmt.Printf("Warning: unknown instruction '%s' at %s will be skipped\n", inst.Opcode, inst.Position(uint32(pc)))
 		}
 	}
+}
 
-	return nil
+// warnIgnoredDirectives warns about each skipped directive when -warn-directives is set
+func warnIgnoredDirectives(ignored []quantum.IgnoredDirective) {
+	if !warnDirectives {
+		return
+	}
+	for _, d := range ignored {
+		fmt.Printf("Warning: line %d: directive %s is not implemented and was ignored\n", d.Line, d.Directive)
+	}
+}
+
+// reportSkipped reports how many unknown instructions a run skipped
+func reportSkipped(count int) {
+	if count > 0 {
+		fmt.Printf("Skipped %d unknown instruction(s)\n", count)
+	}
 }
 
-// printRegisters prints the current state of the registers
+// logf prints non-essential progress output unless -quiet is set
+func logf(format string, args ...interface{}) {
+	if !quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// measurementPrinter returns an observer reporting each measurement event; prefix names what
+// was measured ("x" for host quantum registers, "q" for VM qubits)
+func measurementPrinter(prefix string) quantum.MeasurementObserver {
+	return func(qubit int, outcome uint64, probability float64) {
+		fmt.Printf("Measured %s%d -> %d (p=%.6f)\n", prefix, qubit, outcome, probability)
+	}
+}
+
+// printRegisters prints the current state of the registers (suppressed by -quiet)
 func printRegisters(registers [128]uint64) {
+	if quiet {
+		return
+	}
 	for i, reg := range registers {
 		if reg != 0 { // Only print non-zero registers to reduce noise
 			fmt.Printf("  x%d: %d\n", i, reg)
@@ -282,7 +392,7 @@ func printRegisters(registers [128]uint64) {
 // isQuantumInstruction checks if an instruction is a quantum instruction
 func isQuantumInstruction(opcode string) bool {
 	switch opcode {
-	case "qinit", "qapply", "qmeasure", "qentangle":
+	case "qinit", "qapply", "qmeasure", "qentangle", "qmov":
 		return true
 	default:
 		return false
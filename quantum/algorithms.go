@@ -0,0 +1,103 @@
+package quantum
+
+import (
+	"fmt"
+	"math"
+)
+
+// qftSequence returns the gates of the quantum Fourier transform over qubits, where qubits[0]
+// is the least significant bit of the transformed register: for each qubit from the most
+// significant down, H followed by controlled phases P(π/2^d) from every less significant qubit
+// at distance d, then SWAPs that reverse the qubit order. The inverse transform applies the same
+// gates in reverse order with negated angles (H and SWAP are their own inverses).
+func qftSequence(qubits []int, inverse bool) []GateRecord {
+	n := len(qubits)
+	var sequence []GateRecord
+	for j := n - 1; j >= 0; j-- {
+		sequence = append(sequence, GateRecord{Name: "H", Target: qubits[j]})
+		for k := j - 1; k >= 0; k-- {
+			angle := math.Pi / float64(int(1)<<(j-k))
+			sequence = append(sequence, GateRecord{Name: "P", Target: qubits[j], Controls: []int{qubits[k]}, Angle: angle})
+		}
+	}
+	for i := 0; i < n/2; i++ {
+		sequence = append(sequence, GateRecord{Name: "SWAP", Target: qubits[i], Controls: []int{qubits[n-1-i]}})
+	}
+
+	if inverse {
+		for i, j := 0, len(sequence)-1; i < j; i, j = i+1, j-1 {
+			sequence[i], sequence[j] = sequence[j], sequence[i]
+		}
+		for i := range sequence {
+			sequence[i].Angle = -sequence[i].Angle
+		}
+	}
+	return sequence
+}
+
+// applyRecordedGate applies one H, P or SWAP gate of a generated sequence to state
+func applyRecordedGate(state *QuantumState, r GateRecord) {
+	switch r.Name {
+	case "H":
+		H.Apply(state, r.Target, r.Controls)
+	case "P":
+		Phase(r.Angle).Apply(state, r.Target, r.Controls)
+	case "SWAP":
+		swapQubits(state, r.Target, r.Controls[0])
+	}
+}
+
+// validateQFTQubits checks that qubits are distinct valid qubits of state
+func validateQFTQubits(state *QuantumState, qubits []int) error {
+	if len(qubits) == 0 {
+		return fmt.Errorf("QFT needs at least one qubit")
+	}
+	seen := make(map[int]bool, len(qubits))
+	for _, q := range qubits {
+		if err := state.checkQubit(q); err != nil {
+			return err
+		}
+		if seen[q] {
+			return fmt.Errorf("qubit %d used more than once", q)
+		}
+		seen[q] = true
+	}
+	return nil
+}
+
+// QFT applies the quantum Fourier transform to qubits of state, with qubits[0] the least
+// significant bit: |x⟩ → Σ_y e^(2πixy/2^n) |y⟩ / √(2^n). On |0...0⟩ it gives the uniform
+// superposition.
+func QFT(state *QuantumState, qubits []int) error {
+	if err := validateQFTQubits(state, qubits); err != nil {
+		return err
+	}
+	for _, r := range qftSequence(qubits, false) {
+		applyRecordedGate(state, r)
+	}
+	return nil
+}
+
+// InverseQFT applies the inverse quantum Fourier transform, undoing QFT on the same qubits
+func InverseQFT(state *QuantumState, qubits []int) error {
+	if err := validateQFTQubits(state, qubits); err != nil {
+		return err
+	}
+	for _, r := range qftSequence(qubits, true) {
+		applyRecordedGate(state, r)
+	}
+	return nil
+}
+
+// ApplyQFT applies the quantum Fourier transform (or its inverse) to machine qubits and records
+// its H, controlled-phase and SWAP gates in the gate history
+func (m *QuantumRISCVMachine) ApplyQFT(qubits []int, inverse bool) error {
+	if err := validateQFTQubits(m.state, qubits); err != nil {
+		return err
+	}
+	for _, r := range qftSequence(qubits, inverse) {
+		applyRecordedGate(m.state, r)
+		m.record(r)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestPhaseGate(t *testing.T) {
+	tests := []struct {
+		theta float64
+		same  *SingleQubitGate // Fixed gate that Phase(theta) equals, or nil
+	}{
+		{0, nil},
+		{math.Pi, Z},
+		{math.Pi / 2, S},
+		{math.Pi / 4, T},
+		{-1.3, nil},
+	}
+	for _, tt := range tests {
+		qs := NewQuantumState(1)
+		H.Apply(qs, 0, nil)
+		Phase(tt.theta).Apply(qs, 0, nil)
+		s := complex(1/math.Sqrt2, 0)
+		if got := qs.GetAmplitude(0); !approxEqual(got, s) {
+			t.Errorf("P(%g): |0⟩ amplitude %v, want %v", tt.theta, got, s)
+		}
+		if got, want := qs.GetAmplitude(1), s*cmplx.Exp(complex(0, tt.theta)); !approxEqual(got, want) {
+			t.Errorf("P(%g): |1⟩ amplitude %v, want %v", tt.theta, got, want)
+		}
+		if tt.same == nil {
+			continue
+		}
+		for r := 0; r < 2; r++ {
+			for c := 0; c < 2; c++ {
+				if got, want := Phase(tt.theta).matrix[r][c], tt.same.matrix[r][c]; !approxEqual(got, want) {
+					t.Errorf("P(%g)[%d][%d] = %v, want %v", tt.theta, r, c, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestQFTOnBasisStates(t *testing.T) {
+	tests := []struct {
+		numQubits int
+		qubits    []int
+		input     int // Value of the transformed register, qubits[0] least significant
+	}{
+		{1, []int{0}, 0},
+		{1, []int{0}, 1},
+		{2, []int{0, 1}, 1},
+		{3, []int{0, 1, 2}, 0},
+		{3, []int{0, 1, 2}, 5},
+		{3, []int{2, 1, 0}, 3},
+		{4, []int{3, 0, 2}, 6},
+		{4, []int{1, 2, 3, 0}, 11},
+	}
+	for _, tt := range tests {
+		qs := NewQuantumState(tt.numQubits)
+		for j, q := range tt.qubits {
+			if tt.input>>j&1 == 1 {
+				X.Apply(qs, q, nil)
+			}
+		}
+		if err := QFT(qs, tt.qubits); err != nil {
+			t.Fatalf("QFT %v: %v", tt.qubits, err)
+		}
+		n := len(tt.qubits)
+		dim := 1 << n
+		scale := 1 / math.Sqrt(float64(dim))
+		// |x⟩ → Σ_y e^(2πixy/2^n)|y⟩/√(2^n), with untouched qubits left at 0
+		for y := 0; y < dim; y++ {
+			index := 0
+			for j, q := range tt.qubits {
+				index |= (y >> j & 1) << q
+			}
+			angle := 2 * math.Pi * float64(tt.input*y) / float64(dim)
+			want := complex(scale, 0) * cmplx.Exp(complex(0, angle))
+			if got := qs.GetAmplitude(index); !approxEqual(got, want) {
+				t.Errorf("QFT %v of %d: amplitude of y=%d is %v, want %v", tt.qubits, tt.input, y, got, want)
+			}
+		}
+		if norm := qs.Norm(); math.Abs(norm-1) > 1e-9 {
+			t.Errorf("QFT %v of %d: norm %g", tt.qubits, tt.input, norm)
+		}
+	}
+}
+
+func TestInverseQFTUndoesQFT(t *testing.T) {
+	for _, qubits := range [][]int{{0}, {0, 1}, {2, 0, 1}, {1, 3}, {0, 1, 2, 3}} {
+		qs := NewQuantumState(4)
+		for q := 0; q < 4; q++ {
+			RY(0.4+0.3*float64(q)).Apply(qs, q, nil)
+			T.Apply(qs, q, nil)
+		}
+		before := qs.GetAmplitudes()
+		if err := QFT(qs, qubits); err != nil {
+			t.Fatal(err)
+		}
+		if err := InverseQFT(qs, qubits); err != nil {
+			t.Fatal(err)
+		}
+		for i, amp := range qs.GetAmplitudes() {
+			if !approxEqual(amp, before[i]) {
+				t.Errorf("qubits %v: amplitude %d = %v after QFT and inverse, want %v", qubits, i, amp, before[i])
+			}
+		}
+	}
+}
+
+func TestQFTErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		qubits []int
+	}{
+		{"no qubits", nil},
+		{"out of range", []int{0, 3}},
+		{"negative", []int{-1}},
+		{"repeated", []int{0, 1, 0}},
+	}
+	for _, tt := range tests {
+		if err := QFT(NewQuantumState(3), tt.qubits); err == nil {
+			t.Errorf("QFT %s: succeeded", tt.name)
+		}
+		if err := InverseQFT(NewQuantumState(3), tt.qubits); err == nil {
+			t.Errorf("inverse QFT %s: succeeded", tt.name)
+		}
+		m := NewQuantumRISCVMachine(3)
+		if err := m.ApplyQFT(tt.qubits, false); err == nil {
+			t.Errorf("ApplyQFT %s: succeeded", tt.name)
+		}
+		if got := len(m.GetGateHistory()); got != 0 {
+			t.Errorf("ApplyQFT %s: recorded %d gates", tt.name, got)
+		}
+	}
+}
+
+func TestApplyQFTRecordsGates(t *testing.T) {
+	tests := []struct {
+		qubits               []int
+		hadamards, cp, swaps int
+	}{
+		{[]int{0}, 1, 0, 0},
+		{[]int{0, 1}, 2, 1, 1},
+		{[]int{0, 1, 2}, 3, 3, 1},
+		{[]int{3, 1, 0, 2}, 4, 6, 2},
+	}
+	for _, tt := range tests {
+		for _, inverse := range []bool{false, true} {
+			m := NewQuantumRISCVMachine(4)
+			if err := m.ApplyQFT(tt.qubits, inverse); err != nil {
+				t.Fatal(err)
+			}
+			counts := make(map[string]int)
+			for _, r := range m.GetGateHistory() {
+				counts[r.Name]++
+				if r.Name == "P" && (inverse != (r.Angle < 0)) {
+					t.Errorf("qubits %v (inverse %v): phase angle %g has the wrong sign", tt.qubits, inverse, r.Angle)
+				}
+			}
+			if counts["H"] != tt.hadamards || counts["P"] != tt.cp || counts["SWAP"] != tt.swaps {
+				t.Errorf("qubits %v (inverse %v): recorded %v, want %d H, %d P, %d SWAP", tt.qubits, inverse, counts,
+					tt.hadamards, tt.cp, tt.swaps)
+			}
+
+			// The machine state matches the package-level transform
+			want := NewQuantumState(4)
+			apply := QFT
+			if inverse {
+				apply = InverseQFT
+			}
+			if err := apply(want, tt.qubits); err != nil {
+				t.Fatal(err)
+			}
+			got := m.GetState().GetAmplitudes()
+			for i, amp := range want.GetAmplitudes() {
+				if !approxEqual(got[i], amp) {
+					t.Errorf("qubits %v (inverse %v): amplitude %d = %v, want %v", tt.qubits, inverse, i, got[i], amp)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package quantum
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// DefaultXLEN is the register width used unless RV32 mode is selected
+const DefaultXLEN = 64
+
+// validateXLEN accepts the two supported register widths
+func validateXLEN(xlen int) error {
+	if xlen != 32 && xlen != 64 {
+		return fmt.Errorf("unsupported XLEN %d (expected 32 or 64)", xlen)
+	}
+	return nil
+}
+
+// maskXLEN truncates v to xlen bits, which is exactly RISC-V's two's-complement wrap-around
+func maskXLEN(v uint64, xlen int) uint64 {
+	if xlen >= 64 {
+		return v
+	}
+	return v & (1<<uint(xlen) - 1)
+}
+
+// signedXLEN interprets the low xlen bits of v as a two's-complement integer
+func signedXLEN(v uint64, xlen int) int64 {
+	if xlen >= 64 {
+		return int64(v)
+	}
+	return signExtend(int64(v), uint(xlen))
+}
+
+// ExecuteALU computes an integer R-type or I-type instruction, including the M extension's
+// multiply and divide (for I-type opcodes b is the immediate). Operands are reduced to XLEN bits
+// and the result is masked to XLEN, so add, sub and shifts wrap modulo 2^XLEN as RISC-V
+// specifies: RV64 matches Go's native uint64 wrap-around, while RV32 wraps at 32 bits. Shift
+// amounts use only the low log2(XLEN) bits.
+func ExecuteALU(opcode string, a, b uint64, xlen int) (uint64, error) {
+	a, b = maskXLEN(a, xlen), maskXLEN(b, xlen)
+	shamt := b & uint64(xlen-1)
+
+	var result uint64
+	switch opcode {
+	case "add", "addi":
+		result = a + b
+	case "sub":
+		result = a - b
+	case "and", "andi":
+		result = a & b
+	case "or", "ori":
+		result = a | b
+	case "xor", "xori":
+		result = a ^ b
+	case "sll", "slli":
+		result = a << shamt
+	case "srl", "srli":
+		result = a >> shamt
+	case "sra", "srai":
+		result = uint64(signedXLEN(a, xlen) >> shamt)
+	case "slt", "slti":
+		result = boolToRegister(signedXLEN(a, xlen) < signedXLEN(b, xlen))
+	case "sltu", "sltiu":
+		result = boolToRegister(a < b)
+	case "mul":
+		result = a * b
+	case "mulh", "mulhu", "mulhsu":
+		result = mulHigh(opcode, a, b, xlen)
+	case "div", "divu", "rem", "remu":
+		result = divide(opcode, a, b, xlen)
+	default:
+		return 0, fmt.Errorf("not an ALU instruction: %s", opcode)
+	}
+	return maskXLEN(result, xlen), nil
+}
+
+// boolToRegister converts a comparison into the 0/1 value set-less-than instructions write
+func boolToRegister(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// mulHigh returns the upper XLEN bits of the 2·XLEN-bit product of a and b, treating the operands
+// as signed (mulh), unsigned (mulhu), or signed rs1 times unsigned rs2 (mulhsu)
+func mulHigh(opcode string, a, b uint64, xlen int) uint64 {
+	if xlen < 64 {
+		// The full RV32 product fits in 64 bits, so compute it directly and keep the upper half
+		switch opcode {
+		case "mulh":
+			return uint64(signedXLEN(a, xlen)*signedXLEN(b, xlen)) >> uint(xlen)
+		case "mulhsu":
+			return uint64(signedXLEN(a, xlen)*int64(b)) >> uint(xlen)
+		}
+		return (a * b) >> uint(xlen)
+	}
+	hi, _ := bits.Mul64(a, b)
+	// A negative operand x is read as x + 2^64 by the unsigned product, which adds the other
+	// operand to the upper half; subtracting it back gives the signed upper half
+	if opcode != "mulhu" && int64(a) < 0 {
+		hi -= b
+	}
+	if opcode == "mulh" && int64(b) < 0 {
+		hi -= a
+	}
+	return hi
+}
+
+// divide implements div, divu, rem and remu with the results RISC-V specifies instead of a trap:
+// dividing by zero gives an all-ones quotient and returns the dividend as remainder, and the
+// signed overflow INT_MIN / -1 gives quotient INT_MIN and remainder 0. Signed division rounds
+// toward zero, so the remainder takes the sign of the dividend, as in Go.
+func divide(opcode string, a, b uint64, xlen int) uint64 {
+	signedA, signedB := signedXLEN(a, xlen), signedXLEN(b, xlen)
+	minInt := int64(-1) << uint(xlen-1)
+	switch opcode {
+	case "div":
+		switch {
+		case b == 0:
+			return ^uint64(0)
+		case signedA == minInt && signedB == -1:
+			return uint64(minInt)
+		}
+		return uint64(signedA / signedB)
+	case "divu":
+		if b == 0 {
+			return ^uint64(0)
+		}
+		return a / b
+	case "rem":
+		switch {
+		case b == 0:
+			return a
+		case signedA == minInt && signedB == -1:
+			return 0
+		}
+		return uint64(signedA % signedB)
+	default: // remu
+		if b == 0 {
+			return a
+		}
+		return a % b
+	}
+}
@@ -0,0 +1,136 @@
+package quantum
+
+import "testing"
+
+// aluCase is one ExecuteALU call and its expected result
+type aluCase struct {
+	opcode string
+	a, b   uint64
+	xlen   int
+	want   uint64
+}
+
+// checkALU runs each case through ExecuteALU
+func checkALU(t *testing.T, cases []aluCase) {
+	t.Helper()
+	for _, c := range cases {
+		got, err := ExecuteALU(c.opcode, c.a, c.b, c.xlen)
+		if err != nil {
+			t.Errorf("RV%d %s %#x, %#x: %v", c.xlen, c.opcode, c.a, c.b, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("RV%d %s %#x, %#x = %#x, want %#x", c.xlen, c.opcode, c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestALUWrapsModuloXLEN(t *testing.T) {
+	checkALU(t, []aluCase{
+		{"add", 0xFFFFFFFFFFFFFFFF, 1, 64, 0},
+		{"add", 0x7FFFFFFFFFFFFFFF, 1, 64, 0x8000000000000000},
+		{"sub", 0, 1, 64, 0xFFFFFFFFFFFFFFFF},
+		{"add", 0xFFFFFFFF, 1, 32, 0},
+		{"add", 0x7FFFFFFF, 1, 32, 0x80000000},
+		{"sub", 0, 1, 32, 0xFFFFFFFF},
+		{"addi", 5, 0xFFFFFFFFFFFFFFFD, 32, 2}, // Immediate -3, sign-extended past 32 bits
+		{"sll", 1, 31, 32, 0x80000000},
+		{"sll", 1, 32, 32, 1}, // RV32 shifts use the low 5 bits of the amount
+		{"sll", 1, 64, 64, 1},
+		{"sra", 0x80000000, 4, 32, 0xF8000000},
+		{"srl", 0x80000000, 4, 32, 0x08000000},
+		{"slt", 0xFFFFFFFF, 0, 32, 1}, // -1 < 0 when read as RV32
+		{"slt", 0xFFFFFFFF, 0, 64, 0},
+		{"sltu", 0xFFFFFFFF, 0, 32, 0},
+	})
+}
+
+func TestALUErrors(t *testing.T) {
+	if _, err := ExecuteALU("beq", 1, 2, 64); err == nil {
+		t.Error("ExecuteALU accepted a branch")
+	}
+	if err := validateXLEN(16); err == nil {
+		t.Error("validateXLEN accepted 16")
+	}
+}
+
+func TestRV32RegistersStayMasked(t *testing.T) {
+	source := "addi x5, x0, -1\naddi x6, x5, 2\nlui x7, 0x40000\nadd x8, x7, x7\nadd x9, x8, x8\n"
+	tests := []struct {
+		xlen int
+		want map[int]uint64
+	}{
+		{32, map[int]uint64{5: 0xFFFFFFFF, 6: 1, 8: 0x80000000, 9: 0}},
+		{64, map[int]uint64{5: 0xFFFFFFFFFFFFFFFF, 6: 1, 8: 0x80000000, 9: 0x100000000}},
+	}
+	for _, tt := range tests {
+		result, err := RunProgram(source, Options{XLEN: tt.xlen})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for reg, want := range tt.want {
+			if got := result.Registers[reg]; got != want {
+				t.Errorf("RV%d x%d = %#x, want %#x", tt.xlen, reg, got, want)
+			}
+		}
+	}
+}
+
+func TestMultiply(t *testing.T) {
+	const minus1, minus2 = 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFE
+	checkALU(t, []aluCase{
+		{"mul", 6, 7, 64, 42},
+		{"mul", minus1, 3, 64, 0xFFFFFFFFFFFFFFFD},
+		{"mul", 0x100000000, 0x100000000, 64, 0}, // Low 64 bits only
+		{"mulhu", 0x100000000, 0x100000000, 64, 1},
+		{"mulhu", minus1, minus1, 64, minus2},
+		{"mulh", minus1, minus1, 64, 0}, // (-1)·(-1) = 1
+		{"mulh", minus1, 2, 64, minus1}, // -2: upper half all ones
+		{"mulh", 0x8000000000000000, 0x8000000000000000, 64, 0x4000000000000000},
+		{"mulhsu", minus1, minus1, 64, minus1}, // -1 · (2^64 - 1)
+		{"mulhsu", 2, minus1, 64, 1},
+		{"mul", 0xFFFFFFFF, 0xFFFFFFFF, 32, 1},
+		{"mulhu", 0xFFFFFFFF, 0xFFFFFFFF, 32, 0xFFFFFFFE},
+		{"mulh", 0xFFFFFFFF, 0xFFFFFFFF, 32, 0},
+		{"mulh", 0x80000000, 0x80000000, 32, 0x40000000},
+		{"mulhsu", 0xFFFFFFFF, 0xFFFFFFFF, 32, 0xFFFFFFFF},
+		{"mulhsu", 2, 0xFFFFFFFF, 32, 1},
+	})
+}
+
+func TestDivide(t *testing.T) {
+	const minus1, minInt64 = 0xFFFFFFFFFFFFFFFF, 0x8000000000000000
+	checkALU(t, []aluCase{
+		{"div", 42, 5, 64, 8},
+		{"rem", 42, 5, 64, 2},
+		{"div", 0xFFFFFFFFFFFFFFD6, 5, 64, 0xFFFFFFFFFFFFFFF8}, // -42 / 5 = -8, rounding toward zero
+		{"rem", 0xFFFFFFFFFFFFFFD6, 5, 64, 0xFFFFFFFFFFFFFFFE}, // -42 % 5 = -2, the sign of the dividend
+		{"divu", minus1, 2, 64, 0x7FFFFFFFFFFFFFFF},
+		{"remu", minus1, 2, 64, 1},
+		{"div", 7, 0, 64, minus1}, // Division by zero
+		{"divu", 7, 0, 64, minus1},
+		{"rem", 7, 0, 64, 7},
+		{"remu", 7, 0, 64, 7},
+		{"div", minInt64, minus1, 64, minInt64}, // Signed overflow
+		{"rem", minInt64, minus1, 64, 0},
+		{"div", 0xFFFFFFD6, 5, 32, 0xFFFFFFF8},
+		{"divu", 0xFFFFFFFF, 2, 32, 0x7FFFFFFF},
+		{"div", 7, 0, 32, 0xFFFFFFFF},
+		{"div", 0x80000000, 0xFFFFFFFF, 32, 0x80000000},
+		{"rem", 0x80000000, 0xFFFFFFFF, 32, 0},
+	})
+}
+
+func TestMExtensionInPrograms(t *testing.T) {
+	source := "li a0, -42\nli a1, 5\nmul a2, a0, a1\ndiv a3, a0, a1\nrem a4, a0, a1\ndivu a5, a1, zero\n"
+	want := map[int]uint64{12: 0xFFFFFFFFFFFFFF2E, 13: 0xFFFFFFFFFFFFFFF8, 14: 0xFFFFFFFFFFFFFFFE, 15: 0xFFFFFFFFFFFFFFFF}
+	vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, source)
+	if vmErr != nil || hostErr != nil {
+		t.Fatalf("VM error %v, host error %v", vmErr, hostErr)
+	}
+	for reg, value := range want {
+		if vmRegs[reg] != value || hostRegs[reg] != value {
+			t.Errorf("x%d = %#x (VM), %#x (host), want %#x", reg, vmRegs[reg], hostRegs[reg], value)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package quantum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// labelDef records where a label was defined
+type labelDef struct {
+	index int  // Instruction index the label points at (byte offset for data labels)
+	line  int  // 1-based source line of the definition
+	data  bool // Defined in the .data section
+}
+
+// assembly is the output of both assembler passes
+type assembly struct {
+	program []RISCInstruction
+	lines   []sourceLine        // Comment-free, label-free instruction lines from the first pass
+	labels  map[string]labelDef // Label -> definition
+	globals map[string]int      // Symbol declared with .globl -> line of the directive
+	data    []byte              // Contents of the .data section, loaded at DataBase
+	inData  bool                // The first pass is currently in the .data section
+	ignored []IgnoredDirective  // Directives the assembler does not implement, skipped
+}
+
+// IgnoredDirective is a directive a loaded program used that the assembler skipped because it
+// has no effect here, such as .file, .type, .size or .cfi_startproc in compiler output
+type IgnoredDirective struct {
+	Line      int
+	Directive string // Directive name, e.g. ".cfi_def_cfa_offset"
+}
+
+// assembleOptions controls how a program is assembled
+type assembleOptions struct {
+	xlen        int  // Register width used to materialize pseudo-instruction constants
+	skipUnknown bool // Emit unknown mnemonics as operand-less placeholders instead of failing
+}
+
+// sourceLine is one instruction of the program with its origin. Pseudo-instructions expand into
+// several sourceLines that share the same source text.
+type sourceLine struct {
+	text   string // Instruction to parse (after pseudo-instruction expansion)
+	source string // Comment-free, label-free text as written in the file
+	line   int    // 1-based line number
+}
+
+// assembleProgram parses program source in two passes: the first records label definitions
+// (rejecting duplicates), the second parses instructions and resolves label operands
+func assembleProgram(content string, opts assembleOptions) (*assembly, error) {
+	asm, err := collectLabels(content, opts.xlen)
+	if err != nil {
+		return nil, err
+	}
+	if err := asm.checkGlobals(); err != nil {
+		return nil, err
+	}
+
+	program := make([]RISCInstruction, 0, len(asm.lines))
+	for pc, src := range asm.lines {
+		if mnemonic := instructionFields(src.text)[0]; opts.skipUnknown && !IsKnownInstruction(mnemonic) {
+			program = append(program, RISCInstruction{Opcode: mnemonic, Source: src.source, LineNumber: src.line})
+			continue
+		}
+		text, err := resolveLabelOperand(src.text, pc, asm.labels)
+		if err == nil {
+			text, err = asm.resolveRelocations(text, pc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", src.line, err)
+		}
+		inst, err := parseRISCInstruction(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: error parsing instruction '%s': %v", src.line, src.source, err)
+		}
+		inst.Source, inst.LineNumber = src.source, src.line
+		program = append(program, inst)
+	}
+	asm.program = program
+	return asm, nil
+}
+
+// checkGlobals rejects .globl declarations of symbols that are never defined
+func (asm *assembly) checkGlobals() error {
+	for symbol, line := range asm.globals {
+		if _, ok := asm.labels[symbol]; !ok {
+			return fmt.Errorf("line %d: global symbol '%s' is not defined", line, symbol)
+		}
+	}
+	return nil
+}
+
+// entryPoint returns the instruction index of label, the program's entry point
+func (asm *assembly) entryPoint(label string) (uint32, error) {
+	def, ok := asm.labels[label]
+	if !ok || def.data {
+		return 0, fmt.Errorf("entry label '%s' is not defined in the .text section", label)
+	}
+	return uint32(def.index), nil
+}
+
+// normalizeLineEndings converts Windows (CRLF) and classic Mac (CR) line endings to LF
+func normalizeLineEndings(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(content, "\r", "\n")
+}
+
+// collectLabels is the first assembler pass: it strips comments and labels from every line,
+// records which instruction index each label points at, and handles directives
+func collectLabels(content string, xlen int) (*assembly, error) {
+	asm := &assembly{labels: make(map[string]labelDef), globals: make(map[string]int)}
+
+	for i, raw := range strings.Split(normalizeLineEndings(content), "\n") {
+		text := stripComment(raw)
+		// A line may carry several labels, optionally followed by an instruction
+		for {
+			label, rest, ok := splitLabel(text)
+			if !ok {
+				break
+			}
+			if prev, exists := asm.labels[label]; exists {
+				return nil, fmt.Errorf("duplicate label '%s' on line %d (first defined on line %d)",
+					label, i+1, prev.line)
+			}
+			if asm.inData {
+				asm.labels[label] = labelDef{index: len(asm.data), line: i + 1, data: true}
+			} else {
+				asm.labels[label] = labelDef{index: len(asm.lines), line: i + 1}
+			}
+			text = rest
+		}
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, ".") {
+			if err := asm.directive(text, i+1); err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			continue
+		}
+		if asm.inData {
+			return nil, fmt.Errorf("line %d: instruction in the .data section (switch back with .text)", i+1)
+		}
+		// Pseudo-instructions expand here so labels account for every emitted instruction
+		expanded, err := expandPseudo(text, xlen)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		for _, inst := range expanded {
+			asm.lines = append(asm.lines, sourceLine{text: inst, source: text, line: i + 1})
+		}
+	}
+	return asm, nil
+}
+
+// directive handles an assembler directive line such as ".globl _start"
+func (asm *assembly) directive(text string, line int) error {
+	fields := splitOperands(text, func(c rune) bool { return c == ',' || unicode.IsSpace(c) })
+	switch fields[0] {
+	case ".data", ".text":
+		asm.inData = fields[0] == ".data"
+		return nil
+	case ".section":
+		return asm.sectionDirective(fields, line)
+	case ".byte", ".half", ".word", ".dword":
+		return asm.dataDirective(fields)
+	case ".skip", ".space":
+		return asm.reserveDirective(fields)
+	case ".globl", ".global":
+		if len(fields) < 2 {
+			return fmt.Errorf("%s needs at least one symbol", fields[0])
+		}
+		for _, symbol := range fields[1:] {
+			if !isLabelName(symbol) {
+				return fmt.Errorf("invalid symbol name '%s'", symbol)
+			}
+			asm.globals[symbol] = line
+		}
+		return nil
+	default:
+		// Compiler output is full of directives (.file, .type, .size, .cfi_*, .ident, ...) that
+		// only matter to a linker or debugger; skip them so such files load as-is
+		asm.ignored = append(asm.ignored, IgnoredDirective{Line: line, Directive: fields[0]})
+		return nil
+	}
+}
+
+// sectionDirective handles ".section name[, flags]": code sections (.text*) switch to the text
+// section and data sections (.data*, .rodata*, .sdata*, .bss*) to the data section. Other sections
+// (debug information, notes) are ignored like unimplemented directives.
+func (asm *assembly) sectionDirective(fields []string, line int) error {
+	if len(fields) < 2 {
+		return fmt.Errorf(".section needs a section name")
+	}
+	switch name := fields[1]; {
+	case strings.HasPrefix(name, ".text"):
+		asm.inData = false
+	case strings.HasPrefix(name, ".data"), strings.HasPrefix(name, ".rodata"),
+		strings.HasPrefix(name, ".sdata"), strings.HasPrefix(name, ".bss"):
+		asm.inData = true
+	default:
+		asm.ignored = append(asm.ignored, IgnoredDirective{Line: line, Directive: ".section " + name})
+	}
+	return nil
+}
+
+// stripComment removes a trailing '#' comment and surrounding whitespace
+func stripComment(line string) string {
+	scanQuoted(line, func(i int) bool {
+		if line[i] != '#' {
+			return true
+		}
+		line = line[:i] // A '#' character literal is not a comment
+		return false
+	})
+	return strings.TrimSpace(line)
+}
+
+// splitLabel splits a leading "name:" label off a line
+func splitLabel(text string) (string, string, bool) {
+	colon := strings.Index(text, ":")
+	if colon <= 0 {
+		return "", text, false
+	}
+	name := strings.TrimSpace(text[:colon])
+	if !isLabelName(name) {
+		return "", text, false
+	}
+	return name, strings.TrimSpace(text[colon+1:]), true
+}
+
+// isLabelName reports whether s is a valid label identifier ([A-Za-z_.][A-Za-z0-9_.$]*)
+func isLabelName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		letter := c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		digit := c == '$' || (c >= '0' && c <= '9')
+		if !letter && (i == 0 || !digit) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveLabelOperand replaces a label used as a branch or jal target with its
+// instruction-relative offset from pc
+func resolveLabelOperand(text string, pc int, labels map[string]labelDef) (string, error) {
+	fields := instructionFields(text)
+	switch fields[0] {
+	case "jal", "beq", "bne", "blt", "bge", "bltu", "bgeu":
+	default:
+		return text, nil
+	}
+
+	last := len(fields) - 1
+	if last == 0 {
+		return text, nil // Let the parser report the missing operands
+	}
+	target := strings.TrimRight(fields[last], ",")
+	if _, _, err := parseIntLiteral(target); err == nil {
+		return text, nil // Already a numeric offset
+	}
+	def, ok := labels[target]
+	if !ok {
+		return "", fmt.Errorf("undefined label '%s'", target)
+	}
+	if def.data {
+		return "", fmt.Errorf("'%s' is a data label, not a branch target", target)
+	}
+	fields[last] = strconv.Itoa(def.index - pc)
+	return strings.Join(fields, " "), nil
+}
@@ -0,0 +1,211 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// runSource runs source on a fresh default-sized machine of the given XLEN and returns its registers
+func runSource(t *testing.T, source string, xlen int) [NumRegisters]uint64 {
+	t.Helper()
+	result, err := RunProgram(source, Options{XLEN: xlen})
+	if err != nil {
+		t.Fatalf("running %q: %v", source, err)
+	}
+	return result.Registers
+}
+
+func TestLoadImmediate(t *testing.T) {
+	tests := []struct {
+		xlen     int
+		imm      string
+		want     uint64
+		maxInsts int // Longest acceptable expansion
+	}{
+		{64, "0", 0, 1},
+		{64, "2047", 2047, 1},
+		{64, "-2048", 0xFFFFFFFFFFFFF800, 1},
+		{64, "0x12345", 0x12345, 2},
+		{64, "0x7FFFFFFF", 0x7FFFFFFF, 3},
+		{64, "0x123456789ABCDEF0", 0x123456789ABCDEF0, 8},
+		{64, "0xFFFFFFFFFFFFFFFF", 0xFFFFFFFFFFFFFFFF, 1},
+		{64, "-9223372036854775808", 1 << 63, 8},
+		{64, "'A'", 65, 1},
+		{32, "0xFFFFFFFF", 0xFFFFFFFF, 2},
+		{32, "-1", 0xFFFFFFFF, 1},
+		{32, "0x80000000", 0x80000000, 2},
+	}
+	for _, tt := range tests {
+		source := fmt.Sprintf("li x5, %s\n", tt.imm)
+		if got := runSource(t, source, tt.xlen)[5]; got != tt.want {
+			t.Errorf("RV%d li %s: x5 = %#x, want %#x", tt.xlen, tt.imm, got, tt.want)
+		}
+		expansion, err := expandPseudo("li x5, "+tt.imm, tt.xlen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expansion) > tt.maxInsts {
+			t.Errorf("RV%d li %s expands to %d instructions %v, want at most %d", tt.xlen, tt.imm,
+				len(expansion), expansion, tt.maxInsts)
+		}
+	}
+}
+
+func TestLoadImmediateErrors(t *testing.T) {
+	tests := []struct {
+		xlen int
+		line string
+	}{
+		{32, "li x5, 0x100000000"},
+		{64, "li x5, 0x1FFFFFFFFFFFFFFFF"},
+		{64, "li x5"},
+		{64, "li x200, 1"},
+		{64, "li x5, banana"},
+	}
+	for _, tt := range tests {
+		if _, err := RunProgram(tt.line+"\n", Options{XLEN: tt.xlen}); err == nil {
+			t.Errorf("RV%d %q assembled, want an error", tt.xlen, tt.line)
+		}
+	}
+}
+
+func TestLineEndings(t *testing.T) {
+	const program = "start:\n  addi x5, x0, 3 # three\n  addi x6, x5, 4\n  beq x0, x0, done\n  addi x6, x0, 99\ndone:\n"
+	tests := []struct {
+		name    string
+		newline string
+	}{
+		{"LF", "\n"},
+		{"CRLF", "\r\n"},
+		{"CR", "\r"},
+	}
+	for _, tt := range tests {
+		source := strings.ReplaceAll(program, "\n", tt.newline)
+		if got := normalizeLineEndings(source); got != program {
+			t.Errorf("%s: normalizeLineEndings = %q, want %q", tt.name, got, program)
+		}
+		regs := runSource(t, source, DefaultXLEN)
+		if regs[5] != 3 || regs[6] != 7 {
+			t.Errorf("%s: x5 = %d, x6 = %d, want 3 and 7", tt.name, regs[5], regs[6])
+		}
+	}
+}
+
+func TestEntryAndGlobals(t *testing.T) {
+	const source = `.globl _start
+.global helper
+helper:
+  addi x6, x0, 1
+_start:
+  addi x5, x0, 7
+`
+	tests := []struct {
+		entry       string
+		wantPC      uint32
+		wantX6      uint64
+		wantErr     bool
+		wantGlobals []string
+	}{
+		{"", 0, 1, false, []string{"_start", "helper"}},
+		{"_start", 1, 0, false, []string{"_start", "helper"}},
+		{"helper", 0, 1, false, []string{"_start", "helper"}},
+		{"missing", 0, 0, true, nil},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(1)
+		m.SetEntry(tt.entry)
+		err := m.LoadRISCSource(source)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("entry %q: error %v, want error %v", tt.entry, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if m.EntryPC() != tt.wantPC {
+			t.Errorf("entry %q: EntryPC = %d, want %d", tt.entry, m.EntryPC(), tt.wantPC)
+		}
+		if fmt.Sprint(m.GetGlobalSymbols()) != fmt.Sprint(tt.wantGlobals) {
+			t.Errorf("entry %q: globals %v, want %v", tt.entry, m.GetGlobalSymbols(), tt.wantGlobals)
+		}
+		if err := m.ExecuteRISCProgram(); err != nil {
+			t.Fatal(err)
+		}
+		if regs := m.GetRegisters(); regs[5] != 7 || regs[6] != tt.wantX6 {
+			t.Errorf("entry %q: x5 = %d, x6 = %d, want 7 and %d", tt.entry, regs[5], regs[6], tt.wantX6)
+		}
+	}
+}
+
+func TestUndefinedGlobal(t *testing.T) {
+	if _, err := RunProgram(".globl main\naddi x5, x0, 1\n", Options{}); err == nil {
+		t.Error("a .globl of an undefined symbol assembled")
+	}
+}
+
+func TestIgnoredDirectivesAndSections(t *testing.T) {
+	source := strings.Join([]string{
+		`.file "main.c"`,
+		".section .rodata.str1.1, \"aMS\"",
+		"msg: .byte 72, 105",
+		".section .text.startup, \"ax\"",
+		".globl main",
+		".type main, @function",
+		"main:",
+		".cfi_startproc",
+		"auipc x5, %pcrel_hi(msg)",
+		"lbu x6, %pcrel_lo(main)(x5)",
+		".cfi_endproc",
+		".size main, .-main",
+		".section .note.GNU-stack, \"\", @progbits",
+		`.ident "GCC"`,
+	}, "\n")
+	m := runMachine(t, source, 1)
+	if got := m.GetRegisters()[6]; got != 72 {
+		t.Errorf("x6 = %d, want 72 from the .rodata section", got)
+	}
+	if n := len(m.Disassemble()); n != 2 {
+		t.Errorf("program has %d instructions, want 2", n)
+	}
+
+	want := []IgnoredDirective{{1, ".file"}, {6, ".type"}, {8, ".cfi_startproc"}, {11, ".cfi_endproc"},
+		{12, ".size"}, {13, ".section .note.GNU-stack"}, {14, ".ident"}}
+	ignored := m.IgnoredDirectives()
+	if len(ignored) != len(want) {
+		t.Fatalf("ignored directives %v, want %v", ignored, want)
+	}
+	for i := range want {
+		if ignored[i] != want[i] {
+			t.Errorf("ignored directive %d = %v, want %v", i, ignored[i], want[i])
+		}
+	}
+
+	if err := NewQuantumRISCVMachine(1).LoadRISCSource(".section\n"); err == nil {
+		t.Error(".section without a name assembled")
+	}
+}
+
+func TestDuplicateLabel(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		first, line int
+	}{
+		{"code", "loop:\naddi x5, x5, 1\nloop:\necall\n", 1, 3},
+		{"same line", "a: a: addi x5, x0, 1\n", 1, 1},
+		{"label before an instruction", "start: addi x5, x0, 1\n\n# comment\nstart:\n", 1, 4},
+		{"data and code", ".data\nvalue: .word 1\n.text\nvalue:\necall\n", 2, 4},
+	}
+	for _, tt := range tests {
+		err := NewQuantumRISCVMachine(1).LoadRISCSource(tt.source)
+		if err == nil {
+			t.Errorf("%s: duplicate label assembled", tt.name)
+			continue
+		}
+		for _, want := range []string{fmt.Sprintf("on line %d", tt.line), fmt.Sprintf("first defined on line %d", tt.first)} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q does not mention %q", tt.name, err, want)
+			}
+		}
+	}
+}
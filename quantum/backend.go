@@ -0,0 +1,221 @@
+package quantum
+
+import (
+	"fmt"
+	"hash"
+	"sort"
+)
+
+// State backends: a state keeps its amplitudes in an amplitudeStore, either a denseStore slice of
+// all 2^n amplitudes or a sparseStore map of its nonzero amplitudes. The slice is faster once most
+// basis states are populated, e.g. after Hadamards on every qubit; the map is the only option for
+// large machines and wins while few basis states are populated.
+const (
+	BackendAuto   = "auto"   // Dense up to denseQubitLimit qubits; larger states start sparse and turn dense as they fill up
+	BackendDense  = "dense"  // Always the dense slice (at most MaxDenseQubits qubits)
+	BackendSparse = "sparse" // Always the sparse map
+)
+
+// MaxDenseQubits is the largest state stored as a dense slice (2^24 amplitudes, 256 MiB)
+const MaxDenseQubits = 24
+
+// autoDenseFraction: with the auto backend, a sparse state of at most MaxDenseQubits qubits turns
+// dense once more than 1/autoDenseFraction of its basis states are populated
+const autoDenseFraction = 4
+
+// amplitudeStore is the storage of a state's amplitudes; each backend is one implementation.
+// QuantumState only reaches its amplitudes through this interface.
+type amplitudeStore interface {
+	amp(b BasisState) Complex128                // The amplitude of basis state b
+	setAmp(b BasisState, value Complex128)      // Write the amplitude of basis state b
+	each(fn func(b BasisState, amp Complex128)) // Visit the nonzero amplitudes in increasing basis state order
+	support() int                               // The number of nonzero amplitudes
+	norm() float64                              // Σ|amplitude|²
+	scale(factor Complex128)                    // Multiply every amplitude by factor
+	clone() amplitudeStore
+
+	// Gate kernels (gates.go) and state hashing (loops.go)
+	applyPairs(target int, controls []int, u [2][2]Complex128)
+	applyQuads(high, low int, u [4][4]Complex128)
+	hash(h hash.Hash64)
+}
+
+// denseStore holds all 2^n amplitudes, indexed by basis state. It holds at most MaxDenseQubits
+// qubits, so every basis state is its low word.
+type denseStore []Complex128
+
+func (d denseStore) amp(b BasisState) Complex128           { return d[b.low] }
+func (d denseStore) setAmp(b BasisState, value Complex128) { d[b.low] = value }
+func (d denseStore) clone() amplitudeStore                 { return append(denseStore(nil), d...) }
+
+func (d denseStore) each(fn func(b BasisState, amp Complex128)) {
+	for i, amp := range d {
+		if amp != 0 {
+			fn(BasisState{low: uint64(i)}, amp)
+		}
+	}
+}
+
+func (d denseStore) support() int {
+	size := 0
+	for _, amp := range d {
+		if amp != 0 {
+			size++
+		}
+	}
+	return size
+}
+
+func (d denseStore) norm() float64 {
+	var sum float64
+	for _, amp := range d {
+		sum += basisProbability(amp)
+	}
+	return sum
+}
+
+func (d denseStore) scale(factor Complex128) {
+	for i := range d {
+		d[i] *= factor
+	}
+}
+
+// sparseStore holds the nonzero amplitudes, keyed by basis state, so it addresses every qubit of
+// any machine. Writes drop amplitudes below sparsePruneThreshold, so rounding residue from
+// interference does not keep cancelled basis states populated.
+type sparseStore map[BasisState]Complex128
+
+func (s sparseStore) amp(b BasisState) Complex128 { return s[b] }
+func (s sparseStore) support() int                { return len(s) }
+
+func (s sparseStore) setAmp(b BasisState, value Complex128) {
+	if basisProbability(value) < sparsePruneThreshold {
+		delete(s, b)
+		return
+	}
+	s[b] = value
+}
+
+// each visits a snapshot of the populated basis states, so fn may overwrite amplitudes; entries
+// it removes before they are reached are skipped
+func (s sparseStore) each(fn func(b BasisState, amp Complex128)) {
+	populated := make([]BasisState, 0, len(s))
+	for b := range s {
+		populated = append(populated, b)
+	}
+	sort.Slice(populated, func(i, j int) bool { return populated[i].less(populated[j]) })
+	for _, b := range populated {
+		if amp, ok := s[b]; ok {
+			fn(b, amp)
+		}
+	}
+}
+
+func (s sparseStore) norm() float64 {
+	var sum float64
+	for _, amp := range s {
+		sum += basisProbability(amp)
+	}
+	return sum
+}
+
+func (s sparseStore) scale(factor Complex128) {
+	for i := range s {
+		s[i] *= factor
+	}
+}
+
+func (s sparseStore) clone() amplitudeStore {
+	clone := make(sparseStore, len(s))
+	for b, amp := range s {
+		clone[b] = amp
+	}
+	return clone
+}
+
+// newStore returns an all-zero store for numQubits qubits
+func newStore(numQubits int, dense bool) amplitudeStore {
+	if dense {
+		return make(denseStore, 1<<numQubits)
+	}
+	return make(sparseStore)
+}
+
+// StateBackends returns the accepted backend names
+func StateBackends() []string {
+	return []string{BackendAuto, BackendDense, BackendSparse}
+}
+
+// SetBackend selects how qs stores its amplitudes and converts the current storage to match
+func (qs *QuantumState) SetBackend(backend string) error {
+	switch backend {
+	case BackendAuto, BackendSparse:
+	case BackendDense:
+		if qs.numQubits > MaxDenseQubits {
+			return fmt.Errorf("the dense backend holds at most %d qubits, state has %d", MaxDenseQubits, qs.numQubits)
+		}
+	default:
+		return fmt.Errorf("unknown state backend %q (choose from %v)", backend, StateBackends())
+	}
+	qs.backend = backend
+	qs.setDense(qs.wantsDense())
+	return nil
+}
+
+// Backend returns the backend selected with SetBackend
+func (qs *QuantumState) Backend() string {
+	if qs.backend == "" {
+		return BackendAuto
+	}
+	return qs.backend
+}
+
+// IsSparse reports whether qs currently stores its amplitudes in the sparse map
+func (qs *QuantumState) IsSparse() bool {
+	_, sparse := qs.store.(sparseStore)
+	return sparse
+}
+
+// wantsDense reports whether the backend of qs calls for dense storage at its current support
+func (qs *QuantumState) wantsDense() bool {
+	switch qs.Backend() {
+	case BackendDense:
+		return true
+	case BackendSparse:
+		return false
+	}
+	if qs.numQubits <= denseQubitLimit {
+		return true
+	}
+	return qs.numQubits <= MaxDenseQubits && qs.SupportSize()*autoDenseFraction > 1<<qs.numQubits
+}
+
+// setDense converts qs to dense or sparse storage, keeping every amplitude
+func (qs *QuantumState) setDense(dense bool) {
+	if dense != qs.IsSparse() {
+		return
+	}
+	store := newStore(qs.numQubits, dense)
+	qs.store.each(store.setAmp)
+	qs.store = store
+	qs.invalidatePolar()
+}
+
+// growBackend turns an auto-backend sparse state dense once its support fills up. It runs after
+// every gate, so it only looks at the map size.
+func (qs *QuantumState) growBackend() {
+	sparse, ok := qs.store.(sparseStore)
+	if !ok || qs.Backend() != BackendAuto || qs.numQubits > MaxDenseQubits {
+		return
+	}
+	if len(sparse)*autoDenseFraction > 1<<qs.numQubits {
+		qs.setDense(true)
+	}
+}
+
+// copySettings gives qs the polar phase, renormalization and backend settings of from
+func (qs *QuantumState) copySettings(from *QuantumState) error {
+	qs.SetPolarPhases(from.polar)
+	qs.RenormalizeEvery(from.renormalizeEvery)
+	return qs.SetBackend(from.Backend())
+}
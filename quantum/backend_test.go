@@ -0,0 +1,176 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// bellLadder puts qubit 0 of state in superposition and entangles every other qubit with it,
+// then applies a few rotations so amplitudes differ in phase
+func bellLadder(state *QuantumState) {
+	H.Apply(state, 0, nil)
+	for q := 1; q < state.NumQubits(); q++ {
+		X.Apply(state, q, []int{q - 1})
+	}
+	RY(0.3).Apply(state, 1, nil)
+	T.Apply(state, 2, []int{0})
+}
+
+func TestSetBackendConvertsStorage(t *testing.T) {
+	tests := []struct {
+		backend    string
+		numQubits  int
+		wantSparse bool
+	}{
+		{BackendAuto, 4, false},
+		{BackendAuto, 22, true},
+		{BackendDense, 22, false},
+		{BackendSparse, 4, true},
+	}
+	for _, tt := range tests {
+		reference := NewQuantumState(tt.numQubits)
+		bellLadder(reference)
+		state := reference.Clone()
+		if err := state.SetBackend(tt.backend); err != nil {
+			t.Fatalf("SetBackend(%s) on %d qubits: %v", tt.backend, tt.numQubits, err)
+		}
+		if state.IsSparse() != tt.wantSparse {
+			t.Errorf("%s backend on %d qubits: IsSparse() = %v, want %v", tt.backend, tt.numQubits,
+				state.IsSparse(), tt.wantSparse)
+		}
+		for _, amp := range reference.NonzeroAmplitudes() {
+			if got := state.GetAmplitude(amp.Index); got != amp.Amplitude {
+				t.Errorf("%s backend: amplitude %d = %v, want %v", tt.backend, amp.Index, got, amp.Amplitude)
+			}
+		}
+		if state.SupportSize() != reference.SupportSize() {
+			t.Errorf("%s backend: support size %d, want %d", tt.backend, state.SupportSize(), reference.SupportSize())
+		}
+	}
+}
+
+// Every store implementation must run a circuit to the same amplitudes
+func TestBackendsAgree(t *testing.T) {
+	var results [][]Complex128
+	for _, backend := range []string{BackendDense, BackendSparse} {
+		state := NewQuantumState(5)
+		if err := state.SetBackend(backend); err != nil {
+			t.Fatal(err)
+		}
+		bellLadder(state)
+		CNOT.Apply(state, 4, []int{1})
+		swapQubits(state, 0, 3)
+		RX(1.2).Apply(state, 2, []int{4})
+		state.ApplyGlobalPhase(0.5)
+		state.SetAmplitude(9, 0.25)
+		if err := state.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		if norm := state.Norm(); math.Abs(norm-1) > 1e-12 {
+			t.Errorf("%s backend: norm %v after Normalize", backend, norm)
+		}
+		results = append(results, state.Clone().GetAmplitudes())
+	}
+	for i, want := range results[0] {
+		if got := results[1][i]; !approxEqual(got, want) {
+			t.Errorf("amplitude %d = %v with the sparse backend, %v with the dense one", i, got, want)
+		}
+	}
+}
+
+func TestSetBackendErrors(t *testing.T) {
+	if err := NewQuantumState(MaxDenseQubits + 1).SetBackend(BackendDense); err == nil {
+		t.Error("dense backend accepted more than MaxDenseQubits qubits")
+	}
+	if err := NewQuantumState(2).SetBackend("packed"); err == nil {
+		t.Error("unknown backend accepted")
+	}
+}
+
+func TestAutoBackendTurnsDense(t *testing.T) {
+	const numQubits = denseQubitLimit + 1
+	state := NewQuantumState(numQubits)
+	if !state.IsSparse() {
+		t.Fatalf("a fresh %d-qubit state should start sparse", numQubits)
+	}
+	for q := 0; q < numQubits; q++ {
+		H.Apply(state, q, nil)
+	}
+	if state.IsSparse() {
+		t.Errorf("auto backend kept a fully populated %d-qubit state sparse", numQubits)
+	}
+	want := complex(1/float64(int(1)<<(numQubits/2))/1.4142135623730951, 0)
+	if got := state.GetAmplitude(1<<numQubits - 1); cmplx.Abs(got-want) > 1e-12 {
+		t.Errorf("amplitude after the switch = %v, want %v", got, want)
+	}
+
+	sparse := NewQuantumState(numQubits)
+	sparse.SetBackend(BackendSparse)
+	for q := 0; q < numQubits; q++ {
+		H.Apply(sparse, q, nil)
+	}
+	if !sparse.IsSparse() {
+		t.Error("sparse backend switched to dense storage")
+	}
+}
+
+func TestMachineKeepsBackend(t *testing.T) {
+	m := NewQuantumRISCVMachine(3)
+	if err := m.GetState().SetBackend(BackendSparse); err != nil {
+		t.Fatal(err)
+	}
+	m.ResetQuantumState()
+	if got := m.GetState().Backend(); got != BackendSparse || !m.GetState().IsSparse() {
+		t.Errorf("after ResetQuantumState the backend is %s (sparse storage %v), want sparse",
+			got, m.GetState().IsSparse())
+	}
+	if err := m.LoadState(NewQuantumState(3)); err != nil {
+		t.Fatal(err)
+	}
+	if !m.GetState().IsSparse() {
+		t.Error("LoadState dropped the sparse backend")
+	}
+}
+
+// benchmarkBackend times circuit on a numQubits state stored by backend
+func benchmarkBackend(b *testing.B, backend string, numQubits int, circuit func(*QuantumState)) {
+	for i := 0; i < b.N; i++ {
+		state := NewQuantumState(numQubits)
+		if err := state.SetBackend(backend); err != nil {
+			b.Fatal(err)
+		}
+		circuit(state)
+	}
+}
+
+// uniformLayers applies H to every qubit and then a layer of controlled phases, populating
+// every basis state
+func uniformLayers(state *QuantumState) {
+	for q := 0; q < state.NumQubits(); q++ {
+		H.Apply(state, q, nil)
+	}
+	for q := 1; q < state.NumQubits(); q++ {
+		S.Apply(state, q, []int{q - 1})
+	}
+}
+
+// ghzChain prepares a GHZ state, which populates only two basis states
+func ghzChain(state *QuantumState) {
+	H.Apply(state, 0, nil)
+	for q := 1; q < state.NumQubits(); q++ {
+		X.Apply(state, q, []int{q - 1})
+	}
+}
+
+func BenchmarkBackendsUniform(b *testing.B) {
+	for _, backend := range []string{BackendDense, BackendSparse} {
+		b.Run(backend, func(b *testing.B) { benchmarkBackend(b, backend, 14, uniformLayers) })
+	}
+}
+
+func BenchmarkBackendsGHZ(b *testing.B) {
+	for _, backend := range []string{BackendDense, BackendSparse} {
+		b.Run(backend, func(b *testing.B) { benchmarkBackend(b, backend, 20, ghzChain) })
+	}
+}
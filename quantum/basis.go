@@ -0,0 +1,121 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IndexQubits is how many qubits an int basis state index covers: qubit q is bit q of the index.
+// Gates and measurements address every qubit of a machine, but the index-based API
+// (GetAmplitude, CollapseTo, Sample, ExportState, ...) only names basis states whose set qubits
+// are all below IndexQubits.
+const IndexQubits = 63
+
+// BasisState is a computational basis state of any number of qubits: Bit(q) is the value of
+// qubit q. Qubits 0-63 are kept in a word and higher ones as little-endian bytes without trailing
+// zero bytes, so every basis state has one representation, == compares basis states and a
+// BasisState can key a map.
+type BasisState struct {
+	low  uint64
+	high string
+}
+
+// NewBasisState returns the basis state with int index index (see IndexQubits)
+func NewBasisState(index int) BasisState {
+	return BasisState{low: uint64(index)}
+}
+
+// Bit returns the value (0 or 1) of qubit in b
+func (b BasisState) Bit(qubit int) int {
+	if qubit < 64 {
+		return int(b.low>>qubit) & 1
+	}
+	byteIndex := (qubit - 64) / 8
+	if byteIndex >= len(b.high) {
+		return 0
+	}
+	return int(b.high[byteIndex]>>((qubit-64)%8)) & 1
+}
+
+// Index returns b as an int index, or false when b sets a qubit of IndexQubits or above
+func (b BasisState) Index() (int, bool) {
+	if b.high != "" || b.low>>IndexQubits != 0 {
+		return 0, false
+	}
+	return int(b.low), true
+}
+
+// Bits formats b as numQubits binary digits, most significant qubit first: the q(n-1)...q0 of
+// |q(n-1)...q0⟩
+func (b BasisState) Bits(numQubits int) string {
+	var sb strings.Builder
+	sb.Grow(numQubits)
+	for q := numQubits - 1; q >= 0; q-- {
+		sb.WriteByte(byte('0' + b.Bit(q)))
+	}
+	return sb.String()
+}
+
+// String formats b as its int index, or as its bits from the highest set qubit when it has none
+func (b BasisState) String() string {
+	if index, ok := b.Index(); ok {
+		return fmt.Sprint(index)
+	}
+	return strings.TrimLeft(b.Bits(64+8*len(b.high)), "0")
+}
+
+// flip returns b with qubit inverted
+func (b BasisState) flip(qubit int) BasisState {
+	if qubit < 64 {
+		b.low ^= 1 << qubit
+		return b
+	}
+	byteIndex := (qubit - 64) / 8
+	high := []byte(b.high)
+	if byteIndex >= len(high) {
+		high = append(high, make([]byte, byteIndex+1-len(high))...)
+	}
+	high[byteIndex] ^= 1 << ((qubit - 64) % 8)
+	for len(high) > 0 && high[len(high)-1] == 0 {
+		high = high[:len(high)-1]
+	}
+	b.high = string(high)
+	return b
+}
+
+// withBit returns b with qubit set to value (0 or 1)
+func (b BasisState) withBit(qubit, value int) BasisState {
+	if b.Bit(qubit) != value {
+		return b.flip(qubit)
+	}
+	return b
+}
+
+// allSet reports whether every one of qubits is 1 in b
+func (b BasisState) allSet(qubits []int) bool {
+	for _, q := range qubits {
+		if b.Bit(q) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// less orders basis states by their value as binary numbers
+func (b BasisState) less(other BasisState) bool {
+	if len(b.high) != len(other.high) {
+		return len(b.high) < len(other.high)
+	}
+	for i := len(b.high) - 1; i >= 0; i-- {
+		if b.high[i] != other.high[i] {
+			return b.high[i] < other.high[i]
+		}
+	}
+	return b.low < other.low
+}
+
+// errNoIndex is the error of an index-based operation on a basis state that has no int index
+func errNoIndex(operation string, b BasisState) error {
+	return fmt.Errorf("cannot %s: basis state %s sets a qubit above %d, beyond what an index holds", operation, b,
+		IndexQubits-1)
+}
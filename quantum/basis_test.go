@@ -0,0 +1,73 @@
+package quantum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBasisStateBits(t *testing.T) {
+	tests := []struct {
+		name      string
+		qubits    []int // Set, in order, from |0...0⟩
+		wantIndex int
+		wantOK    bool
+	}{
+		{"ground", nil, 0, true},
+		{"low qubits", []int{0, 2}, 5, true},
+		{"highest indexable qubit", []int{IndexQubits - 1}, 1 << (IndexQubits - 1), true},
+		{"qubit 63", []int{63}, 0, false},
+		{"qubit 1000", []int{1000, 1}, 0, false},
+	}
+	for _, tt := range tests {
+		b := BasisState{}
+		for _, q := range tt.qubits {
+			b = b.flip(q)
+		}
+		for _, q := range []int{0, 1, 2, 62, 63, 64, 999, 1000, 1999} {
+			want := 0
+			for _, set := range tt.qubits {
+				if set == q {
+					want = 1
+				}
+			}
+			if got := b.Bit(q); got != want {
+				t.Errorf("%s: Bit(%d) = %d, want %d", tt.name, q, got, want)
+			}
+		}
+		if index, ok := b.Index(); index != tt.wantIndex || ok != tt.wantOK {
+			t.Errorf("%s: Index() = %d, %v, want %d, %v", tt.name, index, ok, tt.wantIndex, tt.wantOK)
+		}
+		// Clearing every qubit again must give back the one representation of |0...0⟩
+		for _, q := range tt.qubits {
+			b = b.withBit(q, 0)
+		}
+		if b != (BasisState{}) {
+			t.Errorf("%s: clearing the set qubits left %#v", tt.name, b)
+		}
+	}
+}
+
+func TestBasisStateOrder(t *testing.T) {
+	ordered := []BasisState{
+		{},
+		NewBasisState(1),
+		NewBasisState(6),
+		BasisState{}.flip(63),
+		BasisState{}.flip(64),
+		BasisState{}.flip(64).flip(3),
+		BasisState{}.flip(1000),
+	}
+	for i := range ordered {
+		for j := range ordered {
+			if got := ordered[i].less(ordered[j]); got != (i < j) {
+				t.Errorf("%v.less(%v) = %v, want %v", ordered[i], ordered[j], got, i < j)
+			}
+		}
+	}
+	if got := NewBasisState(5).Bits(4); got != "0101" {
+		t.Errorf("Bits(4) of 5 = %q, want 0101", got)
+	}
+	if got, want := (BasisState{}).flip(70).String(), "1"+strings.Repeat("0", 70); got != want {
+		t.Errorf("String() of qubit 70 = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package quantum
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// MaxBenchmarkQubits bounds the state size BenchmarkGates will allocate (2^24 amplitudes = 256 MiB)
+const MaxBenchmarkQubits = 24
+
+// benchmarkDuration is how long BenchmarkGates times each operation
+const benchmarkDuration = 200 * time.Millisecond
+
+// GateBenchmark is the cost of one state-vector operation, measured by BenchmarkGates
+type GateBenchmark struct {
+	Name        string
+	Runs        int   // Iterations the benchmark timed
+	NsPerOp     int64 // Wall time per application
+	AllocsPerOp int64 // Heap allocations per application
+	BytesPerOp  int64 // Heap bytes allocated per application
+}
+
+// gateBenchmarks are the operations BenchmarkGates measures, each applied to a numQubits state.
+// The Go benchmarks in gates_test.go time the same operations.
+var gateBenchmarks = []struct {
+	name string
+	op   func(state *QuantumState, numQubits int)
+}{
+	{"SingleGate (H)", func(state *QuantumState, n int) { H.Apply(state, n-1, nil) }},
+	{"CNOT", func(state *QuantumState, n int) { X.Apply(state, n-1, []int{0}) }},
+	{"Measure", func(state *QuantumState, n int) {
+		// Re-spread the measured qubit so every iteration measures a superposition
+		H.Apply(state, 0, nil)
+		state.Measure(0, NewSplitMix64(0))
+	}},
+}
+
+// newBenchmarkState returns the dense numQubits state the gate benchmarks run on, with qubit 0
+// in superposition so the CNOT has work to do
+func newBenchmarkState(numQubits int) *QuantumState {
+	state := newDenseQuantumState(numQubits) // Time the state vector, never the sparse map
+	state.InitializeZeroState()
+	H.Apply(state, 0, nil)
+	return state
+}
+
+// BenchmarkGates times a single-qubit gate, a CNOT and a measurement on a numQubits state and
+// reports the allocations each one makes. Like go test -bench, it doubles the number of runs
+// until a batch takes benchmarkDuration, and reports that batch.
+func BenchmarkGates(numQubits int) ([]GateBenchmark, error) {
+	if numQubits < 2 || numQubits > MaxBenchmarkQubits {
+		return nil, fmt.Errorf("benchmark qubit count must be between 2 and %d, got %d", MaxBenchmarkQubits, numQubits)
+	}
+	results := make([]GateBenchmark, 0, len(gateBenchmarks))
+	for _, bench := range gateBenchmarks {
+		state := newBenchmarkState(numQubits)
+		var before, after runtime.MemStats
+		for runs := 1; ; runs *= 2 {
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+			for i := 0; i < runs; i++ {
+				bench.op(state, numQubits)
+			}
+			elapsed := time.Since(start)
+			runtime.ReadMemStats(&after)
+			if elapsed < benchmarkDuration {
+				continue
+			}
+			results = append(results, GateBenchmark{
+				Name:        bench.name,
+				Runs:        runs,
+				NsPerOp:     elapsed.Nanoseconds() / int64(runs),
+				AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(runs),
+				BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / int64(runs),
+			})
+			break
+		}
+	}
+	return results, nil
+}
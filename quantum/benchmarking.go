@@ -0,0 +1,64 @@
+package quantum
+
+import "fmt"
+
+// MaxBenchmarkLength bounds the number of random gate layers RandomizedBenchmark applies
+const MaxBenchmarkLength = 100000
+
+// cliffordGates are the single-qubit Clifford gates (X, Y, Z, H, S) randomized benchmarking
+// draws each layer's gates from
+var cliffordGates = []uint8{0x00, 0x01, 0x02, 0x03, 0x04}
+
+// BenchmarkResult is the outcome of one randomized benchmarking sequence
+type BenchmarkResult struct {
+	Gates    int     // Random gates applied before the inversion
+	Survival float64 // Probability of being back in |0...0⟩ after the inverse circuit
+	Outcome  int     // Basis state the final measurement of every qubit returned
+}
+
+// RandomizedBenchmark runs a randomized benchmarking sequence on a fresh numQubits scratch
+// machine, leaving this machine's state untouched: `length` layers of random Clifford gates (one
+// per qubit, drawn with this machine's RNG), then the inverse of the whole circuit, then a
+// measurement of every qubit. The scratch machine applies this machine's noise model after every
+// gate. Without noise the inverse undoes the sequence exactly and the survival probability is 1;
+// gate errors show up as a survival probability below 1.
+func (m *QuantumRISCVMachine) RandomizedBenchmark(length, numQubits int) (BenchmarkResult, error) {
+	if length < 1 || length > MaxBenchmarkLength {
+		return BenchmarkResult{}, fmt.Errorf("sequence length must be between 1 and %d, got %d",
+			MaxBenchmarkLength, length)
+	}
+	if numQubits < 1 || numQubits > MaxBenchmarkQubits {
+		return BenchmarkResult{}, fmt.Errorf("benchmark qubit count must be between 1 and %d, got %d",
+			MaxBenchmarkQubits, numQubits)
+	}
+
+	scratch := NewQuantumRISCVMachine(numQubits)
+	scratch.rng = m.rng // Share the RNG so a seeded machine reproduces the sequence
+	scratch.noise = m.noise
+	for layer := 0; layer < length; layer++ {
+		for q := 0; q < numQubits; q++ {
+			opcode := cliffordGates[int(m.rng.Float64()*float64(len(cliffordGates)))]
+			if err := scratch.executeInstruction(Instruction{Opcode: opcode, Target: q}); err != nil {
+				return BenchmarkResult{}, err
+			}
+		}
+	}
+	// Invert the ideal circuit: the noise recorded between its gates is what the benchmark measures
+	var ideal []GateRecord
+	for _, record := range scratch.history {
+		if record.Name != "NOISE" {
+			ideal = append(ideal, record)
+		}
+	}
+	scratch.applyInverse(ideal)
+
+	result := BenchmarkResult{Gates: len(ideal), Survival: basisProbability(scratch.state.amp(BasisState{}))}
+	for q := 0; q < numQubits; q++ {
+		measured, err := scratch.state.Measure(q, scratch.rng)
+		if err != nil {
+			return BenchmarkResult{}, err
+		}
+		result.Outcome |= measured.Outcome << q
+	}
+	return result, nil
+}
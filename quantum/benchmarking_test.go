@@ -0,0 +1,74 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandomizedBenchmarkIdeal(t *testing.T) {
+	tests := []struct {
+		length, qubits int
+	}{
+		{1, 1},
+		{10, 1},
+		{25, 3},
+		{200, 2},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(2)
+		m.SetSeed(11)
+		H.Apply(m.GetState(), 0, nil)
+		before := m.GetState().Clone()
+
+		result, err := m.RandomizedBenchmark(tt.length, tt.qubits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Gates != tt.length*tt.qubits {
+			t.Errorf("length %d on %d qubits: %d gates, want %d", tt.length, tt.qubits, result.Gates,
+				tt.length*tt.qubits)
+		}
+		if math.Abs(result.Survival-1) > 1e-9 || result.Outcome != 0 {
+			t.Errorf("length %d on %d qubits: survival %g, outcome %b, want 1 and 0", tt.length, tt.qubits,
+				result.Survival, result.Outcome)
+		}
+		// The benchmark runs on a scratch machine
+		for i, amp := range before.GetAmplitudes() {
+			if m.GetState().GetAmplitudes()[i] != amp {
+				t.Errorf("length %d: machine amplitude %d changed", tt.length, i)
+			}
+		}
+		if len(m.GetGateHistory()) != 0 {
+			t.Errorf("length %d: benchmark gates recorded on the machine", tt.length)
+		}
+	}
+}
+
+func TestRandomizedBenchmarkIsReproducible(t *testing.T) {
+	run := func() BenchmarkResult {
+		m := NewQuantumRISCVMachine(1)
+		m.SetSeed(5)
+		if err := m.SetNoise(NoiseModel{BitFlip: 0.1}); err != nil {
+			t.Fatal(err)
+		}
+		result, err := m.RandomizedBenchmark(30, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	if a, b := run(), run(); a != b {
+		t.Errorf("seeded benchmarks differ: %+v and %+v", a, b)
+	}
+}
+
+func TestRandomizedBenchmarkErrors(t *testing.T) {
+	m := NewQuantumRISCVMachine(1)
+	for _, tt := range []struct{ length, qubits int }{
+		{0, 1}, {-3, 1}, {MaxBenchmarkLength + 1, 1}, {5, 0}, {5, MaxBenchmarkQubits + 1},
+	} {
+		if _, err := m.RandomizedBenchmark(tt.length, tt.qubits); err == nil {
+			t.Errorf("RandomizedBenchmark(%d, %d) succeeded", tt.length, tt.qubits)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package quantum
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ClassicalBit is a named bit of the classical-bit store that measurements write into
+type ClassicalBit struct {
+	Name  string
+	Value int // 0 or 1
+}
+
+// MeasureToBit measures qubit and stores the outcome in the named classical bit (e.g. "c0"),
+// so later gates can be conditioned on it without going through integer registers
+func (m *QuantumRISCVMachine) MeasureToBit(qubit int, name string) (MeasurementResult, error) {
+	if !isLabelName(name) {
+		return MeasurementResult{}, fmt.Errorf("invalid classical bit name '%s'", name)
+	}
+	result, err := m.MeasureQubit(qubit)
+	if err != nil {
+		return MeasurementResult{}, err
+	}
+	if m.cbits == nil {
+		m.cbits = make(map[string]int)
+	}
+	m.cbits[name] = result.Outcome
+	return result, nil
+}
+
+// ClassicalBitValue returns the value of a classical bit, or an error if nothing was measured
+// into it yet
+func (m *QuantumRISCVMachine) ClassicalBitValue(name string) (int, error) {
+	value, ok := m.cbits[name]
+	if !ok {
+		return 0, fmt.Errorf("classical bit %s has not been set by a measurement", name)
+	}
+	return value, nil
+}
+
+// ClassicalBits returns every classical bit, sorted by name
+func (m *QuantumRISCVMachine) ClassicalBits() []ClassicalBit {
+	bits := make([]ClassicalBit, 0, len(m.cbits))
+	for name, value := range m.cbits {
+		bits = append(bits, ClassicalBit{Name: name, Value: value})
+	}
+	sort.Slice(bits, func(i, j int) bool { return bits[i].Name < bits[j].Name })
+	return bits
+}
@@ -0,0 +1,53 @@
+package quantum
+
+import "testing"
+
+func TestMeasureToBit(t *testing.T) {
+	m := NewQuantumRISCVMachine(2)
+	X.Apply(m.GetState(), 1, nil)
+	for _, tt := range []struct {
+		qubit int
+		name  string
+		want  int
+	}{
+		{1, "c1", 1},
+		{0, "c0", 0},
+		{0, "result_2", 0},
+	} {
+		result, err := m.MeasureToBit(tt.qubit, tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := m.ClassicalBitValue(tt.name)
+		if err != nil || value != tt.want || result.Outcome != tt.want {
+			t.Errorf("measure q%d -> %s: stored %d (error %v), outcome %d, want %d", tt.qubit, tt.name, value,
+				err, result.Outcome, tt.want)
+		}
+	}
+
+	want := []ClassicalBit{{"c0", 0}, {"c1", 1}, {"result_2", 0}}
+	bits := m.ClassicalBits()
+	if len(bits) != len(want) {
+		t.Fatalf("ClassicalBits() = %v, want %v", bits, want)
+	}
+	for i := range want {
+		if bits[i] != want[i] {
+			t.Errorf("ClassicalBits()[%d] = %v, want %v", i, bits[i], want[i])
+		}
+	}
+}
+
+func TestMeasureToBitErrors(t *testing.T) {
+	m := NewQuantumRISCVMachine(1)
+	for _, name := range []string{"", "0c", "c-1", "c 1"} {
+		if _, err := m.MeasureToBit(0, name); err == nil {
+			t.Errorf("MeasureToBit accepted bit name %q", name)
+		}
+	}
+	if _, err := m.MeasureToBit(1, "c0"); err == nil {
+		t.Error("MeasureToBit accepted qubit 1 of a 1-qubit machine")
+	}
+	if _, err := m.ClassicalBitValue("c0"); err == nil {
+		t.Error("a failed measurement set c0")
+	}
+}
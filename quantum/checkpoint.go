@@ -0,0 +1,79 @@
+package quantum
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// Checkpoints: SaveState and LoadState persist a state in a compact binary (gob) file so a long
+// computation can be resumed exactly. Amplitudes keep every bit, unlike the text formats of
+// ExportState, and only the nonzero ones are stored, so sparse states stay small.
+
+// checkpointFormat identifies a saved state file and its layout version
+const checkpointFormat = "qmachine-state/1"
+
+// checkpointFile is the gob layout of a saved state: Amplitudes[i] belongs to basis state Indices[i]
+type checkpointFile struct {
+	Format     string
+	NumQubits  int
+	Indices    []int
+	Amplitudes []Complex128
+}
+
+// SaveState writes the qubit count and nonzero amplitudes of qs to filename. Basis states are
+// stored by index, so every populated one must have an index (see IndexQubits).
+func (qs *QuantumState) SaveState(filename string) error {
+	checkpoint := checkpointFile{Format: checkpointFormat, NumQubits: qs.numQubits}
+	for _, basis := range qs.NonzeroAmplitudes() {
+		if basis.Index < 0 {
+			return errNoIndex("save the state", basis.Basis)
+		}
+		checkpoint.Indices = append(checkpoint.Indices, basis.Index)
+		checkpoint.Amplitudes = append(checkpoint.Amplitudes, basis.Amplitude)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %v", err)
+	}
+	err = gob.NewEncoder(file).Encode(checkpoint)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	return nil
+}
+
+// LoadState replaces qs with the state saved in filename, including its qubit count. The polar
+// phase, renormalization and backend settings of qs are kept. On error qs is left untouched.
+func (qs *QuantumState) LoadState(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %v", err)
+	}
+	defer file.Close()
+
+	var checkpoint checkpointFile
+	if err := gob.NewDecoder(file).Decode(&checkpoint); err != nil || checkpoint.Format != checkpointFormat {
+		return fmt.Errorf("%s is not a saved state file", filename)
+	}
+	if checkpoint.NumQubits < 1 || len(checkpoint.Indices) != len(checkpoint.Amplitudes) {
+		return fmt.Errorf("state file %s is corrupt", filename)
+	}
+
+	loaded := NewUninitializedQuantumState(checkpoint.NumQubits)
+	for i, index := range checkpoint.Indices {
+		if !loaded.inRange(index) {
+			return fmt.Errorf("basis state %d is out of range for %d qubits", index, checkpoint.NumQubits)
+		}
+		loaded.setAmp(NewBasisState(index), checkpoint.Amplitudes[i])
+	}
+	if err := loaded.copySettings(qs); err != nil {
+		return err
+	}
+	*qs = *loaded
+	return nil
+}
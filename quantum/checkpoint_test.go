@@ -0,0 +1,84 @@
+package quantum
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadStateRoundTrip(t *testing.T) {
+	wide := NewQuantumState(40) // Stored sparsely
+	H.Apply(wide, 0, nil)
+	X.Apply(wide, 39, nil)
+	RZ(0.3).Apply(wide, 0, nil)
+
+	for name, want := range map[string]*QuantumState{"dense": samplePhaseState(), "sparse": wide} {
+		path := filepath.Join(t.TempDir(), "state.bin")
+		if err := want.SaveState(path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got := NewQuantumState(1)
+		got.SetPolarPhases(true)
+		if err := got.LoadState(path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.NumQubits() != want.NumQubits() {
+			t.Errorf("%s: loaded %d qubits, want %d", name, got.NumQubits(), want.NumQubits())
+		}
+		wantAmps, gotAmps := want.NonzeroAmplitudes(), got.NonzeroAmplitudes()
+		if len(gotAmps) != len(wantAmps) {
+			t.Fatalf("%s: loaded %d nonzero amplitudes, want %d", name, len(gotAmps), len(wantAmps))
+		}
+		for i := range wantAmps {
+			if gotAmps[i] != wantAmps[i] { // Bit-exact
+				t.Errorf("%s: amplitude %v, want %v", name, gotAmps[i], wantAmps[i])
+			}
+		}
+		if !got.PolarPhases() {
+			t.Errorf("%s: LoadState dropped the polar phases setting", name)
+		}
+	}
+}
+
+func TestLoadStateErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, checkpoint checkpointFile) string {
+		path := filepath.Join(dir, name)
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if err := gob.NewEncoder(file).Encode(checkpoint); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	text := filepath.Join(dir, "state.txt")
+	if err := os.WriteFile(text, []byte("index,re,im\n0,1,0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{
+		filepath.Join(dir, "missing.bin"),
+		text,
+		write("format.bin", checkpointFile{Format: "qmachine-state/0", NumQubits: 1, Indices: []int{0},
+			Amplitudes: []Complex128{1}}),
+		write("qubits.bin", checkpointFile{Format: checkpointFormat, NumQubits: 0}),
+		write("lengths.bin", checkpointFile{Format: checkpointFormat, NumQubits: 1, Indices: []int{0, 1},
+			Amplitudes: []Complex128{1}}),
+		write("range.bin", checkpointFile{Format: checkpointFormat, NumQubits: 1, Indices: []int{2},
+			Amplitudes: []Complex128{1}}),
+	}
+	for _, path := range paths {
+		qs := NewQuantumState(2)
+		X.Apply(qs, 1, nil)
+		if err := qs.LoadState(path); err == nil {
+			t.Errorf("%s loaded, want an error", filepath.Base(path))
+		}
+		if qs.NumQubits() != 2 || qs.GetAmplitude(2) != 1 {
+			t.Errorf("%s: failed LoadState changed the state", filepath.Base(path))
+		}
+	}
+}
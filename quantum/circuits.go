@@ -0,0 +1,86 @@
+package quantum
+
+import "fmt"
+
+// validateQubits checks that every qubit is on the machine and that none is repeated
+func (m *QuantumRISCVMachine) validateQubits(qubits ...int) error {
+	seen := make(map[int]bool, len(qubits))
+	for _, q := range qubits {
+		if err := m.state.checkQubit(q); err != nil {
+			return err
+		}
+		if seen[q] {
+			return fmt.Errorf("qubit %d used more than once", q)
+		}
+		seen[q] = true
+	}
+	return nil
+}
+
+// ApplySWAP exchanges the states of qubits a and b and records a SWAP
+func (m *QuantumRISCVMachine) ApplySWAP(a, b int) error {
+	if err := m.validateQubits(a, b); err != nil {
+		return err
+	}
+	swapQubits(m.state, a, b)
+	m.recordGate("SWAP", a, []int{b})
+	return nil
+}
+
+// applyCNOT flips target when control is 1. It is built from a controlled X so that it acts on
+// any pair of qubit indices.
+func (m *QuantumRISCVMachine) applyCNOT(control, target int) {
+	X.Apply(m.state, target, []int{control})
+	m.recordGate("CNOT", target, []int{control})
+}
+
+// ZZInteraction applies e^{-iγ Z_a Z_b}, the two-qubit cost rotation of QAOA, as
+// CNOT(a -> b), RZ(2γ) on b, CNOT(a -> b)
+func (m *QuantumRISCVMachine) ZZInteraction(a, b int, gamma float64) error {
+	if err := m.validateQubits(a, b); err != nil {
+		return err
+	}
+	m.applyCNOT(a, b)
+	m.applyRotation("RZ", 2*gamma, b, nil)
+	m.applyCNOT(a, b)
+	return nil
+}
+
+// ApplyMixer applies the QAOA mixing layer RX(2β) to every qubit. The layer populates all 2^n
+// basis states, so it is refused on machines wider than MaxDenseQubits.
+func (m *QuantumRISCVMachine) ApplyMixer(beta float64) error {
+	numQubits := m.state.NumQubits()
+	if numQubits > MaxDenseQubits {
+		return fmt.Errorf("mixer populates all 2^%d basis states: at most %d qubits supported", numQubits, MaxDenseQubits)
+	}
+	for q := 0; q < numQubits; q++ {
+		if err := m.state.checkQubit(q); err != nil {
+			return err
+		}
+		m.applyRotation("RX", 2*beta, q, nil)
+	}
+	return nil
+}
+
+// PrepareBell applies H to q1 then CNOT(q1 -> q2), turning |00⟩ into the Bell state
+// |Φ+⟩ = (|00⟩+|11⟩)/√2 on those two qubits
+func (m *QuantumRISCVMachine) PrepareBell(q1, q2 int) error {
+	return m.PrepareGHZ(q1, q2)
+}
+
+// PrepareGHZ applies H to the first qubit and a CNOT chain q1 -> q2 -> ... -> qn, turning
+// |0...0⟩ into the GHZ state (|0...0⟩+|1...1⟩)/√2 on those qubits
+func (m *QuantumRISCVMachine) PrepareGHZ(qubits ...int) error {
+	if len(qubits) < 2 {
+		return fmt.Errorf("GHZ state needs at least 2 qubits, got %d", len(qubits))
+	}
+	if err := m.validateQubits(qubits...); err != nil {
+		return err
+	}
+	H.Apply(m.state, qubits[0], nil)
+	m.recordGate("H", qubits[0], nil)
+	for i := 1; i < len(qubits); i++ {
+		m.applyCNOT(qubits[i-1], qubits[i])
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestApplyMixer(t *testing.T) {
+	tests := []struct {
+		numQubits int
+		beta      float64
+		wantErr   bool
+	}{
+		{1, math.Pi / 4, false},
+		{3, math.Pi / 4, false},
+		{3, 0, false},
+		{MaxDenseQubits + 1, 0.1, true},
+		{70, 0.1, true},
+		{2000, 0.1, true},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(tt.numQubits)
+		err := m.ApplyMixer(tt.beta)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ApplyMixer on %d qubits succeeded, want an error", tt.numQubits)
+			}
+			if len(m.GetGateHistory()) != 0 {
+				t.Errorf("rejected mixer on %d qubits still recorded gates", tt.numQubits)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ApplyMixer on %d qubits: %v", tt.numQubits, err)
+		}
+		// RX(2β)|0⟩ leaves each qubit 0 with probability cos²β, independently
+		want := math.Pow(math.Cos(tt.beta), 2*float64(tt.numQubits))
+		if got := math.Pow(cmplx.Abs(m.GetState().GetAmplitude(0)), 2); math.Abs(got-want) > 1e-12 {
+			t.Errorf("mixer(%g) on %d qubits: P(|0...0⟩) = %g, want %g", tt.beta, tt.numQubits, got, want)
+		}
+		if got := len(m.GetGateHistory()); got != tt.numQubits {
+			t.Errorf("mixer on %d qubits recorded %d gates", tt.numQubits, got)
+		}
+	}
+}
+
+// checkCatState checks that exactly |0...0⟩ and the basis state with all of qubits set each
+// have probability 1/2
+func checkCatState(t *testing.T, state *QuantumState, qubits []int) {
+	t.Helper()
+	ones := 0
+	for _, q := range qubits {
+		ones |= 1 << q
+	}
+	for _, amp := range state.NonzeroAmplitudes() {
+		if amp.Index != 0 && amp.Index != ones {
+			t.Errorf("qubits %v: unexpected basis state %b populated", qubits, amp.Index)
+		}
+	}
+	for _, index := range []int{0, ones} {
+		if p := basisProbability(state.GetAmplitude(index)); math.Abs(p-0.5) > 1e-12 {
+			t.Errorf("qubits %v: P(%b) = %g, want 0.5", qubits, index, p)
+		}
+	}
+}
+
+func TestPrepareBell(t *testing.T) {
+	tests := []struct {
+		q1, q2  int
+		wantErr bool
+	}{
+		{0, 1, false},
+		{1, 0, false},
+		{0, 3, false},
+		{2, 2, true},
+		{0, 4, true},
+		{-1, 0, true},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(4)
+		err := m.PrepareBell(tt.q1, tt.q2)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("PrepareBell(%d, %d): error %v, want error %v", tt.q1, tt.q2, err, tt.wantErr)
+		}
+		if err == nil {
+			checkCatState(t, m.GetState(), []int{tt.q1, tt.q2})
+		}
+	}
+}
+
+func TestPrepareGHZ(t *testing.T) {
+	tests := []struct {
+		qubits  []int
+		wantErr bool
+	}{
+		{[]int{0, 1}, false},
+		{[]int{0, 1, 2}, false},
+		{[]int{4, 0, 2, 5}, false},
+		{[]int{0, 1, 2, 3, 4, 5}, false},
+		{[]int{0}, true},
+		{nil, true},
+		{[]int{0, 1, 0}, true},
+		{[]int{0, 6}, true},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(6)
+		err := m.PrepareGHZ(tt.qubits...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("PrepareGHZ(%v): error %v, want error %v", tt.qubits, err, tt.wantErr)
+		}
+		if err != nil {
+			if len(m.GetGateHistory()) != 0 {
+				t.Errorf("rejected PrepareGHZ(%v) still applied gates", tt.qubits)
+			}
+			continue
+		}
+		checkCatState(t, m.GetState(), tt.qubits)
+		if got := len(m.GetGateHistory()); got != len(tt.qubits) {
+			t.Errorf("PrepareGHZ(%v) recorded %d gates, want H plus %d CNOTs", tt.qubits, got, len(tt.qubits)-1)
+		}
+	}
+}
+
+func TestZZInteraction(t *testing.T) {
+	tests := []struct {
+		a, b    int
+		gamma   float64
+		wantErr bool
+	}{
+		{0, 1, 0.3, false},
+		{2, 0, -1.2, false},
+		{1, 2, math.Pi, false},
+		{1, 1, 0.3, true},
+		{0, 3, 0.3, true},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		for q := 0; q < 3; q++ {
+			H.Apply(m.GetState(), q, nil)
+		}
+		before := m.GetState().GetAmplitudes()
+		err := m.ZZInteraction(tt.a, tt.b, tt.gamma)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ZZInteraction(%d, %d): error %v, want error %v", tt.a, tt.b, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		// Each basis state picks up e^{-iγ z_a z_b}, with z = +1 for a 0 bit and -1 for a 1 bit
+		for i, amp := range m.GetState().GetAmplitudes() {
+			zz := 1.0
+			if (i>>tt.a)&1 != (i>>tt.b)&1 {
+				zz = -1
+			}
+			want := before[i] * cmplx.Exp(complex(0, -tt.gamma*zz))
+			if !approxEqual(amp, want) {
+				t.Errorf("ZZ(%d, %d, %g): amplitude %03b = %v, want %v", tt.a, tt.b, tt.gamma, i, amp, want)
+			}
+		}
+	}
+}
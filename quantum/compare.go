@@ -0,0 +1,70 @@
+package quantum
+
+import (
+	"fmt"
+	"sort"
+)
+
+// distributionTolerance is how far two basis state probabilities may differ and still match
+const distributionTolerance = 1e-9
+
+// Comparison is the first difference between the final states of two machines, used to check
+// an optimized program against a reference implementation
+type Comparison struct {
+	Register      int        // First register whose final values differ, or -1
+	Values        [2]uint64  // Its value in each machine
+	Diverged      bool       // Whether the measurement distributions differ
+	BasisState    BasisState // First basis state whose measurement probability differs
+	Probabilities [2]float64 // Its probability in each machine
+}
+
+// Match reports whether the registers and measurement distributions agree
+func (c Comparison) Match() bool {
+	return c.Register < 0 && !c.Diverged
+}
+
+// CompareMachines compares the classical registers and the measurement distributions of the
+// qubits (|amplitude|² per basis state, so global phases are ignored) of two machines
+func CompareMachines(a, b *QuantumRISCVMachine) (Comparison, error) {
+	if a.state.NumQubits() != b.state.NumQubits() {
+		return Comparison{}, fmt.Errorf("cannot compare machines with %d and %d qubits",
+			a.state.NumQubits(), b.state.NumQubits())
+	}
+	c := Comparison{Register: -1}
+	for i := range a.registers {
+		if a.registers[i] != b.registers[i] {
+			c.Register, c.Values = i, [2]uint64{a.registers[i], b.registers[i]}
+			break
+		}
+	}
+	for _, i := range populatedUnion(a.state, b.state) {
+		pa, pb := basisProbability(a.state.amp(i)), basisProbability(b.state.amp(i))
+		if pa-pb > distributionTolerance || pb-pa > distributionTolerance {
+			c.Diverged, c.BasisState, c.Probabilities = true, i, [2]float64{pa, pb}
+			break
+		}
+	}
+	return c, nil
+}
+
+// populatedUnion returns the basis states populated in either state, in increasing order; every
+// other basis state has probability zero in both
+func populatedUnion(a, b *QuantumState) []BasisState {
+	seen := make(map[BasisState]bool)
+	var populated []BasisState
+	collect := func(basis BasisState, _ Complex128) {
+		if !seen[basis] {
+			seen[basis] = true
+			populated = append(populated, basis)
+		}
+	}
+	a.each(collect)
+	b.each(collect)
+	sort.Slice(populated, func(i, j int) bool { return populated[i].less(populated[j]) })
+	return populated
+}
+
+// basisProbability returns |amp|²
+func basisProbability(amp Complex128) float64 {
+	return real(amp)*real(amp) + imag(amp)*imag(amp)
+}
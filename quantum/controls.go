@@ -0,0 +1,51 @@
+package quantum
+
+import "fmt"
+
+// controlWordSize is the size in bytes of one control qubit index in a qgate.mc control list
+const controlWordSize = 4
+
+// applyControlledGate applies a single-qubit gate by mnemonic to target, controlled by qubit control
+func (m *QuantumRISCVMachine) applyControlledGate(gate string, control, target int64) error {
+	opcode, ok := singleQubitGateOpcode(gate)
+	if !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", gate)
+	}
+	for _, q := range []int64{control, target} {
+		if q < 0 || q >= int64(m.state.NumQubits()) {
+			return fmt.Errorf("invalid qubit number: %d", q)
+		}
+	}
+	return m.executeInstruction(Instruction{Opcode: opcode, Target: int(target), Controls: []int{int(control)}})
+}
+
+// applyMemoryControlledGate implements qgate.mc: it reads rs2 control qubit indices, stored as
+// little-endian 32-bit words (as sw writes them) from the address in rs1, and applies the gate to
+// the target qubit where every control is |1⟩. The list is fully validated before the state is
+// touched; an empty list applies the gate unconditionally.
+func (m *QuantumRISCVMachine) applyMemoryControlledGate(inst RISCInstruction) error {
+	opcode, ok := singleQubitGateOpcode(inst.Gate)
+	if !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", inst.Gate)
+	}
+	addr, count := m.registers[inst.Rs1], m.registers[inst.Rs2]
+	if count > uint64(m.state.NumQubits()) {
+		return fmt.Errorf("qgate.mc control count %d in x%d exceeds the %d machine qubits",
+			count, inst.Rs2, m.state.NumQubits())
+	}
+	if addr > uint64(len(m.memory)) || count*controlWordSize > uint64(len(m.memory))-addr {
+		return fmt.Errorf("memory access out of bounds: %d control words at address 0x%X from x%d",
+			count, addr, inst.Rs1)
+	}
+
+	controls := make([]int, count)
+	for i := range controls {
+		word := addr + uint64(i)*controlWordSize
+		controls[i] = int(int32(uint32(m.memory[word]) | uint32(m.memory[word+1])<<8 |
+			uint32(m.memory[word+2])<<16 | uint32(m.memory[word+3])<<24))
+	}
+	if err := m.validateQubits(append([]int{int(inst.Imm)}, controls...)...); err != nil {
+		return fmt.Errorf("qgate.mc: %v", err)
+	}
+	return m.executeInstruction(Instruction{Opcode: opcode, Target: int(inst.Imm), Controls: controls})
+}
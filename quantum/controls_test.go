@@ -0,0 +1,70 @@
+package quantum
+
+import (
+	"fmt"
+	"testing"
+)
+
+// controlListProgram stores controls as 32-bit words at 0x100, sets the qubits in ones to |1⟩
+// and applies "qgate.mc X, target, x10, x11" with x11 = len(controls)
+func controlListProgram(controls, ones []int, target int) string {
+	source := "addi x10, x0, 0x100\naddi x9, x0, 1\n"
+	for i, q := range controls {
+		source += fmt.Sprintf("addi x5, x0, %d\nsw x5, %d(x10)\n", q, 4*i)
+	}
+	for _, q := range ones {
+		source += fmt.Sprintf("qgate.if x9, X, %d\n", q)
+	}
+	return source + fmt.Sprintf("addi x11, x0, %d\nqgate.mc X, %d, x10, x11\n", len(controls), target)
+}
+
+func TestQGateMC(t *testing.T) {
+	tests := []struct {
+		name     string
+		controls []int
+		ones     []int // Qubits set to |1⟩ first
+		target   int
+		want     int // Resulting basis state
+	}{
+		{"Toffoli fires", []int{0, 1}, []int{0, 1}, 2, 0b111},
+		{"Toffoli with one control clear", []int{0, 1}, []int{0}, 2, 0b001},
+		{"four controls", []int{0, 1, 2, 4}, []int{0, 1, 2, 4}, 3, 0b11111},
+		{"controls in any order", []int{4, 0}, []int{0, 4}, 1, 0b10011},
+		{"no controls", nil, nil, 3, 0b01000},
+	}
+	for _, tt := range tests {
+		m := runMachine(t, controlListProgram(tt.controls, tt.ones, tt.target), 5)
+		if p := basisProbability(m.GetState().GetAmplitudes()[tt.want]); p != 1 {
+			t.Errorf("%s: P(|%05b⟩) = %g, want 1", tt.name, tt.want, p)
+		}
+	}
+}
+
+func TestQGateMCErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"control is the target", controlListProgram([]int{0, 2}, nil, 2)},
+		{"repeated control", controlListProgram([]int{1, 1}, nil, 2)},
+		{"control out of range", controlListProgram([]int{5}, nil, 2)},
+		{"negative control", controlListProgram([]int{-1}, nil, 2)},
+		{"target out of range", controlListProgram([]int{0}, nil, 5)},
+		{"more controls than qubits", "addi x11, x0, 6\nqgate.mc X, 0, x10, x11\n"},
+		{"list past the end of memory", "lui x10, 0x100\naddi x10, x10, -4\naddi x11, x0, 2\nqgate.mc X, 0, x10, x11\n"},
+		{"not a single-qubit gate", "qgate.mc CNOT, 0, x10, x11\n"},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(5)
+		err := m.LoadRISCSource(tt.source)
+		if err == nil {
+			err = m.ExecuteRISCProgram()
+		}
+		if err == nil {
+			t.Errorf("%s: ran without an error", tt.name)
+		}
+		if len(m.GetGateHistory()) != 0 {
+			t.Errorf("%s: the rejected gate was applied", tt.name)
+		}
+	}
+}
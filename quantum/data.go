@@ -0,0 +1,136 @@
+package quantum
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DataBase is the memory address where the program's .data section is loaded
+const DataBase = 0x10000
+
+// maxDataSize bounds the .data section to what fits in the machines' 1MB memory above DataBase
+const maxDataSize = MemorySize - DataBase
+
+// dataDirectiveSizes gives the byte width of each value emitted by a data directive
+var dataDirectiveSizes = map[string]int{".byte": 1, ".half": 2, ".word": 4, ".dword": 8}
+
+// dataDirective appends the little-endian values of ".byte/.half/.word/.dword v1, v2, ..." to
+// the data section
+func (asm *assembly) dataDirective(fields []string) error {
+	size := dataDirectiveSizes[fields[0]]
+	if !asm.inData {
+		return fmt.Errorf("%s is only allowed in the .data section", fields[0])
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("%s needs at least one value", fields[0])
+	}
+	for _, text := range fields[1:] {
+		value, err := parseImmediate64(text)
+		if err != nil {
+			return fmt.Errorf("invalid %s value '%s'", fields[0], text)
+		}
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(value))
+		asm.data = append(asm.data, buf[:size]...)
+	}
+	return nil
+}
+
+// reserveDirective handles ".skip n[, fill]" / ".space n[, fill]": it reserves n bytes set to
+// fill (zero by default), e.g. an uninitialized buffer named by the label before it
+func (asm *assembly) reserveDirective(fields []string) error {
+	if !asm.inData {
+		return fmt.Errorf("%s is only allowed in the .data section", fields[0])
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return fmt.Errorf("usage: %s <bytes>[, fill]", fields[0])
+	}
+	size, err := parseImmediate64(fields[1])
+	if err != nil || size < 0 {
+		return fmt.Errorf("invalid %s size '%s'", fields[0], fields[1])
+	}
+	if int64(len(asm.data))+size > maxDataSize {
+		return fmt.Errorf("%s %d overflows the %d-byte data section", fields[0], size, maxDataSize)
+	}
+	var fill int64
+	if len(fields) == 3 {
+		if fill, err = parseImmediate64(fields[2]); err != nil {
+			return fmt.Errorf("invalid %s fill value '%s'", fields[0], fields[2])
+		}
+	}
+	for i := int64(0); i < size; i++ {
+		asm.data = append(asm.data, byte(fill))
+	}
+	return nil
+}
+
+// symbolAddress returns the address of a label: its instruction index for code labels (the PC
+// is an instruction index) or DataBase plus its offset for data labels
+func (asm *assembly) symbolAddress(name string) (int64, error) {
+	def, ok := asm.labels[name]
+	if !ok {
+		return 0, fmt.Errorf("undefined label '%s'", name)
+	}
+	if def.data {
+		return DataBase + int64(def.index), nil
+	}
+	return int64(def.index), nil
+}
+
+// relocationPattern matches the %pcrel_hi(symbol) and %pcrel_lo(label) operand forms
+var relocationPattern = regexp.MustCompile(`%pcrel_(hi|lo)\(([^)]*)\)`)
+
+// resolveRelocations replaces PC-relative relocation operands with numbers. As in the RISC-V
+// psABI, %pcrel_hi(sym) on an auipc at pc gives the upper 20 bits of sym-pc, and %pcrel_lo(L)
+// names the label of that auipc and gives the matching low 12 bits, so
+//
+//	L: auipc x5, %pcrel_hi(value)
+//	   lw    x6, %pcrel_lo(L)(x5)
+//
+// loads the word at `value` wherever the code sits.
+func (asm *assembly) resolveRelocations(text string, pc int) (string, error) {
+	var resolveErr error
+	resolved := relocationPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := relocationPattern.FindStringSubmatch(match)
+		var offset int64
+		var err error
+		if parts[1] == "hi" {
+			offset, err = asm.pcrelOffset(parts[2], pc)
+			offset = (offset + 0x800) >> 12
+		} else {
+			offset, err = asm.pcrelLoOffset(parts[2])
+			offset = signExtend(offset&0xFFF, 12)
+		}
+		if err != nil && resolveErr == nil {
+			resolveErr = err
+		}
+		return fmt.Sprint(offset)
+	})
+	return resolved, resolveErr
+}
+
+// pcrelOffset returns the distance from pc to symbol
+func (asm *assembly) pcrelOffset(symbol string, pc int) (int64, error) {
+	addr, err := asm.symbolAddress(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return addr - int64(pc), nil
+}
+
+// pcrelLoOffset finds the auipc labelled `label` and returns the full offset of its
+// %pcrel_hi symbol, measured from that auipc
+func (asm *assembly) pcrelLoOffset(label string) (int64, error) {
+	def, ok := asm.labels[label]
+	if !ok || def.data || def.index >= len(asm.lines) {
+		return 0, fmt.Errorf("%%pcrel_lo(%s): label must mark an auipc instruction", label)
+	}
+	hi := asm.lines[def.index].text
+	parts := relocationPattern.FindStringSubmatch(hi)
+	if !strings.HasPrefix(hi, "auipc") || parts == nil || parts[1] != "hi" {
+		return 0, fmt.Errorf("%%pcrel_lo(%s): '%s' is not an auipc with %%pcrel_hi", label, hi)
+	}
+	return asm.pcrelOffset(parts[2], def.index)
+}
@@ -0,0 +1,126 @@
+package quantum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDataDirectivesAndPCRelative(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   map[int]uint64
+	}{
+		{"word", `.data
+value: .word 42
+.text
+here: auipc x5, %pcrel_hi(value)
+      lw    x6, %pcrel_lo(here)(x5)
+`, map[int]uint64{6: 42}},
+		{"later code", `.data
+pad:  .byte 1, 2, 3, 4
+val:  .half 0x1234
+big:  .dword 0x1122334455667788
+.text
+      addi x1, x0, 0
+      addi x1, x0, 0
+a:    auipc x5, %pcrel_hi(val)
+      lhu   x6, %pcrel_lo(a)(x5)
+b:    auipc x7, %pcrel_hi(big)
+      lw    x8, %pcrel_lo(b)(x7)
+      lbu   x9, %pcrel_lo(a)(x5)
+`, map[int]uint64{6: 0x1234, 8: 0x55667788, 9: 0x34}},
+		{"negative byte", ".data\nb: .byte -1\n.text\nx: auipc x5, %pcrel_hi(b)\nlb x6, %pcrel_lo(x)(x5)\n",
+			map[int]uint64{6: 0xFFFFFFFFFFFFFFFF}},
+		{"data labels are addresses", ".data\n.word 7\nsecond: .word 8\n.text\nh: auipc x5, %pcrel_hi(second)\n" +
+			"addi x5, x5, %pcrel_lo(h)\nlw x6, 0(x5)\n", map[int]uint64{5: DataBase + 4, 6: 8}},
+	}
+	for _, tt := range tests {
+		regs := runSource(t, tt.source, DefaultXLEN)
+		for reg, want := range tt.want {
+			if regs[reg] != want {
+				t.Errorf("%s: x%d = %#x, want %#x", tt.name, reg, regs[reg], want)
+			}
+		}
+	}
+}
+
+func TestDataErrors(t *testing.T) {
+	for _, source := range []string{
+		".word 1\n",                                           // Data directive outside .data
+		".data\nv: .word\n",                                   // No values
+		".data\nv: .word banana\n",                            // Bad value
+		"here: auipc x5, %pcrel_hi(missing)\n",                // Undefined symbol
+		"lw x6, %pcrel_lo(nowhere)(x5)\n",                     // %pcrel_lo of an undefined label
+		".data\nv: .word 1\n.text\nlw x6, %pcrel_lo(v)(x5)\n", // Not the label of an auipc
+	} {
+		if _, err := RunProgram(source, Options{}); err == nil {
+			t.Errorf("%q assembled, want an error", source)
+		}
+	}
+}
+
+func TestHostLoadsData(t *testing.T) {
+	source := strings.Join([]string{".data", "v: .word 99", ".text", "h: auipc x5, %pcrel_hi(v)",
+		"lw x6, %pcrel_lo(h)(x5)"}, "\n")
+	vm := NewQuantumRISCVMachine(1)
+	if err := vm.LoadRISCSource(source); err != nil {
+		t.Fatal(err)
+	}
+	host := NewHostQuantumMachine(1)
+	if err := host.LoadData(vm.DataSegment()); err != nil {
+		t.Fatal(err)
+	}
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+	if err := host.ExecuteProgram(); err != nil {
+		t.Fatal(err)
+	}
+	if got := host.GetRegister(6); got != 99 {
+		t.Errorf("host x6 = %d, want 99", got)
+	}
+}
+
+func TestReserveDirectives(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []byte
+	}{
+		{"skip", ".data\nbuf: .skip 4\n", []byte{0, 0, 0, 0}},
+		{"space with fill", ".data\nbuf: .space 3, 0xAB\n", []byte{0xAB, 0xAB, 0xAB}},
+		{"zero bytes", ".data\nbuf: .skip 0\n", nil},
+		{"between values", ".data\n.byte 1\n.space 2\n.byte 2\n", []byte{1, 0, 0, 2}},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(1)
+		if err := m.LoadRISCSource(tt.source); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if _, data := m.DataSegment(); string(data) != string(tt.want) {
+			t.Errorf("%s: data = %v, want %v", tt.name, data, tt.want)
+		}
+	}
+
+	// Labels after a reservation point past it
+	regs := runSource(t, ".data\nbuf: .skip 8\nv: .word 5\n.text\nh: auipc x5, %pcrel_hi(v)\n"+
+		"lw x6, %pcrel_lo(h)(x5)\n", DefaultXLEN)
+	if regs[6] != 5 {
+		t.Errorf("word after .skip 8: x6 = %d, want 5", regs[6])
+	}
+}
+
+func TestReserveDirectiveErrors(t *testing.T) {
+	for _, source := range []string{
+		".skip 4\n",                    // Outside .data
+		".data\nbuf: .skip\n",          // No size
+		".data\nbuf: .skip -1\n",       // Negative size
+		".data\nbuf: .space 4, 0, 1\n", // Too many operands
+		".data\nbuf: .space 4, bad\n",  // Bad fill
+		".data\nbuf: .skip 0x100000\n", // Larger than memory
+	} {
+		if _, err := RunProgram(source, Options{}); err == nil {
+			t.Errorf("%q assembled, want an error", source)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package quantum
+
+import "strings"
+
+// opDoc is the human-readable documentation of one instruction or gate
+type opDoc struct {
+	description string // One-line semantics
+	example     string // A complete, valid use
+}
+
+// opDocs documents every entry SupportedOps lists, keyed by mnemonic
+var opDocs = map[string]opDoc{
+	// Quantum extensions
+	"qinit":     {"Initialize quantum register rd to |0⟩", "qinit x1"},
+	"qapply":    {"Apply the gate with opcode imm (0=X 1=Y 2=Z 3=H 4=S 5=T 8=I) to register rs1", "qapply x1, x1, 3"},
+	"qmeasure":  {"Measure quantum register rs1, collapsing its state, and write the 0/1 outcome to rd", "qmeasure x2, x1"},
+	"qentangle": {"Entangle registers rs1 and rs2 into rd: tensor product, then H and CNOT", "qentangle x3, x1, x2"},
+	"qmov":      {"Move the quantum register in rs1 to rd; rs1 is invalidated (no-cloning)", "qmov x2, x1"},
+	"qgate.if":  {"Apply single-qubit GATE to machine qubit target only when rs != 0", "qgate.if x5, X, 2"},
+	"qgate.r":   {"Apply single-qubit GATE to the machine qubit whose index is in rs", "qgate.r H, x5"},
+	"qgate.c":   {"Apply single-qubit GATE to machine qubit target, controlled by qubit control", "qgate.c X, 0, 1"},
+	"qgate.mc": {"Apply GATE to qubit target controlled by the rs2 qubit indices (32-bit words) at address rs1",
+		"qgate.mc X, 3, x5, x6"},
+	"qmeas.mem": {"Measure machine qubit `qubit` and store the 0/1 outcome as a byte at the address in rs",
+		"qmeas.mem 0, x10"},
+
+	// Register-register arithmetic and logic
+	"add":  {"rd = rs1 + rs2 (wraps modulo 2^XLEN)", "add x3, x1, x2"},
+	"sub":  {"rd = rs1 - rs2 (wraps modulo 2^XLEN)", "sub x3, x1, x2"},
+	"and":  {"rd = rs1 & rs2", "and x3, x1, x2"},
+	"or":   {"rd = rs1 | rs2", "or x3, x1, x2"},
+	"xor":  {"rd = rs1 ^ rs2", "xor x3, x1, x2"},
+	"sll":  {"rd = rs1 << rs2 (shift amount taken modulo XLEN)", "sll x3, x1, x2"},
+	"srl":  {"rd = rs1 >> rs2, shifting in zeros", "srl x3, x1, x2"},
+	"sra":  {"rd = rs1 >> rs2, shifting in copies of the sign bit", "sra x3, x1, x2"},
+	"slt":  {"rd = 1 if rs1 < rs2 as signed values, else 0", "slt x3, x1, x2"},
+	"sltu": {"rd = 1 if rs1 < rs2 as unsigned values, else 0", "sltu x3, x1, x2"},
+
+	// M extension: multiply and divide
+	"mul":    {"rd = low XLEN bits of rs1 * rs2", "mul x3, x1, x2"},
+	"mulh":   {"rd = high XLEN bits of rs1 * rs2, both signed", "mulh x3, x1, x2"},
+	"mulhu":  {"rd = high XLEN bits of rs1 * rs2, both unsigned", "mulhu x3, x1, x2"},
+	"mulhsu": {"rd = high XLEN bits of signed rs1 * unsigned rs2", "mulhsu x3, x1, x2"},
+	"div":    {"rd = rs1 / rs2 as signed values, rounding toward zero (-1 if rs2 is 0)", "div x3, x1, x2"},
+	"divu":   {"rd = rs1 / rs2 as unsigned values (all ones if rs2 is 0)", "divu x3, x1, x2"},
+	"rem":    {"rd = signed remainder of rs1 / rs2, with the sign of rs1 (rs1 if rs2 is 0)", "rem x3, x1, x2"},
+	"remu":   {"rd = unsigned remainder of rs1 / rs2 (rs1 if rs2 is 0)", "remu x3, x1, x2"},
+
+	// Register-immediate arithmetic and logic
+	"addi":  {"rd = rs1 + imm", "addi x1, x0, 42"},
+	"slli":  {"rd = rs1 << imm", "slli x1, x1, 4"},
+	"srli":  {"rd = rs1 >> imm, shifting in zeros", "srli x1, x1, 4"},
+	"srai":  {"rd = rs1 >> imm, shifting in copies of the sign bit", "srai x1, x1, 4"},
+	"andi":  {"rd = rs1 & imm", "andi x1, x1, 255"},
+	"ori":   {"rd = rs1 | imm", "ori x1, x1, 1"},
+	"xori":  {"rd = rs1 ^ imm (xori rd, rs1, -1 is bitwise NOT)", "xori x1, x1, -1"},
+	"slti":  {"rd = 1 if rs1 < imm as signed values, else 0", "slti x2, x1, 10"},
+	"sltiu": {"rd = 1 if rs1 < imm as unsigned values, else 0", "sltiu x2, x1, 10"},
+
+	// Upper immediates and jumps
+	"lui":   {"rd = imm << 12", "lui x1, 74565"},
+	"auipc": {"rd = PC + (imm << 12)", "auipc x5, 0"},
+	"jal":   {"rd = return address (PC + 1, the next instruction), then jump by offset (or to a label)", "jal x1, loop"},
+	"jalr":  {"rd = return address (PC + 1, the next instruction), then jump to rs1 + offset", "jalr x0, x1, 0"},
+
+	// Branches
+	"beq":  {"Branch by offset (or to a label) if rs1 == rs2", "beq x1, x2, done"},
+	"bne":  {"Branch by offset (or to a label) if rs1 != rs2", "bne x1, x0, loop"},
+	"blt":  {"Branch by offset (or to a label) if rs1 < rs2 as signed values", "blt x1, x2, loop"},
+	"bge":  {"Branch by offset (or to a label) if rs1 >= rs2 as signed values", "bge x1, x2, done"},
+	"bltu": {"Branch by offset (or to a label) if rs1 < rs2 as unsigned values", "bltu x1, x2, loop"},
+	"bgeu": {"Branch by offset (or to a label) if rs1 >= rs2 as unsigned values", "bgeu x1, x2, done"},
+
+	// Loads and stores
+	"lw":  {"rd = sign-extended 32-bit word at rs1 + offset", "lw x2, 0(x1)"},
+	"lh":  {"rd = sign-extended 16-bit halfword at rs1 + offset", "lh x2, 0(x1)"},
+	"lb":  {"rd = sign-extended byte at rs1 + offset", "lb x2, 0(x1)"},
+	"lwu": {"rd = zero-extended 32-bit word at rs1 + offset", "lwu x2, 0(x1)"},
+	"lhu": {"rd = zero-extended 16-bit halfword at rs1 + offset", "lhu x2, 0(x1)"},
+	"lbu": {"rd = zero-extended byte at rs1 + offset", "lbu x2, 0(x1)"},
+	"sw":  {"Store the low 32 bits of rs2 at rs1 + offset", "sw x2, 0(x1)"},
+	"sh":  {"Store the low 16 bits of rs2 at rs1 + offset", "sh x2, 0(x1)"},
+	"sb":  {"Store the low byte of rs2 at rs1 + offset", "sb x2, 0(x1)"},
+
+	// System calls
+	"ecall":  {"System call selected by a7 with argument a0: 1 print int, 4 print string, 11 print char, 10 exit", "ecall"},
+	"ebreak": {"Breakpoint: halts the program", "ebreak"},
+
+	// Pseudo-instructions
+	"li": {"Load any XLEN-bit constant into rd (expands to lui/addi/slli)", "li x1, 0x12345678"},
+
+	// REPL gates
+	"X":    {"Pauli-X (NOT): swaps |0⟩ and |1⟩", "gate X 0"},
+	"Y":    {"Pauli-Y: bit and phase flip, |0⟩ → i|1⟩, |1⟩ → -i|0⟩", "gate Y 0"},
+	"Z":    {"Pauli-Z: flips the phase of |1⟩", "gate Z 0"},
+	"H":    {"Hadamard: maps |0⟩ to (|0⟩+|1⟩)/√2, creating superposition", "gate H 0"},
+	"S":    {"Phase gate: multiplies |1⟩ by i", "gate S 0"},
+	"T":    {"π/8 gate: multiplies |1⟩ by e^(iπ/4)", "gate T 0"},
+	"CNOT": {"Controlled NOT: flips target when the control qubit is |1⟩", "gate CNOT 1 0"},
+	"SDG":  {"Inverse phase gate S†: multiplies |1⟩ by -i, undoing S", "gate SDG 0"},
+	"TDG":  {"Inverse π/8 gate T†: multiplies |1⟩ by e^(-iπ/4), undoing T", "gate TDG 0"},
+	"I":    {"Identity: leaves the state unchanged (a wait/idle slot)", "gate I 0"},
+	"RX":   {"Rotation by theta radians about the X axis, e^(-iθX/2)", "gate RX 0 1.5708"},
+	"RY":   {"Rotation by theta radians about the Y axis, e^(-iθY/2)", "gate RY 0 1.5708"},
+	"RZ":   {"Rotation by theta radians about the Z axis, e^(-iθZ/2)", "gate RZ 0 0.7854"},
+	"P":    {"Phase shift by theta radians: multiplies |1⟩ by e^(iθ)", "gate P 0 0.7854"},
+	"SWAP": {"Exchange the states of two qubits", "gate SWAP 0 1"},
+	"CCNOT": {"Toffoli (controlled-controlled NOT): flips target when both controls are |1⟩",
+		"gate CCNOT 2 0 1"},
+}
+
+// DescribeOp returns the documentation of an instruction or gate. Instruction mnemonics match
+// case-insensitively, so "ADDI" and "h" are found too.
+func DescribeOp(name string) (OpInfo, bool) {
+	for _, op := range SupportedOps() {
+		if strings.EqualFold(op.Name, name) {
+			return op, true
+		}
+	}
+	return OpInfo{}, false
+}
@@ -0,0 +1,59 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String renders inst as canonical assembly that parses back to the same instruction: registers
+// as xN, labels as the numeric offsets they resolved to, and pseudo-instructions as their
+// expansion. Custom instructions, whose operand layout the parser does not record, keep their
+// source text.
+func (inst RISCInstruction) String() string {
+	spec, ok := instructionTable[inst.Opcode]
+	if !ok {
+		if inst.Source != "" {
+			return inst.Source
+		}
+		return inst.Opcode
+	}
+	if spec.format.operands == 0 {
+		return inst.Opcode
+	}
+	slots := strings.Split(spec.format.signature, ", ")
+	operands := make([]string, len(slots))
+	for i, slot := range slots {
+		operands[i] = inst.operand(slot)
+	}
+	return inst.Opcode + " " + strings.Join(operands, ", ")
+}
+
+// operand renders the field behind one slot of a format signature
+func (inst RISCInstruction) operand(slot string) string {
+	switch slot {
+	case "rd":
+		return fmt.Sprintf("x%d", inst.Rd)
+	case "rs1", "rs":
+		return fmt.Sprintf("x%d", inst.Rs1)
+	case "rs2":
+		return fmt.Sprintf("x%d", inst.Rs2)
+	case "imm", "target", "qubit":
+		return fmt.Sprint(inst.Imm)
+	case "offset", "control":
+		return fmt.Sprint(inst.Offset)
+	case "offset(rs1)":
+		return fmt.Sprintf("%d(x%d)", inst.Offset, inst.Rs1)
+	case "GATE":
+		return inst.Gate
+	}
+	return slot
+}
+
+// Disassemble renders the loaded program as canonical assembly, one instruction per PC index
+func (m *QuantumRISCVMachine) Disassemble() []string {
+	lines := make([]string, len(m.riscProgram))
+	for pc, inst := range m.riscProgram {
+		lines[pc] = inst.String()
+	}
+	return lines
+}
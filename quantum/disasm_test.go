@@ -0,0 +1,86 @@
+package quantum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstructionString(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{"addi a0, zero, -5", []string{"addi x10, x0, -5"}},
+		{"add x1, x2, x3", []string{"add x1, x2, x3"}},
+		{"lw t0, 8(sp)", []string{"lw x5, 8(x2)"}},
+		{"sw t0, -4(sp)", []string{"sw x5, -4(x2)"}},
+		{"lui x5, 0x12345", []string{"lui x5, 74565"}},
+		{"start:\nbeq x1, x2, start", []string{"beq x1, x2, 0"}},
+		{"jal ra, end\necall\nend:\nebreak", []string{"jal x1, 2", "ecall", "ebreak"}},
+		{"jalr x0, ra, 0", []string{"jalr x0, x1, 0"}},
+		{"li x6, 100", []string{"addi x6, x0, 100"}},
+		{"li x6, 0x12345678", []string{"lui x6, 74565", "addi x6, x6, 1656"}},
+		{"qinit x1", []string{"qinit x1"}},
+		{"qapply x1, x2, 3", []string{"qapply x1, x2, 3"}},
+		{"qmeasure x7, x1", []string{"qmeasure x7, x1"}},
+		{"qmov a1, a2", []string{"qmov x11, x12"}},
+		{"qentangle x1, x2, x3", []string{"qentangle x1, x2, x3"}},
+		{"qgate.r H, t0", []string{"qgate.r H, x5"}},
+		{"qgate.if a0, X, 1", []string{"qgate.if x10, X, 1"}},
+		{"qgate.c H, 0, 1", []string{"qgate.c H, 0, 1"}},
+		{"qgate.mc X, 2, x5, x6", []string{"qgate.mc X, 2, x5, x6"}},
+		{"qmeas.mem 1, x5", []string{"qmeas.mem 1, x5"}},
+		// Custom instructions keep their source text
+		{"test.madd a0, a1, 0x10", []string{"test.madd a0, a1, 0x10"}},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		if err := m.LoadRISCSource(tt.source + "\n"); err != nil {
+			t.Fatalf("%q: %v", tt.source, err)
+		}
+		if got := m.Disassemble(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%q disassembles to %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	programs := []string{
+		"li a0, 10\nli a1, 0\nloop:\nadd a1, a1, a0\naddi a0, a0, -1\nbne a0, zero, loop\necall\n",
+		"addi sp, sp, -8\nsw ra, 4(sp)\njal ra, f\nlw ra, 4(sp)\naddi sp, sp, 8\nebreak\nf:\njalr x0, ra, 0\n",
+		"li t0, -123456789\nslli t1, t0, 3\nsrai t2, t1, 2\nmul a0, t1, t2\nrem a1, a0, t0\n",
+		"qinit x1\nqapply x1, x1, 0\nqmeasure x5, x1\nqgate.if x5, X, 0\nqgate.c H, 1, 0\nqmeas.mem 0, x6\n",
+	}
+	for _, source := range programs {
+		m := NewQuantumRISCVMachine(2)
+		if err := m.LoadRISCSource(source); err != nil {
+			t.Fatalf("%q: %v", source, err)
+		}
+		again := NewQuantumRISCVMachine(2)
+		if err := again.LoadRISCSource(strings.Join(m.Disassemble(), "\n") + "\n"); err != nil {
+			t.Fatalf("reassembling %q: %v", source, err)
+		}
+		compareInstructions(t, source, m.GetRISCProgram(), again.GetRISCProgram())
+	}
+
+	if lines := NewQuantumRISCVMachine(1).Disassemble(); len(lines) != 0 {
+		t.Errorf("machine without a program disassembles to %q", lines)
+	}
+}
+
+// compareInstructions reports every instruction of got that decodes differently from want,
+// ignoring where each was written in its source
+func compareInstructions(t *testing.T, name string, want, got []RISCInstruction) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: %d instructions after the round trip, want %d", name, len(got), len(want))
+		return
+	}
+	for pc := range want {
+		w, g := want[pc], got[pc]
+		w.Source, w.LineNumber, g.Source, g.LineNumber = "", 0, "", 0
+		if g != w {
+			t.Errorf("%s: PC %d is %+v after the round trip, want %+v", name, pc, g, w)
+		}
+	}
+}
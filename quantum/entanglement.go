@@ -0,0 +1,191 @@
+package quantum
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// MaxEntanglementMapQubits caps EntanglementMap: it builds a reduced density matrix for every
+// qubit pair, O(n²·2ⁿ) work in total
+const MaxEntanglementMapQubits = 16
+
+// ReducedDensityMatrix traces out every qubit except `qubits` and returns the 2^k x 2^k density
+// matrix of the rest. Bit j of a row or column index is the value of qubits[j].
+func (qs *QuantumState) ReducedDensityMatrix(qubits ...int) ([][]Complex128, error) {
+	for i, q := range qubits {
+		if err := qs.checkQubit(q); err != nil {
+			return nil, err
+		}
+		for _, other := range qubits[:i] {
+			if other == q {
+				return nil, fmt.Errorf("qubit %d used more than once", q)
+			}
+		}
+	}
+	norm := qs.Norm()
+	if !(norm > 0) {
+		return nil, fmt.Errorf("state has zero norm")
+	}
+
+	dim := 1 << len(qubits)
+	rho := make([][]Complex128, dim)
+	for r := range rho {
+		rho[r] = make([]Complex128, dim)
+	}
+	qs.each(func(b BasisState, amp Complex128) {
+		row, rest := splitBasis(b, qubits)
+		// ρ[row][col] sums amp(row, rest)·conj(amp(col, rest)) over the traced-out bits `rest`
+		for col := 0; col < dim; col++ {
+			partner := joinBasis(col, rest, qubits)
+			rho[row][col] += amp * cmplx.Conj(qs.amp(partner)) / complex(norm, 0)
+		}
+	})
+	return rho, nil
+}
+
+// splitBasis separates basis state b into the bits of `qubits` (packed, qubits[j] -> bit j)
+// and the remaining bits (left in place with the kept bits cleared)
+func splitBasis(b BasisState, qubits []int) (int, BasisState) {
+	sub, rest := 0, b
+	for j, q := range qubits {
+		sub |= b.Bit(q) << j
+		rest = rest.withBit(q, 0)
+	}
+	return sub, rest
+}
+
+// joinBasis is the inverse of splitBasis
+func joinBasis(sub int, rest BasisState, qubits []int) BasisState {
+	for j, q := range qubits {
+		rest = rest.withBit(q, (sub>>j)&1)
+	}
+	return rest
+}
+
+// VonNeumannEntropy returns S(ρ) = -Σ λ log₂ λ in bits over the eigenvalues λ of a density matrix
+func VonNeumannEntropy(rho [][]Complex128) float64 {
+	entropy := 0.0
+	for _, lambda := range hermitianEigenvalues(rho) {
+		if lambda > 1e-12 {
+			entropy -= lambda * math.Log2(lambda)
+		}
+	}
+	return entropy
+}
+
+// MutualInformation returns I(a:b) = S(a) + S(b) - S(ab) in bits: 0 for uncorrelated qubits and
+// 2 for a maximally entangled pair. In a pure state of two qubits it equals twice their
+// entanglement entropy; with more qubits it counts classical correlations too.
+func (qs *QuantumState) MutualInformation(a, b int) (float64, error) {
+	rhoA, err := qs.ReducedDensityMatrix(a)
+	if err != nil {
+		return 0, err
+	}
+	rhoB, err := qs.ReducedDensityMatrix(b)
+	if err != nil {
+		return 0, err
+	}
+	rhoAB, err := qs.ReducedDensityMatrix(a, b)
+	if err != nil {
+		return 0, err
+	}
+	info := VonNeumannEntropy(rhoA) + VonNeumannEntropy(rhoB) - VonNeumannEntropy(rhoAB)
+	return math.Max(info, 0), nil // Clamp rounding noise below zero
+}
+
+// EntanglementMap returns the symmetric matrix of pairwise mutual information between all
+// qubits (the diagonal is zero)
+func (qs *QuantumState) EntanglementMap() ([][]float64, error) {
+	n := qs.numQubits
+	if n > MaxEntanglementMapQubits {
+		return nil, fmt.Errorf("entanglement map is limited to %d qubits, state has %d", MaxEntanglementMapQubits, n)
+	}
+	info := make([][]float64, n)
+	for a := range info {
+		info[a] = make([]float64, n)
+	}
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			value, err := qs.MutualInformation(a, b)
+			if err != nil {
+				return nil, err
+			}
+			info[a][b], info[b][a] = value, value
+		}
+	}
+	return info, nil
+}
+
+// hermitianEigenvalues returns the eigenvalues of a Hermitian matrix H = A + iB. The real
+// symmetric matrix [[A, -B], [B, A]] has each eigenvalue of H twice, so its eigenvalues are
+// computed with Jacobi rotations and every other one is kept.
+func hermitianEigenvalues(h [][]Complex128) []float64 {
+	n := len(h)
+	m := make([][]float64, 2*n)
+	for i := range m {
+		m[i] = make([]float64, 2*n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			a, b := real(h[i][j]), imag(h[i][j])
+			m[i][j], m[i][j+n] = a, -b
+			m[i+n][j], m[i+n][j+n] = b, a
+		}
+	}
+
+	doubled := symmetricEigenvalues(m)
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = doubled[2*i]
+	}
+	return values
+}
+
+// symmetricEigenvalues diagonalizes a real symmetric matrix in place with cyclic Jacobi
+// rotations and returns its eigenvalues in ascending order
+func symmetricEigenvalues(m [][]float64) []float64 {
+	n := len(m)
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += m[p][q] * m[p][q]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] != 0 {
+					jacobiRotate(m, p, q)
+				}
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// jacobiRotate applies the rotation that zeroes m[p][q] (and m[q][p])
+func jacobiRotate(m [][]float64, p, q int) {
+	theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+	t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+	c := 1 / math.Sqrt(t*t+1)
+	s := t * c
+	for k := range m {
+		mkp, mkq := m[k][p], m[k][q]
+		m[k][p], m[k][q] = c*mkp-s*mkq, s*mkp+c*mkq
+	}
+	for k := range m {
+		mpk, mqk := m[p][k], m[q][k]
+		m[p][k], m[q][k] = c*mpk-s*mqk, s*mpk+c*mqk
+	}
+}
@@ -0,0 +1,130 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReducedDensityMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		qubits  int
+		prepare func(qs *QuantumState)
+		keep    []int
+		want    [][]Complex128
+	}{
+		{"ground", 2, func(qs *QuantumState) {}, []int{0}, [][]Complex128{{1, 0}, {0, 0}}},
+		{"basis one", 2, func(qs *QuantumState) { X.Apply(qs, 1, nil) }, []int{1}, [][]Complex128{{0, 0}, {0, 1}}},
+		{"plus", 2, func(qs *QuantumState) { H.Apply(qs, 0, nil) }, []int{0},
+			[][]Complex128{{0.5, 0.5}, {0.5, 0.5}}},
+		{"plus with phase", 1, func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			S.Apply(qs, 0, nil)
+		}, []int{0}, [][]Complex128{{0.5, -0.5i}, {0.5i, 0.5}}},
+		{"bell half", 2, func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 1, []int{0})
+		}, []int{1}, [][]Complex128{{0.5, 0}, {0, 0.5}}},
+		{"bell whole", 2, func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 1, []int{0})
+		}, []int{0, 1}, [][]Complex128{{0.5, 0, 0, 0.5}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0.5, 0, 0, 0.5}}},
+		// Bit j of the index is keep[j], so reversing the qubits swaps rows and columns 1 and 2
+		{"qubit order", 3, func(qs *QuantumState) { X.Apply(qs, 2, nil) }, []int{2, 0},
+			[][]Complex128{{0, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}},
+		{"unnormalized", 1, func(qs *QuantumState) {
+			qs.SetAmplitude(0, 3)
+			qs.SetAmplitude(1, 4)
+		}, []int{0}, [][]Complex128{{9.0 / 25, 12.0 / 25}, {12.0 / 25, 16.0 / 25}}},
+		{"traced partner", 3, func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 2, []int{0})
+			H.Apply(qs, 1, nil)
+		}, []int{1}, [][]Complex128{{0.5, 0.5}, {0.5, 0.5}}},
+	}
+	for _, tt := range tests {
+		for _, backend := range []string{BackendDense, BackendSparse} {
+			qs := NewQuantumState(tt.qubits)
+			if err := qs.SetBackend(backend); err != nil {
+				t.Fatal(err)
+			}
+			tt.prepare(qs)
+			rho, err := qs.ReducedDensityMatrix(tt.keep...)
+			if err != nil {
+				t.Fatalf("%s (%s): %v", tt.name, backend, err)
+			}
+			if len(rho) != len(tt.want) {
+				t.Fatalf("%s (%s): %d rows, want %d", tt.name, backend, len(rho), len(tt.want))
+			}
+			for r := range tt.want {
+				for c := range tt.want[r] {
+					if !approxEqual(rho[r][c], tt.want[r][c]) {
+						t.Errorf("%s (%s): rho[%d][%d] = %v, want %v", tt.name, backend, r, c, rho[r][c], tt.want[r][c])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestReducedDensityMatrixErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *QuantumState
+		keep  []int
+	}{
+		{"qubit out of range", NewQuantumState(2), []int{2}},
+		{"negative qubit", NewQuantumState(2), []int{-1}},
+		{"repeated qubit", NewQuantumState(3), []int{1, 0, 1}},
+		{"zero norm", NewUninitializedQuantumState(2), []int{0}},
+	}
+	for _, tt := range tests {
+		if rho, err := tt.state.ReducedDensityMatrix(tt.keep...); err == nil {
+			t.Errorf("%s: got %v, want an error", tt.name, rho)
+		}
+	}
+}
+
+func TestMutualInformation(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(qs *QuantumState)
+		a, b    int
+		want    float64
+	}{
+		{"product", func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			H.Apply(qs, 1, nil)
+		}, 0, 1, 0},
+		{"bell", func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 1, []int{0})
+		}, 0, 1, 2},
+		// In a GHZ state any two qubits share one bit of classical correlation
+		{"ghz pair", func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 1, []int{0})
+			CNOT.Apply(qs, 2, []int{0})
+		}, 0, 2, 1},
+		{"bell and spectator", func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 1, []int{0})
+			H.Apply(qs, 2, nil)
+		}, 1, 2, 0},
+	}
+	for _, tt := range tests {
+		qs := NewQuantumState(3)
+		tt.prepare(qs)
+		info, err := qs.MutualInformation(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if math.Abs(info-tt.want) > 1e-6 {
+			t.Errorf("%s: I(%d:%d) = %g, want %g", tt.name, tt.a, tt.b, info, tt.want)
+		}
+	}
+
+	if _, err := NewQuantumState(MaxEntanglementMapQubits + 1).EntanglementMap(); err == nil {
+		t.Errorf("entanglement map of %d qubits succeeded", MaxEntanglementMapQubits+1)
+	}
+}
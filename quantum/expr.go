@@ -0,0 +1,189 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// exprParser is a recursive-descent evaluator for classical expressions such as "x1 * 2 + x2".
+// Grammar:
+//
+//	expr    := term (('+' | '-') term)*
+//	term    := unary ('*' unary)*
+//	unary   := '-' unary | primary
+//	primary := register | integer | '(' expr ')'
+//
+// Arithmetic wraps modulo 2^64 like the ALU; the caller masks the result to XLEN.
+type exprParser struct {
+	tokens []string
+	pos    int
+	reg    func(uint8) uint64 // Reads a register value
+}
+
+// EvaluateExpression computes expr over register values supplied by reg
+func EvaluateExpression(expr string, reg func(uint8) uint64) (uint64, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, fmt.Errorf("empty expression")
+	}
+
+	p := &exprParser{tokens: tokens, reg: reg}
+	value, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("unexpected '%s' after complete expression", p.tokens[p.pos])
+	}
+	return value, nil
+}
+
+// tokenizeExpression splits an expression into operators, parentheses and operand words
+func tokenizeExpression(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("+-*()", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			start := i
+			for i < len(expr) && (expr[i] == '_' || unicode.IsLetter(rune(expr[i])) || unicode.IsDigit(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, expr[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i+1)
+		}
+	}
+	return tokens, nil
+}
+
+// peek returns the next token without consuming it ("" at the end)
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// expr parses a sum or difference of terms
+func (p *exprParser) expr() (uint64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for op := p.peek(); op == "+" || op == "-"; op = p.peek() {
+		p.pos++
+		rhs, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			value += rhs
+		} else {
+			value -= rhs
+		}
+	}
+	return value, nil
+}
+
+// term parses a product of unary operands
+func (p *exprParser) term() (uint64, error) {
+	value, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == "*" {
+		p.pos++
+		rhs, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		value *= rhs
+	}
+	return value, nil
+}
+
+// unary parses an optionally negated primary
+func (p *exprParser) unary() (uint64, error) {
+	if p.peek() == "-" {
+		p.pos++
+		value, err := p.unary()
+		return -value, err
+	}
+	return p.primary()
+}
+
+// primary parses a register, an integer literal or a parenthesized expression
+func (p *exprParser) primary() (uint64, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return 0, fmt.Errorf("unexpected end of expression")
+	case tok == "(":
+		p.pos++
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ")" {
+			return 0, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return value, nil
+	case unicode.IsDigit(rune(tok[0])):
+		p.pos++
+		value, err := parseImmediate64(tok)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer literal '%s'", tok)
+		}
+		return uint64(value), nil
+	case tok == "+" || tok == "-" || tok == "*" || tok == ")":
+		return 0, fmt.Errorf("unexpected '%s', expected a register, integer or '('", tok)
+	default:
+		p.pos++
+		reg, err := parseRegister(tok)
+		if err != nil {
+			return 0, err
+		}
+		return p.reg(reg), nil
+	}
+}
+
+// Calc evaluates expr over the current registers and writes the result, wrapped to XLEN, into
+// dest using the li expansion (lui/addi/slli) a RISC-V assembler would synthesize. It returns
+// the stored value and the executed instruction sequence.
+func (m *QuantumRISCVMachine) Calc(dest, expr string) (uint64, []string, error) {
+	rd, err := parseRegister(dest)
+	if err != nil {
+		return 0, nil, err
+	}
+	if rd == 0 {
+		return 0, nil, fmt.Errorf("x0 is hardwired to zero and cannot be a calc destination")
+	}
+
+	value, err := EvaluateExpression(expr, func(r uint8) uint64 { return m.registers[r] })
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid expression: %v", err)
+	}
+
+	sequence := materializeConstant(rd, signedXLEN(value, m.xlen))
+	for _, text := range sequence {
+		inst, err := parseRISCInstruction(text)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := m.executeRISCInstruction(inst); err != nil {
+			return 0, nil, err
+		}
+	}
+	return m.registers[rd], sequence, nil
+}
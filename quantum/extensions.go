@@ -0,0 +1,69 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstructionHandler executes a custom instruction added with RegisterInstruction
+type InstructionHandler func(m *QuantumRISCVMachine, inst RISCInstruction) error
+
+// maxCustomOperands is how many operands a custom instruction may take
+const maxCustomOperands = 3
+
+// customInstructions holds the instructions registered by library users, keyed by opcode
+var customInstructions = map[string]InstructionHandler{}
+
+// RegisterInstruction adds a custom instruction to the VM's ISA without modifying the executor.
+// Its operands are parsed generically: up to three comma-separated operands, where registers
+// fill Rd, Rs1 and Rs2 in order and one integer literal sets Imm (e.g. "myop x1, x2, 5").
+// The handler runs whenever the instruction executes, after which the machine advances to the
+// next instruction; it changes the machine through the same API as any caller (SetRegister,
+// StoreMemory, ExecuteInstruction, ...). Built-in instructions cannot be replaced, and registration is not
+// synchronized, so register custom instructions before running programs.
+func RegisterInstruction(opcode string, handler func(m *QuantumRISCVMachine, inst RISCInstruction) error) error {
+	if opcode == "" || strings.ContainsAny(opcode, " \t,()#") {
+		return fmt.Errorf("invalid instruction name '%s'", opcode)
+	}
+	if _, builtin := instructionTable[opcode]; builtin {
+		return fmt.Errorf("instruction %s is built in and cannot be replaced", opcode)
+	}
+	if _, pseudo := pseudoTable[opcode]; pseudo {
+		return fmt.Errorf("%s is a pseudo-instruction and cannot be replaced", opcode)
+	}
+	if _, taken := customInstructions[opcode]; taken {
+		return fmt.Errorf("instruction %s is already registered", opcode)
+	}
+	if handler == nil {
+		return fmt.Errorf("instruction %s needs a handler", opcode)
+	}
+	customInstructions[opcode] = handler
+	return nil
+}
+
+// parseCustomOperands fills inst from the generic operands of a registered instruction
+func parseCustomOperands(inst *RISCInstruction, ops []string) error {
+	if len(ops) > maxCustomOperands {
+		return fmt.Errorf("invalid number of arguments for %s (at most %d)", inst.Opcode, maxCustomOperands)
+	}
+	registers := []*uint8{&inst.Rd, &inst.Rs1, &inst.Rs2}
+	haveImm := false
+	for _, op := range ops {
+		if reg, err := parseRegister(op); err == nil {
+			if len(registers) == 0 {
+				return fmt.Errorf("too many register operands for %s", inst.Opcode)
+			}
+			*registers[0], registers = reg, registers[1:]
+			continue
+		}
+		imm, err := parseImmediate(op)
+		if err != nil {
+			return fmt.Errorf("invalid operand %s: expected a register or an integer", strings.TrimRight(op, ","))
+		}
+		if haveImm {
+			return fmt.Errorf("%s takes at most one immediate operand", inst.Opcode)
+		}
+		inst.Imm, haveImm = imm, true
+	}
+	return nil
+}
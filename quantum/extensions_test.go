@@ -0,0 +1,105 @@
+package quantum_test
+
+import (
+	"testing"
+
+	"qmachine/quantum"
+)
+
+// init registers custom instructions through the public API only:
+//
+//	test.madd rd, rs1, imm  rd = rs1*imm + rd
+//	test.poke rd, imm       stores the word imm at the address held in rd
+func init() {
+	err := quantum.RegisterInstruction("test.madd", func(m *quantum.QuantumRISCVMachine, inst quantum.RISCInstruction) error {
+		m.SetRegister(inst.Rd, m.GetRegister(inst.Rs1)*uint64(inst.Imm)+m.GetRegister(inst.Rd))
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = quantum.RegisterInstruction("test.poke", func(m *quantum.QuantumRISCVMachine, inst quantum.RISCInstruction) error {
+		return m.StoreMemory(uint32(m.GetRegister(inst.Rd)), uint64(inst.Imm), 4)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// runCustom runs source on a fresh one-qubit machine
+func runCustom(t *testing.T, source string) (*quantum.QuantumRISCVMachine, error) {
+	t.Helper()
+	m := quantum.NewQuantumRISCVMachine(1)
+	if err := m.LoadRISCSource(source); err != nil {
+		t.Fatalf("loading %q: %v", source, err)
+	}
+	return m, m.ExecuteRISCProgram()
+}
+
+func TestCustomInstruction(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		reg    uint8
+		want   uint64
+	}{
+		{"madd", "addi x5, x0, 1\naddi x6, x0, 7\ntest.madd x5, x6, 3\ntest.madd x5, x6, 0x10\n", 5, 1 + 21 + 112},
+		{"madd into x0", "addi x6, x0, 7\ntest.madd x0, x6, 3\n", 0, 0},
+		{"poke", "addi x5, x0, 256\ntest.poke x5, 0x1234\nlw x6, 0(x5)\n", 6, 0x1234},
+	}
+	for _, tt := range tests {
+		m, err := runCustom(t, tt.source)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := m.GetRegister(tt.reg); got != tt.want {
+			t.Errorf("%s: x%d = %d, want %d", tt.name, tt.reg, got, tt.want)
+		}
+	}
+
+	// A failing handler stops the program with its error
+	if _, err := runCustom(t, "lui x5, 0xFFFFF\ntest.poke x5, 1\n"); err == nil {
+		t.Error("test.poke out of bounds succeeded")
+	}
+
+	found := false
+	for _, op := range quantum.SupportedOps() {
+		found = found || (op.Name == "test.madd" && op.Kind == "custom")
+	}
+	if !found {
+		t.Error("SupportedOps does not list test.madd as a custom instruction")
+	}
+}
+
+func TestCustomInstructionErrors(t *testing.T) {
+	for _, source := range []string{
+		"test.madd x1, x2, x3, x4\n", // Too many operands
+		"test.madd x1, 2, 3\n",       // Two immediates
+		"test.madd x1, x2, x3, 4\n",
+		"test.madd x1, banana\n",
+	} {
+		if err := quantum.NewQuantumRISCVMachine(1).LoadRISCSource(source); err == nil {
+			t.Errorf("%q assembled, want an operand error", source)
+		}
+	}
+
+	handler := func(*quantum.QuantumRISCVMachine, quantum.RISCInstruction) error { return nil }
+	for _, opcode := range []string{"", "two words", "addi", "li", "test.madd"} {
+		if err := quantum.RegisterInstruction(opcode, handler); err == nil {
+			t.Errorf("RegisterInstruction(%q) succeeded", opcode)
+		}
+	}
+	if err := quantum.RegisterInstruction("test.nohandler", nil); err == nil {
+		t.Error("RegisterInstruction accepted a nil handler")
+	}
+
+	vm := quantum.NewQuantumRISCVMachine(1)
+	if err := vm.LoadRISCSource("test.madd x5, x6, 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	host := quantum.NewHostQuantumMachine(1)
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+	if err := host.ExecuteProgram(); err == nil {
+		t.Error("the host executed a VM-only custom instruction")
+	}
+}
@@ -1,8 +1,11 @@
 package quantum
 
 import (
+	"fmt"
 	"math"
 	"math/cmplx"
+	"runtime"
+	"sync"
 )
 
 // Gate represents a quantum gate operation
@@ -64,6 +67,10 @@ var (
 		},
 	}
 
+	// Inverse phase gates S† and T†, which undo S and T
+	SDG = S.Inverse()
+	TDG = T.Inverse()
+
 	// CNOT gate
 	CNOT = &TwoQubitGate{
 		matrix: [4][4]Complex128{
@@ -75,66 +82,218 @@ var (
 	}
 )
 
-// Apply implements the Gate interface for SingleQubitGate
-func (g *SingleQubitGate) Apply(state *QuantumState, target int, controls []int) {
-	size := 1 << state.numQubits
-	newAmplitudes := make([]Complex128, size)
-	
-	for i := 0; i < size; i++ {
-		// Check if control conditions are met
-		controlMet := true
-		for _, control := range controls {
-			if (i>>control)&1 == 0 {
-				controlMet = false
-				break
-			}
-		}
-		
-		if controlMet {
-			// Apply gate to target qubit
-			targetBit := (i >> target) & 1
-			otherBits := i & ^(1 << target)
-			
-			for j := 0; j < 2; j++ {
-				newIndex := otherBits | (j << target)
-				newAmplitudes[newIndex] += state.amplitudes[i] * g.matrix[targetBit][j]
-			}
-		} else {
-			newAmplitudes[i] = state.amplitudes[i]
+// RX returns the rotation about the X axis by theta radians, e^{-iθX/2}
+func RX(theta float64) *SingleQubitGate {
+	c, s := complex(math.Cos(theta/2), 0), complex(math.Sin(theta/2), 0)
+	return &SingleQubitGate{
+		matrix: [2][2]Complex128{
+			{c, -1i * s},
+			{-1i * s, c},
+		},
+	}
+}
+
+// RY returns the rotation about the Y axis by theta radians, e^{-iθY/2}. Its matrix is real, so
+// RY(θ)|0⟩ = cos(θ/2)|0⟩ + sin(θ/2)|1⟩ prepares any real superposition.
+func RY(theta float64) *SingleQubitGate {
+	c, s := complex(math.Cos(theta/2), 0), complex(math.Sin(theta/2), 0)
+	return &SingleQubitGate{
+		matrix: [2][2]Complex128{
+			{c, -s},
+			{s, c},
+		},
+	}
+}
+
+// RZ returns the rotation about the Z axis by theta radians, e^{-iθZ/2} = diag(e^{-iθ/2}, e^{iθ/2})
+func RZ(theta float64) *SingleQubitGate {
+	return &SingleQubitGate{
+		matrix: [2][2]Complex128{
+			{cmplx.Exp(complex(0, -theta/2)), 0},
+			{0, cmplx.Exp(complex(0, theta/2))},
+		},
+	}
+}
+
+// Phase returns the phase shift by theta radians, diag(1, e^{iθ}). Unlike RZ it leaves |0⟩
+// untouched, so a controlled Phase is the controlled-phase gate of the quantum Fourier transform.
+func Phase(theta float64) *SingleQubitGate {
+	return &SingleQubitGate{
+		matrix: [2][2]Complex128{
+			{1, 0},
+			{0, cmplx.Exp(complex(0, theta))},
+		},
+	}
+}
+
+// RequireQubits returns an error when a gate needing `needed` qubits is applied to a smaller machine
+func RequireQubits(gate string, needed, numQubits int) error {
+	if numQubits < needed {
+		return fmt.Errorf("%s requires at least %d qubits, machine has %d", gate, needed, numQubits)
+	}
+	return nil
+}
+
+// Inverse returns a new gate whose matrix is the conjugate transpose of g (its inverse, since
+// gates are unitary)
+func (g *SingleQubitGate) Inverse() *SingleQubitGate {
+	inv := &SingleQubitGate{}
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			inv.matrix[i][j] = cmplx.Conj(g.matrix[j][i])
 		}
 	}
-	
-	state.amplitudes = newAmplitudes
-	state.Normalize()
+	return inv
+}
+
+// Apply implements the Gate interface for SingleQubitGate. The state is updated in place:
+// only the amplitude pairs (i, i|1<<target) interact, so no new vector is allocated.
+func (g *SingleQubitGate) Apply(state *QuantumState, target int, controls []int) {
+	if state.usePolar() && g.isDiagonal() {
+		g.applyPolarDiagonal(state, target, controls)
+	} else {
+		state.store.applyPairs(target, controls, g.matrix)
+		state.invalidatePolar() // Rounding may have changed magnitudes
+	}
+	state.gateApplied()
 }
 
-// Apply implements the Gate interface for TwoQubitGate
+// Apply implements the Gate interface for TwoQubitGate. The 4x4 matrix acts on the two-qubit
+// subspace |control target⟩ (row and column index 2·control + target), whatever the positions of
+// the two qubits in the state, and the state is updated in place.
 func (g *TwoQubitGate) Apply(state *QuantumState, target int, controls []int) {
 	if len(controls) != 1 {
 		panic("TwoQubitGate requires exactly one control qubit")
 	}
-	
-	size := 1 << state.numQubits
-	newAmplitudes := make([]Complex128, size)
-	
-	for i := 0; i < size; i++ {
-		control := controls[0]
-		controlBit := (i >> control) & 1
-		
-		if controlBit == 1 {
-			// Apply two-qubit gate
-			targetBit := (i >> target) & 1
-			otherBits := i & ^(1 << target)
-			
-			for j := 0; j < 2; j++ {
-				newIndex := otherBits | (j << target)
-				newAmplitudes[newIndex] += state.amplitudes[i] * g.matrix[targetBit][j]
-			}
-		} else {
-			newAmplitudes[i] = state.amplitudes[i]
+	state.store.applyQuads(controls[0], target, g.matrix)
+	state.invalidatePolar()
+	state.gateApplied()
+}
+
+// applyQuads multiplies the 4x4 matrix u into every group of four amplitudes that differ only in
+// the bits of qubits high and low; bit 1 of a row or column index is high's value and bit 0 is
+// low's. Each group is identified by its member with both bits clear.
+func (d denseStore) applyQuads(high, low int, u [4][4]Complex128) {
+	offsets := [4]int{0, 1 << low, 1 << high, 1<<high | 1<<low}
+	for base := range d {
+		if base&offsets[3] != 0 {
+			continue
+		}
+		var in [4]Complex128
+		for k, offset := range offsets {
+			in[k] = d[base|offset]
+		}
+		for r, offset := range offsets {
+			d[base|offset] = u[r][0]*in[0] + u[r][1]*in[1] + u[r][2]*in[2] + u[r][3]*in[3]
+		}
+	}
+}
+
+// applyQuads is denseStore.applyQuads for a sparse state: only groups with a populated member can
+// change, so they are collected before any amplitude is written
+func (s sparseStore) applyQuads(high, low int, u [4][4]Complex128) {
+	seen := make(map[BasisState]bool, len(s))
+	for b := range s {
+		seen[b.withBit(high, 0).withBit(low, 0)] = true
+	}
+	for base := range seen {
+		members := [4]BasisState{base, base.flip(low), base.flip(high), base.flip(high).flip(low)}
+		var in [4]Complex128
+		for k, member := range members {
+			in[k] = s[member]
+		}
+		for r, member := range members {
+			s.setAmp(member, u[r][0]*in[0]+u[r][1]*in[1]+u[r][2]*in[2]+u[r][3]*in[3])
 		}
 	}
-	
-	state.amplitudes = newAmplitudes
-	state.Normalize()
-} 
\ No newline at end of file
+}
+
+// parallelGateMinAmplitudes is the smallest dense state whose gates are split across goroutines;
+// below it the cost of starting goroutines outweighs the work they share
+const parallelGateMinAmplitudes = 1 << 16
+
+// applyPairs multiplies the 2x2 matrix u into every amplitude pair (i0, i1) that differs only
+// in the target bit and has all control qubits set. The pairs are numbered 0..2^(n-1)-1; pairs
+// never share an amplitude, so large states split the numbering into contiguous ranges that
+// goroutines update concurrently, with exactly the same arithmetic as a serial pass.
+func (d denseStore) applyPairs(target int, controls []int, u [2][2]Complex128) {
+	pairs := len(d) / 2
+	workers := runtime.GOMAXPROCS(0)
+	if len(d) < parallelGateMinAmplitudes || workers < 2 {
+		applyPairRange(d, target, controls, u, 0, pairs)
+		return
+	}
+	chunk := (pairs + workers - 1) / workers
+	var wg sync.WaitGroup
+	for from := 0; from < pairs; from += chunk {
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			applyPairRange(d, target, controls, u, from, to)
+		}(from, min(from+chunk, pairs))
+	}
+	wg.Wait()
+}
+
+// applyPairRange applies u to pairs from (inclusive) to `to` (exclusive) of dense amplitudes. Pair
+// k's |0⟩ member i0 is k with a zero inserted at the target bit, and its |1⟩ member is i0 | 2^target.
+func applyPairRange(amps []Complex128, target int, controls []int, u [2][2]Complex128, from, to int) {
+	bit := 1 << target
+	i0 := (from>>target)<<(target+1) | from&(bit-1)
+	for k := from; k < to; k++ {
+		if controlsSet(i0, controls) {
+			i1 := i0 | bit
+			a0, a1 := amps[i0], amps[i1]
+			amps[i0] = u[0][0]*a0 + u[0][1]*a1
+			amps[i1] = u[1][0]*a0 + u[1][1]*a1
+		}
+		// Step to the next index with the target bit clear, skipping each block's |1⟩ half
+		if i0++; i0&bit != 0 {
+			i0 += bit
+		}
+	}
+}
+
+// applyPairs is denseStore.applyPairs for a sparse state: only pairs with at least one populated
+// member can change, so the pairs are collected from the populated indices before any amplitude
+// is written (writes add and remove map entries).
+func (s sparseStore) applyPairs(target int, controls []int, u [2][2]Complex128) {
+	pairs := make([]BasisState, 0, len(s))
+	for b := range s {
+		b0 := b.withBit(target, 0)
+		if _, populated := s[b0]; b != b0 && populated {
+			continue // The pair is collected from its populated |0⟩ member
+		}
+		if b0.allSet(controls) {
+			pairs = append(pairs, b0)
+		}
+	}
+	for _, b0 := range pairs {
+		b1 := b0.flip(target)
+		a0, a1 := s[b0], s[b1]
+		s.setAmp(b0, u[0][0]*a0+u[0][1]*a1)
+		s.setAmp(b1, u[1][0]*a0+u[1][1]*a1)
+	}
+}
+
+// swapQubits exchanges qubits a and b in place: each basis state with a = 1 and b = 0 trades
+// amplitudes with its partner that has a = 0 and b = 1. The pairs are collected first, from
+// whichever member is populated, so that no pair is swapped twice.
+func swapQubits(state *QuantumState, a, b int) {
+	var pairs []BasisState // The a = 1, b = 0 member of each pair
+	state.each(func(i BasisState, _ Complex128) {
+		switch partner := i.flip(a).flip(b); {
+		case i.Bit(a) == 1 && i.Bit(b) == 0:
+			pairs = append(pairs, i)
+		case i.Bit(a) == 0 && i.Bit(b) == 1 && state.amp(partner) == 0:
+			pairs = append(pairs, partner) // Its unpopulated partner is never visited itself
+		}
+	})
+	for _, i := range pairs {
+		j := i.flip(a).flip(b)
+		ai, aj := state.amp(i), state.amp(j)
+		state.setAmp(i, aj)
+		state.setAmp(j, ai)
+	}
+	state.invalidatePolar()
+}
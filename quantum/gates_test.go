@@ -0,0 +1,321 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// benchmarkQubits is the state size of the gate benchmarks, matching the bench-gates default
+const benchmarkQubits = 16
+
+// benchmarkOp times the gateBenchmarks operation called name
+func benchmarkOp(b *testing.B, name string) {
+	for _, bench := range gateBenchmarks {
+		if bench.name != name {
+			continue
+		}
+		state := newBenchmarkState(benchmarkQubits)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			bench.op(state, benchmarkQubits)
+		}
+		return
+	}
+	b.Fatalf("no gate benchmark named %q", name)
+}
+
+func BenchmarkSingleGate(b *testing.B) { benchmarkOp(b, "SingleGate (H)") }
+
+func BenchmarkCNOT(b *testing.B) { benchmarkOp(b, "CNOT") }
+
+func BenchmarkMeasure(b *testing.B) { benchmarkOp(b, "Measure") }
+
+// pairsQubits is the state size of the serial vs parallel kernel comparison, large enough that
+// applyPairs splits the work across goroutines
+const pairsQubits = 22
+
+// newPairsState returns a dense pairsQubits state with every amplitude populated and distinct
+func newPairsState(tb testing.TB) *QuantumState {
+	state := NewQuantumState(pairsQubits)
+	if err := state.SetBackend(BackendDense); err != nil {
+		tb.Fatal(err)
+	}
+	for i := range dense(state) {
+		dense(state)[i] = complex(float64(i%97), float64(i%31))
+	}
+	state.Normalize()
+	return state
+}
+
+// applyPairsSerial is applyPairs on one goroutine
+func applyPairsSerial(state *QuantumState, target int, controls []int, u [2][2]Complex128) {
+	applyPairRange(dense(state), target, controls, u, 0, len(dense(state))/2)
+}
+
+func TestApplyPairsParallelMatchesSerial(t *testing.T) {
+	tests := []struct {
+		gate     *SingleQubitGate
+		target   int
+		controls []int
+	}{
+		{H, 0, nil},
+		{H, pairsQubits - 1, nil},
+		{T, 7, nil},
+		{X, 3, []int{0, 15}},
+		{RY(0.7), 21, []int{4}},
+	}
+	for _, tt := range tests {
+		parallel := newPairsState(t)
+		serial := parallel.Clone()
+		parallel.store.applyPairs(tt.target, tt.controls, tt.gate.matrix)
+		applyPairsSerial(serial, tt.target, tt.controls, tt.gate.matrix)
+		for i := range dense(serial) {
+			if dense(parallel)[i] != dense(serial)[i] {
+				t.Fatalf("target %d controls %v: amplitude %d is %v in parallel, %v serially", tt.target,
+					tt.controls, i, dense(parallel)[i], dense(serial)[i])
+			}
+		}
+	}
+}
+
+func BenchmarkApplyPairsSerial22(b *testing.B) {
+	state := newPairsState(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		applyPairsSerial(state, i%pairsQubits, nil, H.matrix)
+	}
+}
+
+func BenchmarkApplyPairsParallel22(b *testing.B) {
+	state := newPairsState(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state.store.applyPairs(i%pairsQubits, nil, H.matrix)
+	}
+}
+
+// applyAllocating is the single-qubit kernel before gates were applied in place: it builds a new
+// amplitude slice for every gate. It is the reference the in-place kernel must match.
+func applyAllocating(state *QuantumState, target int, u [2][2]Complex128) {
+	bit := 1 << target
+	newAmplitudes := make([]Complex128, len(dense(state)))
+	for i := range dense(state) {
+		if i&bit == 0 {
+			newAmplitudes[i] = u[0][0]*dense(state)[i] + u[0][1]*dense(state)[i|bit]
+		} else {
+			newAmplitudes[i] = u[1][0]*dense(state)[i&^bit] + u[1][1]*dense(state)[i]
+		}
+	}
+	state.store = denseStore(newAmplitudes)
+}
+
+func TestInPlaceApplyMatchesAllocating(t *testing.T) {
+	gates := []*SingleQubitGate{H, X, Y, Z, S, T, RX(0.4), RY(-1.1), RZ(2.5)}
+	for _, gate := range gates {
+		for target := 0; target < 6; target++ {
+			inPlace := NewQuantumState(6)
+			for i := range dense(inPlace) {
+				dense(inPlace)[i] = complex(float64(i+1), float64(7-i%5))
+			}
+			inPlace.Normalize()
+			reference := inPlace.Clone()
+			gate.Apply(inPlace, target, nil)
+			applyAllocating(reference, target, gate.matrix)
+			for i, want := range dense(reference) {
+				if got := dense(inPlace)[i]; got != want {
+					t.Fatalf("%v on qubit %d: amplitude %d = %v, allocating kernel gives %v", gate.matrix, target,
+						i, got, want)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkApplyInPlace reports 0 allocs/op with -benchmem, where BenchmarkApplyAllocating
+// allocates a whole state per gate
+func BenchmarkApplyInPlace(b *testing.B) {
+	state := newBenchmarkState(benchmarkQubits)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		H.Apply(state, i%benchmarkQubits, nil)
+	}
+}
+
+func BenchmarkApplyAllocating(b *testing.B) {
+	state := newBenchmarkState(benchmarkQubits)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		applyAllocating(state, i%benchmarkQubits, H.matrix)
+	}
+}
+
+func TestRequireQubits(t *testing.T) {
+	tests := []struct {
+		needed, numQubits int
+		wantErr           bool
+	}{
+		{2, 1, true},
+		{2, 2, false},
+		{2, 0, true},
+		{3, 5, false},
+	}
+	for _, tt := range tests {
+		if err := RequireQubits("CNOT", tt.needed, tt.numQubits); (err != nil) != tt.wantErr {
+			t.Errorf("RequireQubits(%d, %d): error %v, want error %v", tt.needed, tt.numQubits, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTwoQubitGatesOnOneQubit(t *testing.T) {
+	m := NewQuantumRISCVMachine(1)
+	if err := m.executeInstruction(Instruction{Opcode: 0x06, Target: 0, Controls: []int{1}}); err == nil {
+		t.Error("CNOT ran on a one-qubit machine")
+	}
+	if err := applyRegisterGate(NewQuantumState(1), 6); err == nil {
+		t.Error("qapply CNOT ran on a one-qubit VM register")
+	}
+	host := NewHostQuantumMachine(1)
+	if err := host.applyHostGate(6, NewHostQuantumState(1)); err == nil {
+		t.Error("qapply CNOT ran on a one-qubit host register")
+	}
+}
+
+func TestRotationGates(t *testing.T) {
+	s := complex(1/math.Sqrt2, 0)
+	tests := []struct {
+		name string
+		gate *SingleQubitGate
+		want [2][2]Complex128
+	}{
+		{"RX(0)", RX(0), [2][2]Complex128{{1, 0}, {0, 1}}},
+		{"RX(π)", RX(math.Pi), [2][2]Complex128{{0, -1i}, {-1i, 0}}},
+		{"RX(π/2)", RX(math.Pi / 2), [2][2]Complex128{{s, -1i * s}, {-1i * s, s}}},
+		{"RY(π)", RY(math.Pi), [2][2]Complex128{{0, -1}, {1, 0}}},
+		{"RZ(π)", RZ(math.Pi), [2][2]Complex128{{-1i, 0}, {0, 1i}}},
+		{"RZ(-π/2)", RZ(-math.Pi / 2), [2][2]Complex128{{cmplx.Exp(math.Pi / 4 * 1i), 0}, {0, cmplx.Exp(-math.Pi / 4 * 1i)}}},
+	}
+	for _, tt := range tests {
+		for r := range tt.want {
+			for c := range tt.want[r] {
+				if !approxEqual(tt.gate.matrix[r][c], tt.want[r][c]) {
+					t.Errorf("%s[%d][%d] = %v, want %v", tt.name, r, c, tt.gate.matrix[r][c], tt.want[r][c])
+				}
+			}
+		}
+	}
+}
+
+func TestGateActionOnBasisStates(t *testing.T) {
+	r := Complex128(complex(1/math.Sqrt2, 0))
+	tests := []struct {
+		name  string
+		gate  *SingleQubitGate
+		input int           // Basis state of the single qubit
+		want  [2]Complex128 // Resulting amplitudes of |0⟩ and |1⟩
+	}{
+		{"X|0>", X, 0, [2]Complex128{0, 1}},
+		{"Y|0>", Y, 0, [2]Complex128{0, 1i}},
+		{"Y|1>", Y, 1, [2]Complex128{-1i, 0}}, // The transposed update gave +i here
+		{"Z|1>", Z, 1, [2]Complex128{0, -1}},
+		{"H|1>", H, 1, [2]Complex128{r, -r}},
+		{"S|1>", S, 1, [2]Complex128{0, 1i}},
+		{"T|1>", T, 1, [2]Complex128{0, r + r*1i}},
+	}
+	for _, tt := range tests {
+		state := NewQuantumState(1)
+		dense(state)[0], dense(state)[tt.input] = 0, 1
+		tt.gate.Apply(state, 0, nil)
+		for i, want := range tt.want {
+			if !approxEqual(dense(state)[i], want) {
+				t.Errorf("%s: amplitude %d = %v, want %v", tt.name, i, dense(state)[i], want)
+			}
+		}
+	}
+}
+
+func TestControlledGateInPlace(t *testing.T) {
+	// Uniform superposition over 3 qubits; Y on qubit 0 controlled by qubits 1 and 2 touches only
+	// the pair (6, 7)
+	state := NewQuantumState(3)
+	for i := range dense(state) {
+		dense(state)[i] = Complex128(complex(float64(i+1), 0))
+	}
+	Y.Apply(state, 0, []int{1, 2})
+	for i, amp := range dense(state) {
+		want := Complex128(complex(float64(i+1), 0))
+		switch i {
+		case 6:
+			want = -1i * 8
+		case 7:
+			want = 1i * 7
+		}
+		if !approxEqual(amp, want) {
+			t.Errorf("amplitude %d = %v, want %v", i, amp, want)
+		}
+	}
+}
+
+func TestApplyDoesNotAllocate(t *testing.T) {
+	state, controls := NewQuantumState(10), []int{7}
+	for name, gate := range map[string]*SingleQubitGate{"H": H, "Y": Y, "T": T} {
+		allocs := testing.AllocsPerRun(20, func() { gate.Apply(state, 3, controls) })
+		if allocs != 0 {
+			t.Errorf("%s allocated %.0f times per application", name, allocs)
+		}
+	}
+}
+
+// applyTwoQubitReference applies u to qubits (control, target) of amps by evaluating every output
+// amplitude as a row of the full matrix: row and column index 2·control + target
+func applyTwoQubitReference(amps []Complex128, control, target int, u [4][4]Complex128) []Complex128 {
+	out := make([]Complex128, len(amps))
+	for i := range amps {
+		row := 2*(i>>control&1) + i>>target&1
+		base := i &^ (1<<control | 1<<target)
+		for col := 0; col < 4; col++ {
+			j := base | (col>>1)<<control | (col&1)<<target
+			out[i] += u[row][col] * amps[j]
+		}
+	}
+	return out
+}
+
+func TestTwoQubitGateFullMatrix(t *testing.T) {
+	r := Complex128(complex(1/math.Sqrt2, 0))
+	gates := map[string][4][4]Complex128{
+		"CNOT":  CNOT.matrix,
+		"SWAP":  {{1, 0, 0, 0}, {0, 0, 1, 0}, {0, 1, 0, 0}, {0, 0, 0, 1}},
+		"CZ":    {{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}, {0, 0, 0, -1}},
+		"iSWAP": {{1, 0, 0, 0}, {0, 0, 1i, 0}, {0, 1i, 0, 0}, {0, 0, 0, 1}},
+		"CH":    {{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, r, r}, {0, 0, r, -r}},
+	}
+	pairs := [][2]int{{0, 1}, {1, 0}, {0, 3}, {3, 1}, {2, 0}}
+	for name, u := range gates {
+		gate := &TwoQubitGate{matrix: u}
+		for _, pair := range pairs {
+			for _, backend := range []string{BackendDense, BackendSparse} {
+				state := NewQuantumState(4)
+				if err := state.SetBackend(backend); err != nil {
+					t.Fatal(err)
+				}
+				for q := 0; q < 4; q++ {
+					RY(0.3+float64(q)).Apply(state, q, nil)
+					T.Apply(state, q, nil)
+				}
+				want := applyTwoQubitReference(state.GetAmplitudes(), pair[0], pair[1], u)
+				gate.Apply(state, pair[1], []int{pair[0]})
+				for i, amp := range state.GetAmplitudes() {
+					if !approxEqual(amp, want[i]) {
+						t.Errorf("%s control %d target %d (%s): amplitude %d = %v, want %v", name, pair[0],
+							pair[1], backend, i, amp, want[i])
+						break
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package quantum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GateRecord is one entry in the machine's ordered history of applied operations
+type GateRecord struct {
+	Name     string  // Gate mnemonic ("H", "CNOT", "I", ...), "MEASURE", "PARITY", "COLLAPSE" or "NOISE"
+	Target   int     // Target qubit; for COLLAPSE, the basis state the state collapsed onto
+	Controls []int   // Control qubits; for SWAP, PARITY and NOISE, the other qubits involved
+	Angle    float64 // Angle in radians for parameterized gates (RX, RY, RZ, P)
+}
+
+// rotationGates builds the parameterized gates recorded with an angle
+var rotationGates = map[string]func(theta float64) *SingleQubitGate{
+	"RX": RX,
+	"RY": RY,
+	"RZ": RZ,
+	"P":  Phase,
+}
+
+// IsRotationGate reports whether name is a parameterized gate (RX, RY, RZ or the phase shift P)
+func IsRotationGate(name string) bool {
+	_, ok := rotationGates[name]
+	return ok
+}
+
+// gateNames maps quantum instruction opcodes to their gate mnemonics
+var gateNames = map[uint8]string{
+	0x00: "X",
+	0x01: "Y",
+	0x02: "Z",
+	0x03: "H",
+	0x04: "S",
+	0x05: "T",
+	0x06: "CNOT",
+	0x07: "MEASURE",
+	0x08: "I",
+	0x09: "SDG",
+	0x0A: "TDG",
+}
+
+// isMeasurement reports whether a recorded operation projects the state: a qubit or parity
+// measurement, or a forced collapse
+func isMeasurement(name string) bool {
+	return name == "MEASURE" || name == "PARITY" || name == "COLLAPSE"
+}
+
+// singleQubitGateOpcode returns the opcode of a single-qubit gate mnemonic (X, Y, Z, H, S, T, I,
+// SDG, TDG)
+func singleQubitGateOpcode(name string) (uint8, bool) {
+	for opcode, gate := range gateNames {
+		if gate == name && gate != "CNOT" && gate != "MEASURE" {
+			return opcode, true
+		}
+	}
+	return 0, false
+}
+
+// String renders the record as e.g. "CNOT q1 (controls: q0)", "RZ(0.5) q1" or "PARITY q0 q1"
+func (r GateRecord) String() string {
+	if r.Name == "COLLAPSE" {
+		return fmt.Sprintf("COLLAPSE to basis state %d", r.Target)
+	}
+	if r.Name == "PARITY" || r.Name == "SWAP" || r.Name == "NOISE" { // Every qubit plays the same role
+		text := fmt.Sprintf("%s q%d", r.Name, r.Target)
+		for _, q := range r.Controls {
+			text += fmt.Sprintf(" q%d", q)
+		}
+		return text
+	}
+	name := r.Name
+	if _, ok := rotationGates[r.Name]; ok {
+		name = fmt.Sprintf("%s(%g)", r.Name, r.Angle)
+	}
+	text := fmt.Sprintf("%s q%d", name, r.Target)
+	if len(r.Controls) > 0 {
+		controls := make([]string, len(r.Controls))
+		for i, c := range r.Controls {
+			controls[i] = fmt.Sprintf("q%d", c)
+		}
+		text += fmt.Sprintf(" (controls: %s)", strings.Join(controls, ", "))
+	}
+	return text
+}
+
+// recordGate appends an applied operation to the history
+func (m *QuantumRISCVMachine) recordGate(name string, target int, controls []int) {
+	m.record(GateRecord{Name: name, Target: target, Controls: controls})
+}
+
+// applyRotation applies and records a rotation gate
+func (m *QuantumRISCVMachine) applyRotation(name string, theta float64, target int, controls []int) {
+	rotationGates[name](theta).Apply(m.state, target, controls)
+	m.record(GateRecord{Name: name, Target: target, Controls: controls, Angle: theta})
+}
+
+// ApplyRotation applies the parameterized gate `name` (RX, RY, RZ or P) by theta radians to target,
+// optionally controlled, and records it with its angle
+func (m *QuantumRISCVMachine) ApplyRotation(name string, theta float64, target int, controls []int) error {
+	if !IsRotationGate(name) {
+		return fmt.Errorf("unknown rotation gate: %s", name)
+	}
+	if err := m.validateQubits(append([]int{target}, controls...)...); err != nil {
+		return err
+	}
+	m.applyRotation(name, theta, target, controls)
+	return nil
+}
+
+// GetGateHistory returns the operations applied to the machine state, in order
+func (m *QuantumRISCVMachine) GetGateHistory() []GateRecord {
+	return m.history
+}
+
+// inverseGateNames maps each reversible recorded gate to the name of its inverse; X, Y, Z, H,
+// I, CNOT and SWAP are their own inverses
+var inverseGateNames = map[string]string{
+	"X": "X", "Y": "Y", "Z": "Z", "H": "H", "I": "I", "CNOT": "CNOT", "SWAP": "SWAP",
+	"S": "SDG", "SDG": "S", "T": "TDG", "TDG": "T",
+}
+
+// recordedGates maps single-qubit gate names used in the history to their matrices
+// (I has none: it is a no-op)
+var recordedGates = map[string]*SingleQubitGate{
+	"X": X, "Y": Y, "Z": Z, "H": H, "S": S, "T": T,
+	"SDG": SDG, "TDG": TDG,
+}
+
+// InvertCircuit uncomputes the recorded circuit: it applies the inverse of every recorded gate
+// in reverse order, which returns the state to where the history started, and records the
+// inverse gates too. It returns the number of gates inverted, and fails without touching the
+// state if the history contains a measurement, a forced collapse or noise, none of which can be
+// undone. Rotations are undone by negating their angle.
+func (m *QuantumRISCVMachine) InvertCircuit() (int, error) {
+	history := m.history
+	for _, record := range history {
+		_, rotation := rotationGates[record.Name]
+		if _, ok := inverseGateNames[record.Name]; !ok && !rotation {
+			return 0, fmt.Errorf("cannot invert the circuit: %s is irreversible", record)
+		}
+	}
+	m.applyInverse(history)
+	return len(history), nil
+}
+
+// applyInverse applies and records the inverse of every gate in `gates`, in reverse order. Every
+// gate must be reversible.
+func (m *QuantumRISCVMachine) applyInverse(gates []GateRecord) {
+	for i := len(gates) - 1; i >= 0; i-- {
+		record := gates[i]
+		if _, ok := rotationGates[record.Name]; ok {
+			m.applyRotation(record.Name, -record.Angle, record.Target, record.Controls)
+			continue
+		}
+		inverse := inverseGateNames[record.Name]
+		if inverse == "CNOT" {
+			X.Apply(m.state, record.Target, record.Controls) // CNOT is a controlled X
+		} else if inverse == "SWAP" {
+			swapQubits(m.state, record.Target, record.Controls[0])
+		} else if gate, ok := recordedGates[inverse]; ok {
+			gate.Apply(m.state, record.Target, record.Controls)
+		}
+		m.recordGate(inverse, record.Target, record.Controls)
+	}
+}
@@ -0,0 +1,144 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGateRecordString(t *testing.T) {
+	tests := []struct {
+		record GateRecord
+		want   string
+	}{
+		{GateRecord{Name: "H", Target: 0}, "H q0"},
+		{GateRecord{Name: "I", Target: 3}, "I q3"},
+		{GateRecord{Name: "CNOT", Target: 1, Controls: []int{0}}, "CNOT q1 (controls: q0)"},
+		{GateRecord{Name: "X", Target: 2, Controls: []int{0, 1}}, "X q2 (controls: q0, q1)"},
+		{GateRecord{Name: "PARITY", Target: 0, Controls: []int{2, 3}}, "PARITY q0 q2 q3"},
+		{GateRecord{Name: "NOISE", Target: 1, Controls: []int{0}}, "NOISE q1 q0"},
+		{GateRecord{Name: "COLLAPSE", Target: 5}, "COLLAPSE to basis state 5"},
+	}
+	for _, tt := range tests {
+		if got := tt.record.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.record, got, tt.want)
+		}
+	}
+}
+
+func TestIdentityGateIsRecorded(t *testing.T) {
+	m := NewQuantumRISCVMachine(2)
+	program := []Instruction{
+		{Opcode: 0x03, Target: 0},
+		{Opcode: 0x08, Target: 1},
+		{Opcode: 0x08, Target: 0},
+		{Opcode: 0x06, Target: 1, Controls: []int{0}},
+	}
+	for _, inst := range program {
+		if err := m.ExecuteInstruction(inst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"H q0", "I q1", "I q0", "CNOT q1 (controls: q0)"}
+	history := m.GetGateHistory()
+	if len(history) != len(want) {
+		t.Fatalf("history %v, want %v", history, want)
+	}
+	for i, record := range history {
+		if record.String() != want[i] {
+			t.Errorf("history[%d] = %s, want %s", i, record, want[i])
+		}
+	}
+	// The identity gates left the Bell state H and CNOT make untouched
+	for index, want := range map[int]float64{0: 0.5, 1: 0, 2: 0, 3: 0.5} {
+		if got := basisProbability(m.GetState().GetAmplitude(index)); math.Abs(got-want) > 1e-12 {
+			t.Errorf("P(|%02b⟩) = %g, want %g", index, got, want)
+		}
+	}
+}
+
+func TestInvertCircuit(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(m *QuantumRISCVMachine) error
+		wantErr bool
+	}{
+		{"Clifford+T", func(m *QuantumRISCVMachine) error {
+			for _, inst := range []Instruction{{Opcode: 0x03, Target: 0}, {Opcode: 0x04, Target: 0},
+				{Opcode: 0x05, Target: 1}, {Opcode: 0x06, Target: 1, Controls: []int{0}},
+				{Opcode: 0x01, Target: 2, Controls: []int{1}}} {
+				if err := m.ExecuteInstruction(inst); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, false},
+		{"rotations and SWAP", func(m *QuantumRISCVMachine) error {
+			if err := m.ApplyRotation("RY", 0.7, 0, nil); err != nil {
+				return err
+			}
+			if err := m.ApplyRotation("RX", -1.3, 2, []int{0}); err != nil {
+				return err
+			}
+			if err := m.ApplySWAP(0, 1); err != nil {
+				return err
+			}
+			return m.ZZInteraction(1, 2, 0.4)
+		}, false},
+		{"empty", func(m *QuantumRISCVMachine) error { return nil }, false},
+		{"measurement", func(m *QuantumRISCVMachine) error {
+			m.ExecuteInstruction(Instruction{Opcode: 0x03, Target: 0})
+			_, err := m.MeasureQubit(0)
+			return err
+		}, true},
+		{"forced collapse", func(m *QuantumRISCVMachine) error {
+			m.ExecuteInstruction(Instruction{Opcode: 0x03, Target: 0})
+			return m.ForceCollapse(1)
+		}, true},
+		{"noise", func(m *QuantumRISCVMachine) error {
+			if err := m.SetNoise(NoiseModel{AmplitudeDamping: 0.3}); err != nil {
+				return err
+			}
+			return m.ExecuteInstruction(Instruction{Opcode: 0x03, Target: 0})
+		}, true},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		if err := tt.build(m); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		before := m.GetState().Clone()
+		recorded := len(m.GetGateHistory())
+		count, err := m.InvertCircuit()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: InvertCircuit succeeded, want an error", tt.name)
+			}
+			if fidelity, _ := m.GetState().Fidelity(before); math.Abs(fidelity-1) > 1e-12 {
+				t.Errorf("%s: a failed inversion changed the state", tt.name)
+			}
+			continue
+		}
+		if err != nil || count != recorded {
+			t.Fatalf("%s: InvertCircuit = %d, %v, want %d gates", tt.name, count, err, recorded)
+		}
+		if p := basisProbability(m.GetState().GetAmplitude(0)); math.Abs(p-1) > 1e-9 {
+			t.Errorf("%s: P(|000⟩) after inversion = %g, want 1", tt.name, p)
+		}
+		if got := len(m.GetGateHistory()); got != 2*recorded {
+			t.Errorf("%s: history has %d gates after inversion, want %d", tt.name, got, 2*recorded)
+		}
+	}
+}
+
+func TestRotationRecordString(t *testing.T) {
+	m := NewQuantumRISCVMachine(2)
+	if err := m.ZZInteraction(0, 1, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"CNOT q1 (controls: q0)", "RZ(0.5) q1", "CNOT q1 (controls: q0)"}
+	for i, record := range m.GetGateHistory() {
+		if i >= len(want) || record.String() != want[i] {
+			t.Fatalf("history %v, want %v", m.GetGateHistory(), want)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package quantum
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"math/cmplx"
+	"time"
 )
 
 // HostQuantumState represents a quantum state optimized for host execution
@@ -12,13 +14,20 @@ type HostQuantumState struct {
 	numQubits  int
 }
 
-// NewHostQuantumState creates a new host-optimized quantum state
+// NewHostQuantumState creates a new host-optimized quantum state initialized to |0⟩^⊗n. The host
+// machine computes on its small quantum registers, so a state beyond denseQubitLimit qubits (the
+// machine-wide state of a large machine) only records its qubit count and allocates no amplitudes.
 func NewHostQuantumState(numQubits int) *HostQuantumState {
+	if numQubits > denseQubitLimit {
+		return &HostQuantumState{numQubits: numQubits}
+	}
 	size := 1 << numQubits
-	return &HostQuantumState{
+	state := &HostQuantumState{
 		amplitudes: make([]Complex128, size),
 		numQubits:  numQubits,
 	}
+	state.amplitudes[0] = 1
+	return state
 }
 
 // HostQuantumMachine represents a quantum computer optimized for host execution
@@ -27,46 +36,108 @@ type HostQuantumMachine struct {
 	registers   [128]uint64
 	quantumRegs [128]*HostQuantumState
 	memory      []byte
+	onMeasure   MeasurementObserver
+	rng         RNG
+	rngName     string            // Measurement RNG algorithm (see NewRNG)
+	seed        int64             // Seed the RNG was last initialized from
+	xlen        int               // Register width in bits (32 for RV32, 64 for RV64)
+	program     []RISCInstruction // Program installed by LoadProgram
+	pc          uint32            // Index of the next instruction to execute
+	entryPC     uint32            // Where ExecuteProgram starts
+	breakpoints breakpointSet     // Instruction indices where Continue stops
+	skipped     int               // Unknown instructions skipped during the last run
+	detectLoops bool              // Stop with an error when a backward jump repeats an earlier machine state
+	freshRun    bool              // ExecuteProgram resets the quantum state before running
+	output      io.Writer         // Where ecall print syscalls write (nil means standard output)
 }
 
 // NewHostQuantumMachine creates a new host-optimized quantum machine
 func NewHostQuantumMachine(numQubits int) *HostQuantumMachine {
-	return &HostQuantumMachine{
+	seed := time.Now().UnixNano()
+	m := &HostQuantumMachine{
 		state:       NewHostQuantumState(numQubits),
 		registers:   [128]uint64{},
 		quantumRegs: [128]*HostQuantumState{},
-		memory:      make([]byte, 1024*1024),
+		memory:      make([]byte, MemorySize),
+		rng:         NewSplitMix64(seed),
+		rngName:     DefaultRNG,
+		seed:        seed,
+		xlen:        DefaultXLEN,
+	}
+	m.registers[StackPointer] = DefaultStackTop // Like a loader, point sp at the top of memory
+	return m
+}
+
+// SetXLEN selects RV32 (32) or RV64 (64) register width; results wrap modulo 2^XLEN
+func (m *HostQuantumMachine) SetXLEN(xlen int) error {
+	if err := validateXLEN(xlen); err != nil {
+		return err
 	}
+	m.xlen = xlen
+	return nil
+}
+
+// GetXLEN returns the register width in bits
+func (m *HostQuantumMachine) GetXLEN() int {
+	return m.xlen
+}
+
+// SetSeed re-initializes the measurement RNG so subsequent measurements are reproducible
+func (m *HostQuantumMachine) SetSeed(seed int64) {
+	m.seed = seed
+	m.rng, _ = NewRNG(m.rngName, seed) // rngName is validated by SetRNGAlgorithm
+}
+
+// Seed returns the seed the measurement RNG was last initialized from
+func (m *HostQuantumMachine) Seed() int64 {
+	return m.seed
 }
 
 // ExecuteQuantumRISCV executes a quantum RISC-V instruction on the host
 func (m *HostQuantumMachine) ExecuteQuantumRISCV(inst RISCInstruction) error {
+	if err := CheckRegisters(inst); err != nil {
+		return err
+	}
 	switch inst.Opcode {
 	case "qinit":
 		// Initialize quantum register with |0⟩ state
 		m.quantumRegs[inst.Rd] = NewHostQuantumState(1)
-		m.quantumRegs[inst.Rd].amplitudes[0] = 1.0
 	case "qapply":
 		// Apply quantum gate using host-optimized operations
 		if m.quantumRegs[inst.Rs1] == nil {
 			return fmt.Errorf("quantum register x%d not initialized", inst.Rs1)
 		}
-		gateType := uint8(inst.Imm)
-		m.applyHostGate(gateType, m.quantumRegs[inst.Rs1])
+		if err := m.applyHostGate(inst.Imm, m.quantumRegs[inst.Rs1]); err != nil {
+			return err
+		}
 	case "qmeasure":
 		// Measure quantum register using host-optimized measurement
 		if m.quantumRegs[inst.Rs1] == nil {
 			return fmt.Errorf("quantum register x%d not initialized", inst.Rs1)
 		}
-		result := m.measureHostState(m.quantumRegs[inst.Rs1])
-		m.registers[inst.Rd] = result
+		result, probability := m.measureHostState(m.quantumRegs[inst.Rs1])
+		if m.onMeasure != nil {
+			m.onMeasure(int(inst.Rs1), result, probability)
+		}
+		m.SetRegister(inst.Rd, result)
+	case "qmov":
+		// Move a quantum register reference; the source is invalidated because no-cloning forbids copies
+		if m.quantumRegs[inst.Rs1] == nil {
+			return fmt.Errorf("quantum register x%d not initialized", inst.Rs1)
+		}
+		if inst.Rd != inst.Rs1 {
+			m.quantumRegs[inst.Rd] = m.quantumRegs[inst.Rs1]
+			m.quantumRegs[inst.Rs1] = nil
+		}
 	case "qentangle":
 		// Entangle two quantum registers using host-optimized operations
 		if m.quantumRegs[inst.Rs1] == nil || m.quantumRegs[inst.Rs2] == nil {
 			return fmt.Errorf("quantum registers not initialized")
 		}
 		entangled := NewHostQuantumState(2)
-		m.entangleHostStates(m.quantumRegs[inst.Rs1], m.quantumRegs[inst.Rs2], entangled)
+		if err := m.entangleHostStates(m.quantumRegs[inst.Rs1], m.quantumRegs[inst.Rs2], entangled); err != nil {
+			return err
+		}
 		m.quantumRegs[inst.Rd] = entangled
 	default:
 		return fmt.Errorf("unknown quantum instruction: %s", inst.Opcode)
@@ -74,63 +145,101 @@ func (m *HostQuantumMachine) ExecuteQuantumRISCV(inst RISCInstruction) error {
 	return nil
 }
 
-// applyHostGate applies a quantum gate using host-optimized operations
-func (m *HostQuantumMachine) applyHostGate(gateType uint8, state *HostQuantumState) {
-	switch gateType {
-	case 0: // X gate
-		state.amplitudes[0], state.amplitudes[1] = state.amplitudes[1], state.amplitudes[0]
-	case 1: // Y gate
-		state.amplitudes[0], state.amplitudes[1] = -1i*state.amplitudes[1], 1i*state.amplitudes[0]
-	case 2: // Z gate
-		state.amplitudes[1] = -state.amplitudes[1]
-	case 3: // H gate
-		invSqrt2 := complex(1.0/math.Sqrt2, 0)
-		a, b := state.amplitudes[0], state.amplitudes[1]
-		state.amplitudes[0] = invSqrt2 * (a + b)
-		state.amplitudes[1] = invSqrt2 * (a - b)
-	case 4: // S gate
-		state.amplitudes[1] *= 1i
-	case 5: // T gate
-		state.amplitudes[1] *= cmplx.Exp(1i * math.Pi / 4)
-	case 6: // CNOT gate
-		// For 2-qubit states
-		if state.numQubits == 2 {
-			state.amplitudes[2], state.amplitudes[3] = state.amplitudes[3], state.amplitudes[2]
+// applyHostGate applies a quantum gate using host-optimized operations. Like the VM's
+// applyRegisterGate, single-qubit gates act on qubit 0 of the register, so on every amplitude pair
+// (i, i|1), CNOT flips qubit 0 when qubit 1 is set, I is a no-op and other gate numbers are
+// rejected.
+func (m *HostQuantumMachine) applyHostGate(gateType int64, state *HostQuantumState) error {
+	amps := state.amplitudes
+	if gateType == 6 { // CNOT gate
+		if err := RequireQubits("CNOT", 2, state.numQubits); err != nil {
+			return err
 		}
+		for i := 2; i < len(amps); i += 4 { // Qubit 1 set, qubit 0 clear
+			amps[i], amps[i|1] = amps[i|1], amps[i]
+		}
+		return m.normalizeHostState(state)
+	}
+	if gateType == 8 { // Identity
+		return m.normalizeHostState(state)
+	}
+	gate, ok := registerGates[gateType]
+	if !ok {
+		return fmt.Errorf("error applying quantum gate: unknown opcode: %x", gateType)
 	}
-	m.normalizeHostState(state)
+	g := gate.matrix
+	for i := 0; i < len(amps); i += 2 {
+		a, b := amps[i], amps[i|1]
+		amps[i] = g[0][0]*a + g[0][1]*b
+		amps[i|1] = g[1][0]*a + g[1][1]*b
+	}
+	return m.normalizeHostState(state)
 }
 
-// measureHostState performs measurement using host-optimized operations
-func (m *HostQuantumMachine) measureHostState(state *HostQuantumState) uint64 {
-	// Calculate probabilities
-	p0 := real(state.amplitudes[0] * cmplx.Conj(state.amplitudes[0]))
-	p1 := real(state.amplitudes[1] * cmplx.Conj(state.amplitudes[1]))
-
-	// Simple deterministic measurement (in a real implementation, this would be probabilistic)
-	if p0 > p1 {
-		return 0
+// measureHostState measures qubit 0 of a register using host-optimized operations: it samples
+// the outcome from the marginal probability over every amplitude, collapses the register onto
+// it and renormalizes. It returns the outcome and the probability it had before measurement.
+func (m *HostQuantumMachine) measureHostState(state *HostQuantumState) (uint64, float64) {
+	var p0, p1 float64
+	for i, amp := range state.amplitudes {
+		if i&1 == 0 {
+			p0 += real(amp * cmplx.Conj(amp))
+		} else {
+			p1 += real(amp * cmplx.Conj(amp))
+		}
 	}
-	return 1
+	outcome, probability := 1, p1/(p0+p1)
+	if m.rng.Float64()*(p0+p1) < p0 {
+		outcome, probability = 0, p0/(p0+p1)
+	}
+	for i := range state.amplitudes {
+		if i&1 != outcome {
+			state.amplitudes[i] = 0
+		}
+	}
+	m.normalizeHostState(state) // The kept outcome was sampled, so its probability is nonzero
+	return uint64(outcome), probability
 }
 
-// entangleHostStates entangles two quantum states using host-optimized operations
-func (m *HostQuantumMachine) entangleHostStates(state1, state2, result *HostQuantumState) {
-	// Create Bell state |Φ+⟩ = (|00⟩ + |11⟩)/√2
-	result.amplitudes[0] = 1.0 / math.Sqrt2
-	result.amplitudes[3] = 1.0 / math.Sqrt2
+// entangleHostStates entangles two single-qubit states into result: it forms their tensor
+// product (state1 is qubit 0 of the result, state2 qubit 1), applies H to qubit 0 and then a CNOT
+// from qubit 0 to qubit 1. Two |0⟩ inputs give the Bell state |Φ+⟩ = (|00⟩ + |11⟩)/√2.
+func (m *HostQuantumMachine) entangleHostStates(state1, state2, result *HostQuantumState) error {
+	if state1.numQubits != 1 || state2.numQubits != 1 {
+		return fmt.Errorf("qentangle needs two single-qubit registers, got %d and %d qubits",
+			state1.numQubits, state2.numQubits)
+	}
+	a, b := state1.amplitudes, state2.amplitudes
+	// H on qubit 0 turns its amplitudes into (a0 ± a1)/√2
+	h0, h1 := (a[0]+a[1])/math.Sqrt2, (a[0]-a[1])/math.Sqrt2
+	// Product index = q0 + 2·q1; the CNOT swaps |q0=1,q1=0⟩ (index 1) and |q0=1,q1=1⟩ (index 3)
+	result.amplitudes[0] = h0 * b[0]
+	result.amplitudes[1] = h1 * b[1]
+	result.amplitudes[2] = h0 * b[1]
+	result.amplitudes[3] = h1 * b[0]
+	return nil
 }
 
-// normalizeHostState normalizes a quantum state using host-optimized operations
-func (m *HostQuantumMachine) normalizeHostState(state *HostQuantumState) {
+// normalizeHostState normalizes a quantum state using host-optimized operations. Like
+// QuantumState.Normalize it leaves a zero-norm state untouched and returns an error.
+func (m *HostQuantumMachine) normalizeHostState(state *HostQuantumState) error {
 	var sum float64
 	for _, amp := range state.amplitudes {
 		sum += real(amp * cmplx.Conj(amp))
 	}
+	if !(sum >= minNormalizableNorm) {
+		return fmt.Errorf("cannot normalize: quantum register has zero norm (total probability %g)", sum)
+	}
 	norm := 1.0 / math.Sqrt(sum)
 	for i := range state.amplitudes {
 		state.amplitudes[i] *= complex(norm, 0)
 	}
+	return nil
+}
+
+// SetMeasurementObserver registers a callback invoked after every measurement (nil disables it)
+func (m *HostQuantumMachine) SetMeasurementObserver(observer MeasurementObserver) {
+	m.onMeasure = observer
 }
 
 // GetRegisters returns the current state of all registers
@@ -143,12 +252,12 @@ func (m *HostQuantumMachine) GetState() *HostQuantumState {
 	return m.state
 }
 
-// SetRegister sets the value of a register
+// SetRegister sets the value of a register, masked to XLEN bits
 func (m *HostQuantumMachine) SetRegister(reg uint8, value uint64) {
 	if reg == 0 {
 		return // x0 is hardwired to zero
 	}
-	m.registers[reg] = value
+	m.registers[reg] = maskXLEN(value, m.xlen)
 }
 
 // GetRegister gets the value of a register
@@ -156,53 +265,26 @@ func (m *HostQuantumMachine) GetRegister(reg uint8) uint64 {
 	return m.registers[reg]
 }
 
+// GetSignedRegister returns a register interpreted as a signed XLEN-bit integer
+func (m *HostQuantumMachine) GetSignedRegister(reg uint8) int64 {
+	return signedXLEN(m.registers[reg], m.xlen)
+}
+
 // LoadMemory loads a value from memory
 func (m *HostQuantumMachine) LoadMemory(addr uint32, size uint8) (uint64, error) {
-	switch size {
-	case 1: // byte
-		if addr >= uint32(len(m.memory)) {
-			return 0, fmt.Errorf("memory access out of bounds: addr %d", addr)
-		}
-		return uint64(m.memory[addr]), nil
-	case 2: // halfword
-		if addr+1 >= uint32(len(m.memory)) {
-			return 0, fmt.Errorf("memory access out of bounds: addr %d", addr)
-		}
-		return uint64(m.memory[addr]) | uint64(m.memory[addr+1])<<8, nil
-	case 4: // word
-		if addr+3 >= uint32(len(m.memory)) {
-			return 0, fmt.Errorf("memory access out of bounds: addr %d", addr)
-		}
-		return uint64(m.memory[addr]) | uint64(m.memory[addr+1])<<8 | uint64(m.memory[addr+2])<<16 | uint64(m.memory[addr+3])<<24, nil
-	default:
-		return 0, fmt.Errorf("invalid memory access size: %d", size)
+	return loadMemory(m.memory, addr, size)
+}
+
+// LoadData copies a block of bytes (such as a program's .data section) into memory at addr
+func (m *HostQuantumMachine) LoadData(addr uint32, data []byte) error {
+	if uint64(addr)+uint64(len(data)) > uint64(len(m.memory)) {
+		return fmt.Errorf("data block of %d bytes at addr %d does not fit in memory", len(data), addr)
 	}
+	copy(m.memory[addr:], data)
+	return nil
 }
 
 // StoreMemory stores a value to memory
 func (m *HostQuantumMachine) StoreMemory(addr uint32, value uint64, size uint8) error {
-	switch size {
-	case 1: // byte
-		if addr >= uint32(len(m.memory)) {
-			return fmt.Errorf("memory access out of bounds: addr %d", addr)
-		}
-		m.memory[addr] = byte(value)
-	case 2: // halfword
-		if addr+1 >= uint32(len(m.memory)) {
-			return fmt.Errorf("memory access out of bounds: addr %d", addr)
-		}
-		m.memory[addr] = byte(value)
-		m.memory[addr+1] = byte(value >> 8)
-	case 4: // word
-		if addr+3 >= uint32(len(m.memory)) {
-			return fmt.Errorf("memory access out of bounds: addr %d", addr)
-		}
-		m.memory[addr] = byte(value)
-		m.memory[addr+1] = byte(value >> 8)
-		m.memory[addr+2] = byte(value >> 16)
-		m.memory[addr+3] = byte(value >> 24)
-	default:
-		return fmt.Errorf("invalid memory access size: %d", size)
-	}
-	return nil
+	return storeMemory(m.memory, addr, value, size)
 }
@@ -0,0 +1,125 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// hostRegister returns a two-qubit host register and an equal VM register holding a generic
+// entangled state, so every amplitude pair is populated
+func hostRegister() (*HostQuantumState, *QuantumState) {
+	amps := []Complex128{0.1 + 0.2i, 0.3 - 0.1i, -0.4i, 0.5 + 0.6i}
+	host := NewHostQuantumState(2)
+	vm := NewQuantumState(2)
+	for i, amp := range amps {
+		host.amplitudes[i] = amp
+		vm.SetAmplitude(i, amp)
+	}
+	vm.Normalize()
+	return host, vm
+}
+
+func TestHostGatesMatchRegisterGates(t *testing.T) {
+	for _, gate := range []int64{0, 1, 2, 3, 4, 5, 6, 8} {
+		m := NewHostQuantumMachine(2)
+		host, vm := hostRegister()
+		if err := m.applyHostGate(gate, host); err != nil {
+			t.Fatalf("host gate %d: %v", gate, err)
+		}
+		if err := applyRegisterGate(vm, gate); err != nil {
+			t.Fatalf("VM gate %d: %v", gate, err)
+		}
+		for i, amp := range host.amplitudes {
+			if want := vm.GetAmplitude(i); cmplx.Abs(amp-want) > 1e-12 {
+				t.Errorf("gate %d: host amplitude %d = %v, VM has %v", gate, i, amp, want)
+			}
+		}
+	}
+}
+
+func TestMeasureHostState(t *testing.T) {
+	tests := []struct {
+		name        string
+		amplitudes  []Complex128
+		wantOutcome uint64
+		wantProb    float64
+	}{
+		{"|0⟩", []Complex128{1, 0}, 0, 1},
+		{"|1⟩", []Complex128{0, 1}, 1, 1},
+		{"qubit 1 set, qubit 0 clear", []Complex128{0, 0, 1, 0}, 0, 1},
+		{"qubit 0 set only in the upper half", []Complex128{0, 0, 0, 1}, 1, 1},
+		{"Bell state", []Complex128{1 / math.Sqrt2, 0, 0, 1 / math.Sqrt2}, 0, 0.5},
+	}
+	for _, tt := range tests {
+		for seed := int64(0); seed < 8; seed++ {
+			m := NewHostQuantumMachine(1)
+			m.SetSeed(seed)
+			state := &HostQuantumState{
+				amplitudes: append([]Complex128(nil), tt.amplitudes...),
+				numQubits:  int(math.Log2(float64(len(tt.amplitudes)))),
+			}
+			outcome, p := m.measureHostState(state)
+			if tt.wantProb == 1 && outcome != tt.wantOutcome {
+				t.Errorf("%s: outcome %d, want %d", tt.name, outcome, tt.wantOutcome)
+			}
+			if tt.wantProb != 1 && outcome > 1 {
+				t.Errorf("%s: outcome %d is not a bit", tt.name, outcome)
+			}
+			if math.Abs(p-tt.wantProb) > 1e-12 {
+				t.Errorf("%s: probability %g, want %g", tt.name, p, tt.wantProb)
+			}
+			// The register collapsed onto the outcome, so measuring again repeats it for certain
+			again, p := m.measureHostState(state)
+			if again != outcome || math.Abs(p-1) > 1e-12 {
+				t.Errorf("%s: remeasured %d with p=%g after outcome %d", tt.name, again, p, outcome)
+			}
+		}
+	}
+}
+
+func TestEntangleUsesInputStates(t *testing.T) {
+	r := Complex128(complex(1/math.Sqrt2, 0))
+	tests := []struct {
+		name          string
+		first, second [2]Complex128
+		want          [4]Complex128 // Index q0 + 2·q1
+	}{
+		{"|0⟩|0⟩", [2]Complex128{1, 0}, [2]Complex128{1, 0}, [4]Complex128{r, 0, 0, r}},
+		{"|1⟩|0⟩", [2]Complex128{0, 1}, [2]Complex128{1, 0}, [4]Complex128{r, 0, 0, -r}},
+		{"|0⟩|1⟩", [2]Complex128{1, 0}, [2]Complex128{0, 1}, [4]Complex128{0, r, r, 0}},
+		{"|1⟩|1⟩", [2]Complex128{0, 1}, [2]Complex128{0, 1}, [4]Complex128{0, -r, r, 0}},
+		{"|+⟩|0⟩", [2]Complex128{r, r}, [2]Complex128{1, 0}, [4]Complex128{1, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		first, second := NewUninitializedQuantumState(1), NewUninitializedQuantumState(1)
+		hostFirst, hostSecond := NewHostQuantumState(1), NewHostQuantumState(1)
+		for i := 0; i < 2; i++ {
+			first.SetAmplitude(i, tt.first[i])
+			second.SetAmplitude(i, tt.second[i])
+			hostFirst.amplitudes[i], hostSecond.amplitudes[i] = tt.first[i], tt.second[i]
+		}
+		vm, err := entangleRegisters(first, second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		host := NewHostQuantumState(2)
+		if err := NewHostQuantumMachine(1).entangleHostStates(hostFirst, hostSecond, host); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range tt.want {
+			if !approxEqual(vm.GetAmplitude(i), want) || !approxEqual(host.amplitudes[i], want) {
+				t.Errorf("%s: amplitude %d = %v (VM), %v (host), want %v", tt.name, i, vm.GetAmplitude(i),
+					host.amplitudes[i], want)
+			}
+		}
+	}
+
+	if _, err := entangleRegisters(NewQuantumState(2), NewQuantumState(1)); err == nil {
+		t.Error("entangleRegisters accepted a two-qubit register")
+	}
+	if err := NewHostQuantumMachine(1).entangleHostStates(NewHostQuantumState(1), NewHostQuantumState(2),
+		NewHostQuantumState(2)); err == nil {
+		t.Error("entangleHostStates accepted a two-qubit register")
+	}
+}
@@ -0,0 +1,119 @@
+package quantum
+
+import "fmt"
+
+// hostLoadSizes gives the access width and sign extension of each load instruction
+var hostLoadSizes = map[string]struct {
+	size       uint8
+	signExtend bool
+}{
+	"lw": {4, true}, "lh": {2, true}, "lb": {1, true},
+	"lwu": {4, false}, "lhu": {2, false}, "lbu": {1, false},
+}
+
+// hostStoreSizes gives the access width of each store instruction
+var hostStoreSizes = map[string]uint8{"sw": 4, "sh": 2, "sb": 1}
+
+// executeClassical executes a classical RISC-V instruction on the host and moves the PC to the
+// next instruction (or the jump or branch target)
+func (m *HostQuantumMachine) executeClassical(inst RISCInstruction) error {
+	next := m.pc + 1
+	switch inst.Opcode {
+	case "add", "sub", "and", "or", "xor", "sll", "srl", "sra", "slt", "sltu",
+		"mul", "mulh", "mulhu", "mulhsu", "div", "divu", "rem", "remu":
+		// R-type instructions
+		result, err := ExecuteALU(inst.Opcode, m.GetRegister(inst.Rs1), m.GetRegister(inst.Rs2), m.xlen)
+		if err != nil {
+			return err
+		}
+		m.SetRegister(inst.Rd, result)
+	case "addi", "slli", "srli", "srai", "andi", "ori", "xori", "slti", "sltiu":
+		// I-type instructions
+		result, err := ExecuteALU(inst.Opcode, m.GetRegister(inst.Rs1), uint64(inst.Imm), m.xlen)
+		if err != nil {
+			return err
+		}
+		m.SetRegister(inst.Rd, result)
+	case "lui":
+		m.SetRegister(inst.Rd, uint64(inst.Imm<<12))
+	case "auipc":
+		m.SetRegister(inst.Rd, uint64(m.pc)+uint64(inst.Imm<<12))
+	case "jal":
+		m.SetRegister(inst.Rd, uint64(m.pc+1))
+		next = uint32(int64(m.pc) + inst.Offset)
+	case "jalr":
+		next = uint32(int64(m.GetRegister(inst.Rs1)) + inst.Offset)
+		m.SetRegister(inst.Rd, uint64(m.pc+1))
+	case "beq", "bne", "blt", "bge", "bltu", "bgeu":
+		if m.branchTaken(inst) {
+			next = uint32(int64(m.pc) + inst.Offset)
+		}
+	case "lw", "lh", "lb", "lwu", "lhu", "lbu":
+		if err := m.executeLoad(inst); err != nil {
+			return err
+		}
+	case "sw", "sh", "sb":
+		addr := uint32(int64(m.GetRegister(inst.Rs1)) + inst.Offset)
+		if err := m.StoreMemory(addr, m.GetRegister(inst.Rs2), hostStoreSizes[inst.Opcode]); err != nil {
+			return err
+		}
+	case "ecall":
+		halt, err := syscall(&m.registers, m.memory, m.xlen, outputOrStdout(m.output))
+		if err != nil {
+			return err
+		}
+		if halt {
+			next = uint32(len(m.program)) // Moving the PC past the end stops the run cleanly
+		}
+	case "ebreak":
+		next = uint32(len(m.program)) // No debugger is attached, so a breakpoint halts
+	default:
+		if _, ok := customInstructions[inst.Opcode]; ok {
+			return fmt.Errorf("custom instruction %s is only supported in VM mode", inst.Opcode)
+		}
+		return fmt.Errorf("unknown instruction type")
+	}
+	m.pc = next
+	return nil
+}
+
+// branchTaken evaluates a branch condition; blt and bge compare signed XLEN-bit values
+func (m *HostQuantumMachine) branchTaken(inst RISCInstruction) bool {
+	rs1, rs2 := m.GetRegister(inst.Rs1), m.GetRegister(inst.Rs2)
+	switch inst.Opcode {
+	case "beq":
+		return rs1 == rs2
+	case "bne":
+		return rs1 != rs2
+	case "blt":
+		return m.GetSignedRegister(inst.Rs1) < m.GetSignedRegister(inst.Rs2)
+	case "bge":
+		return m.GetSignedRegister(inst.Rs1) >= m.GetSignedRegister(inst.Rs2)
+	case "bltu":
+		return rs1 < rs2
+	default: // bgeu
+		return rs1 >= rs2
+	}
+}
+
+// executeLoad reads memory at rs1 + offset into rd, sign-extending for lw, lh and lb
+func (m *HostQuantumMachine) executeLoad(inst RISCInstruction) error {
+	load := hostLoadSizes[inst.Opcode]
+	addr := uint32(int64(m.GetRegister(inst.Rs1)) + inst.Offset)
+	val, err := m.LoadMemory(addr, load.size)
+	if err != nil {
+		return err
+	}
+	if load.signExtend {
+		switch load.size {
+		case 1:
+			val = uint64(int8(val))
+		case 2:
+			val = uint64(int16(val))
+		case 4:
+			val = uint64(int32(val))
+		}
+	}
+	m.SetRegister(inst.Rd, val)
+	return nil
+}
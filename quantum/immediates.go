@@ -0,0 +1,62 @@
+package quantum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// immediateBits is the width of the signed immediate field of I-type (addi, loads, jalr),
+// S-type (stores) and B-type (branches) instructions
+const immediateBits = 12
+
+// literalBases maps the prefixes of non-decimal integer literals to their base
+var literalBases = map[string]int{"0x": 16, "0b": 2, "0o": 8}
+
+// parseIntLiteral parses a decimal, hex (0x), binary (0b), octal (0o) or character literal,
+// with a trailing operand comma allowed. pattern reports a non-negative hex, binary or octal
+// literal, which names a bit pattern rather than a number.
+func parseIntLiteral(op string) (value int64, pattern bool, err error) {
+	if isCharLiteral(op) {
+		value, err = parseCharLiteral(op)
+		return value, false, err
+	}
+	op = strings.TrimRight(op, ",")
+	digits := strings.TrimPrefix(op, "-")
+	if len(digits) > 2 {
+		if base, ok := literalBases[strings.ToLower(digits[:2])]; ok {
+			bits, err := strconv.ParseUint(digits[2:], base, 64)
+			if err != nil {
+				return 0, false, err
+			}
+			if digits != op {
+				return -int64(bits), false, nil
+			}
+			return int64(bits), true, nil
+		}
+	}
+	value, err = strconv.ParseInt(op, 10, 64)
+	return value, false, err
+}
+
+// parseSignedField parses an immediate encoded in a signed immediateBits-wide field, following
+// the convention of reading a literal the way it is written:
+//   - a number (decimal, negative or character) must fit the signed range, -2048..2047, so
+//     4095 is rejected rather than silently becoming -1;
+//   - a hex, binary or octal literal is the raw field bits: 0x000..0xFFF is accepted and
+//     sign-extended from bit 11 as the hardware decodes it, so 0xFFF is -1 and 0x800 is -2048.
+func parseSignedField(op string) (int64, error) {
+	value, pattern, err := parseIntLiteral(op)
+	if err != nil {
+		return 0, fmt.Errorf("invalid immediate value: %v", err)
+	}
+	min, max := int64(-1)<<(immediateBits-1), int64(1)<<(immediateBits-1)-1
+	if pattern && value >= 0 && value < 1<<immediateBits {
+		return signExtend(value, immediateBits), nil
+	}
+	if value < min || value > max {
+		return 0, fmt.Errorf("immediate %s does not fit a signed %d-bit field (%d to %d, or 0x0 to 0x%X as raw bits)",
+			strings.TrimRight(op, ","), immediateBits, min, max, int64(1)<<immediateBits-1)
+	}
+	return value, nil
+}
@@ -0,0 +1,93 @@
+package quantum
+
+import "testing"
+
+func TestParseIntLiteral(t *testing.T) {
+	tests := []struct {
+		op          string
+		want        int64
+		wantPattern bool
+		wantErr     bool
+	}{
+		{"42", 42, false, false},
+		{"-7,", -7, false, false},
+		{"0x1F", 31, true, false},
+		{"0XfF", 255, true, false},
+		{"0b101", 5, true, false},
+		{"0o17", 15, true, false},
+		{"-0x10", -16, false, false},
+		{"'A'", 65, false, false},
+		{"0xFFFFFFFFFFFFFFFF", -1, true, false},
+		{"0x", 0, false, true},
+		{"0b102", 0, false, true},
+		{"12abc", 0, false, true},
+	}
+	for _, tt := range tests {
+		got, pattern, err := parseIntLiteral(tt.op)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %v", tt.op, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (got != tt.want || pattern != tt.wantPattern) {
+			t.Errorf("%q = %d (pattern %v), want %d (pattern %v)", tt.op, got, pattern, tt.want, tt.wantPattern)
+		}
+	}
+}
+
+func TestParseSignedField(t *testing.T) {
+	tests := []struct {
+		op      string
+		want    int64
+		wantErr bool
+	}{
+		{"2047", 2047, false},
+		{"-2048", -2048, false},
+		{"0x7FF", 2047, false},
+		{"0xFFF", -1, false}, // Raw field bits, sign-extended from bit 11
+		{"0x800", -2048, false},
+		{"0b111111111111", -1, false},
+		{"-0x800", -2048, false},
+		{"2048", 0, true},
+		{"4095", 0, true}, // A number, not bits: out of range rather than -1
+		{"-2049", 0, true},
+		{"0x1000", 0, true},
+		{"-0x801", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSignedField(tt.op)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %v", tt.op, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%q = %d, want %d", tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestSignExtendedImmediates(t *testing.T) {
+	tests := []struct {
+		source string
+		reg    int
+		want   uint64
+	}{
+		{"addi x5, x0, 0xFFF\n", 5, 0xFFFFFFFFFFFFFFFF},
+		{"addi x5, x0, -1\n", 5, 0xFFFFFFFFFFFFFFFF},
+		{"addi x5, x0, 0x10\n", 5, 16},
+		{"addi x5, x0, 0b1010\n", 5, 10},
+		{"addi x5, x0, 1\nslli x5, x5, 0x3F\n", 5, 1 << 63}, // Shift amounts are not 12-bit fields
+		{"addi x5, x0, 8\nsw x5, 0x7FF(x0)\nlw x6, 2047(x0)\n", 6, 8},
+	}
+	for _, tt := range tests {
+		if regs := runSource(t, tt.source, DefaultXLEN); regs[tt.reg] != tt.want {
+			t.Errorf("%q: x%d = %#x, want %#x", tt.source, tt.reg, regs[tt.reg], tt.want)
+		}
+	}
+
+	for _, source := range []string{"addi x5, x0, 4095\n", "addi x5, x0, 0x1000\n", "lw x5, 2048(x0)\n",
+		"sw x5, -2049(x0)\n", "beq x0, x0, 4096\n"} {
+		if _, err := RunProgram(source, Options{}); err == nil {
+			t.Errorf("%q assembled, want an out-of-range immediate error", source)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package quantum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// scanQuoted walks s calling visit with each byte index that lies outside a '...' character
+// literal. Backslash escapes inside a literal are skipped, so an escaped quote does not end it.
+func scanQuoted(s string, visit func(i int) bool) {
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case inQuote && s[i] == '\\':
+			i++ // Skip the escaped character
+		case s[i] == '\'':
+			inQuote = !inQuote
+		case !inQuote && !visit(i):
+			return
+		}
+	}
+}
+
+// splitOperands splits s into fields at runs of characters matching sep, like
+// strings.FieldsFunc, but never inside a character literal such as ' ' or ','
+func splitOperands(s string, sep func(rune) bool) []string {
+	var fields []string
+	start := -1
+	cut := func(end int) {
+		if start >= 0 {
+			fields = append(fields, s[start:end])
+			start = -1
+		}
+	}
+
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !inQuote && sep(rune(c)) {
+			cut(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		if inQuote && c == '\\' {
+			i++
+		} else if c == '\'' {
+			inQuote = !inQuote
+		}
+	}
+	cut(len(s))
+	return fields
+}
+
+// instructionFields splits an instruction line into whitespace-separated tokens
+func instructionFields(text string) []string {
+	return splitOperands(text, unicode.IsSpace)
+}
+
+// isCharLiteral reports whether an operand is written as a character literal
+func isCharLiteral(op string) bool {
+	return strings.HasPrefix(op, "'")
+}
+
+// parseCharLiteral parses a quoted character such as 'A', '\n' or '\x41' (with an optional
+// trailing operand comma) into its code point
+func parseCharLiteral(op string) (int64, error) {
+	if strings.HasSuffix(op, "',") {
+		op = op[:len(op)-1]
+	}
+	if len(op) < 3 || !strings.HasSuffix(op, "'") {
+		return 0, fmt.Errorf("invalid character literal %s", op)
+	}
+	value, _, tail, err := strconv.UnquoteChar(op[1:len(op)-1], '\'')
+	if err != nil {
+		return 0, fmt.Errorf("invalid character literal %s: %v", op, err)
+	}
+	if tail != "" {
+		return 0, fmt.Errorf("invalid character literal %s: must contain exactly one character", op)
+	}
+	return int64(value), nil
+}
@@ -0,0 +1,73 @@
+package quantum
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseCharLiteral(t *testing.T) {
+	tests := []struct {
+		op      string
+		want    int64
+		wantErr bool
+	}{
+		{"'A'", 65, false},
+		{"'0'", 48, false},
+		{"' '", 32, false},
+		{"','", 44, false},
+		{"'\\n'", 10, false},
+		{"'\\''", 39, false},
+		{"'\\\\'", 92, false},
+		{"'\\x41'", 65, false},
+		{"'A',", 65, false},
+		{"'é'", 233, false},
+		{"''", 0, true},
+		{"'AB'", 0, true},
+		{"'A", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCharLiteral(tt.op)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCharLiteral(%s): error %v, want error %v", tt.op, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("parseCharLiteral(%s) = %d, want %d", tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestSplitOperandsKeepsLiterals(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"addi x5, x0, ' '", []string{"addi", "x5,", "x0,", "' '"}},
+		{"addi x5, x0, ','", []string{"addi", "x5,", "x0,", "','"}},
+		{"addi x5, x0, '\\''", []string{"addi", "x5,", "x0,", "'\\''"}},
+		{"  add   x1, x2, x3 ", []string{"add", "x1,", "x2,", "x3"}},
+	}
+	for _, tt := range tests {
+		if got := instructionFields(tt.text); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+			t.Errorf("instructionFields(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCharLiteralImmediates(t *testing.T) {
+	tests := []struct {
+		line string
+		want uint64
+	}{
+		{"addi x5, x0, 'A'", 65},
+		{"addi x5, x0, ' '", 32},
+		{"addi x5, x0, '#'", 35}, // Not a comment inside a literal
+		{"addi x5, x0, ';'", 59},
+		{"li x5, '\\n'", 10},
+		{"ori x5, x0, 'z'", 122},
+	}
+	for _, tt := range tests {
+		if got := runSource(t, tt.line+"\n", DefaultXLEN)[5]; got != tt.want {
+			t.Errorf("%s: x5 = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
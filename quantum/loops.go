@@ -0,0 +1,103 @@
+package quantum
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+)
+
+// LoopDetector recognizes true infinite loops: execution arriving at the same PC with an
+// identical machine state (registers, memory and quantum amplitudes) will repeat forever.
+// States are hashed only when a backward branch or jump is taken, since every loop takes one.
+type LoopDetector struct {
+	seen map[uint64]bool
+}
+
+// NewLoopDetector creates an empty loop detector
+func NewLoopDetector() *LoopDetector {
+	return &LoopDetector{seen: make(map[uint64]bool)}
+}
+
+// Check records the state hash observed after a backward jump to pc and reports an error if
+// the same state was seen before
+func (d *LoopDetector) Check(pc uint32, stateHash uint64) error {
+	if d.seen[stateHash] {
+		return fmt.Errorf("infinite loop detected at PC %d: machine state repeated", pc)
+	}
+	d.seen[stateHash] = true
+	return nil
+}
+
+// newStateHasher starts a state hash with the PC and the classical register file
+func newStateHasher(pc uint32, registers *[NumRegisters]uint64) hash.Hash64 {
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, pc)
+	binary.Write(h, binary.LittleEndian, registers[:])
+	return h
+}
+
+// hashAmplitudes adds a state vector to a state hash
+func hashAmplitudes(h hash.Hash64, amplitudes []Complex128) {
+	var buf [16]byte
+	for _, amp := range amplitudes {
+		binary.LittleEndian.PutUint64(buf[:8], math.Float64bits(real(amp)))
+		binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(imag(amp)))
+		h.Write(buf[:])
+	}
+}
+
+// hashState writes a quantum state into h
+func hashState(h hash.Hash64, qs *QuantumState) {
+	qs.store.hash(h)
+}
+
+// hash writes every amplitude of a dense state
+func (d denseStore) hash(h hash.Hash64) {
+	hashAmplitudes(h, d)
+}
+
+// hash writes the basis state and amplitude of each populated basis state of a sparse state
+func (s sparseStore) hash(h hash.Hash64) {
+	var buf [8]byte
+	s.each(func(b BasisState, amp Complex128) {
+		binary.LittleEndian.PutUint64(buf[:], b.low)
+		h.Write(buf[:])
+		h.Write([]byte(b.high))
+		hashAmplitudes(h, []Complex128{amp})
+	})
+}
+
+// SetLoopDetection enables infinite-loop detection in ExecuteRISCProgram. It hashes the whole
+// machine state on every backward jump, so it is off by default.
+func (m *QuantumRISCVMachine) SetLoopDetection(enabled bool) {
+	m.detectLoops = enabled
+}
+
+// stateHash fingerprints the PC, registers, memory and quantum state
+func (m *QuantumRISCVMachine) stateHash() uint64 {
+	h := newStateHasher(m.pc, &m.registers)
+	h.Write(m.memory)
+	hashState(h, m.state)
+	for i, reg := range m.quantumRegs {
+		if reg != nil {
+			binary.Write(h, binary.LittleEndian, uint8(i))
+			hashState(h, reg)
+		}
+	}
+	return h.Sum64()
+}
+
+// StateHash fingerprints the PC, registers, memory and quantum registers for loop detection
+func (m *HostQuantumMachine) StateHash(pc uint32) uint64 {
+	h := newStateHasher(pc, &m.registers)
+	h.Write(m.memory)
+	for i, reg := range m.quantumRegs {
+		if reg != nil {
+			binary.Write(h, binary.LittleEndian, uint8(i))
+			hashAmplitudes(h, reg.amplitudes)
+		}
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,71 @@
+package quantum
+
+import "testing"
+
+func TestLoopDetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantLoop bool
+		hostToo  bool // The program only uses instructions the host machine executes
+	}{
+		{"self branch", "loop: beq x0, x0, loop\n", true, true},
+		{"jump back", "addi x5, x0, 3\nloop: addi x6, x0, 1\njal x0, loop\n", true, true},
+		{"countdown", "addi x5, x0, 50\nloop: addi x5, x5, -1\nbne x5, x0, loop\n", false, true},
+		{"qubit flipped every pass", "loop: qgate.r X, x0\njal x0, loop\n", true, false},
+		{"forward jumps only", "jal x0, end\naddi x5, x0, 1\nend: addi x6, x0, 2\n", false, true},
+	}
+	for _, tt := range tests {
+		vm := NewQuantumRISCVMachine(1)
+		vm.SetLoopDetection(true)
+		if err := vm.LoadRISCSource(tt.source); err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.ExecuteRISCProgram(); (err != nil) != tt.wantLoop {
+			t.Errorf("%s (VM): error %v, want loop %v", tt.name, err, tt.wantLoop)
+		}
+		if !tt.hostToo {
+			continue
+		}
+		host := NewHostQuantumMachine(1)
+		host.SetLoopDetection(true)
+		host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+		if err := host.ExecuteProgram(); (err != nil) != tt.wantLoop {
+			t.Errorf("%s (host): error %v, want loop %v", tt.name, err, tt.wantLoop)
+		}
+	}
+}
+
+func TestLoopDetector(t *testing.T) {
+	d := NewLoopDetector()
+	for i, tt := range []struct {
+		hash    uint64
+		wantErr bool
+	}{
+		{1, false},
+		{2, false},
+		{1, true},
+		{3, false},
+		{2, true},
+	} {
+		if err := d.Check(7, tt.hash); (err != nil) != tt.wantErr {
+			t.Errorf("check %d (hash %d): error %v, want error %v", i, tt.hash, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStateHashSeesQuantumState(t *testing.T) {
+	m := NewQuantumRISCVMachine(2)
+	before := m.stateHash()
+	H.Apply(m.GetState(), 1, nil)
+	if m.stateHash() == before {
+		t.Error("state hash ignored a change to the qubits")
+	}
+	sparse := NewQuantumState(2)
+	sparse.SetBackend(BackendSparse)
+	H.Apply(sparse, 1, nil)
+	m.state = sparse
+	if m.stateHash() == before {
+		t.Error("state hash ignored a sparse state")
+	}
+}
@@ -0,0 +1,229 @@
+package quantum
+
+import (
+	"fmt"
+	"math/cmplx"
+)
+
+// MeasurementResult describes a single collapsing measurement
+type MeasurementResult struct {
+	Qubit       int     // Measured qubit
+	Outcome     int     // Collapsed value, 0 or 1
+	Probability float64 // Probability of Outcome in the pre-measurement state
+}
+
+// MeasurementObserver is notified of every measurement with the measured register or qubit,
+// the outcome, and the probability of that outcome in the pre-measurement state
+type MeasurementObserver func(qubit int, outcome uint64, probability float64)
+
+// MeasureProbability returns the probability of observing `outcome` (0 or 1) on `qubit`
+// without collapsing the state
+func (qs *QuantumState) MeasureProbability(qubit, outcome int) float64 {
+	var p, total float64
+	qs.each(func(b BasisState, amp Complex128) {
+		weight := real(amp * cmplx.Conj(amp))
+		total += weight
+		if b.Bit(qubit) == outcome {
+			p += weight
+		}
+	})
+	if total == 0 {
+		return 0
+	}
+	return p / total
+}
+
+// PatternProbability returns the total probability of all basis states whose qubits match
+// `pattern` (qubit index -> required value 0/1), without collapsing the state.
+// Only populated amplitudes are visited.
+func (qs *QuantumState) PatternProbability(pattern map[int]int) float64 {
+	var p, total float64
+	qs.each(func(b BasisState, amp Complex128) {
+		weight := real(amp * cmplx.Conj(amp))
+		total += weight
+		if matchesPattern(b, pattern) {
+			p += weight
+		}
+	})
+	if total == 0 {
+		return 0
+	}
+	return p / total
+}
+
+// argmaxTolerance is how close two probabilities must be for MostProbable to call them tied
+const argmaxTolerance = 1e-9
+
+// MostProbable returns the basis state with the highest probability in a single pass over the
+// populated amplitudes, without collapsing or sampling, together with that probability and how
+// many other basis states tie with it (0 when the maximum is unique). Ties keep the lowest basis
+// state.
+func (qs *QuantumState) MostProbable() (basis BasisState, probability float64, ties int) {
+	qs.each(func(b BasisState, amp Complex128) {
+		p := real(amp * cmplx.Conj(amp))
+		switch {
+		case p > probability+argmaxTolerance:
+			basis, probability, ties = b, p, 0
+		case p >= probability-argmaxTolerance:
+			ties++
+		}
+	})
+	if total := qs.Norm(); total > 0 {
+		probability /= total
+	}
+	return basis, probability, ties
+}
+
+// matchesPattern reports whether basis state b satisfies every qubit=value requirement
+func matchesPattern(b BasisState, pattern map[int]int) bool {
+	for qubit, value := range pattern {
+		if b.Bit(qubit) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Measure samples `qubit` from its Born-rule probabilities, collapses the state onto the
+// observed outcome and renormalizes
+func (qs *QuantumState) Measure(qubit int, rng RNG) (MeasurementResult, error) {
+	if err := qs.checkQubit(qubit); err != nil {
+		return MeasurementResult{}, err
+	}
+
+	p1 := qs.MeasureProbability(qubit, 1)
+	p0 := qs.MeasureProbability(qubit, 0)
+	if p0+p1 == 0 {
+		return MeasurementResult{}, fmt.Errorf("cannot measure qubit %d: state has zero norm", qubit)
+	}
+
+	result := MeasurementResult{Qubit: qubit, Outcome: 0, Probability: p0}
+	if rng.Float64() >= p0 {
+		result = MeasurementResult{Qubit: qubit, Outcome: 1, Probability: p1}
+	}
+
+	// Project onto the observed subspace
+	qs.each(func(b BasisState, _ Complex128) {
+		if b.Bit(qubit) != result.Outcome {
+			qs.setAmp(b, 0)
+		}
+	})
+	qs.Normalize()
+	return result, nil
+}
+
+// MeasureParity measures the joint Z-basis parity (XOR of the values) of qubits and projects the
+// state onto the even (0) or odd (1) parity subspace. Only the parity is revealed: superpositions
+// inside the observed subspace survive, so measuring the parity of a Bell pair leaves it entangled.
+// It returns the parity and its probability in the pre-measurement state.
+func (qs *QuantumState) MeasureParity(qubits []int, rng RNG) (int, float64, error) {
+	for _, q := range qubits {
+		if err := qs.checkQubit(q); err != nil {
+			return 0, 0, err
+		}
+	}
+	parityOf := func(b BasisState) int {
+		parity := 0
+		for _, q := range qubits {
+			parity ^= b.Bit(q)
+		}
+		return parity
+	}
+
+	var odd, total float64
+	qs.each(func(b BasisState, amp Complex128) {
+		weight := real(amp * cmplx.Conj(amp))
+		total += weight
+		if parityOf(b) == 1 {
+			odd += weight
+		}
+	})
+	if total == 0 {
+		return 0, 0, fmt.Errorf("cannot measure parity: state has zero norm")
+	}
+
+	parity, probability := 0, (total-odd)/total
+	if rng.Float64() >= probability {
+		parity, probability = 1, odd/total
+	}
+	// Project onto the observed parity subspace
+	qs.each(func(b BasisState, _ Complex128) {
+		if parityOf(b) != parity {
+			qs.setAmp(b, 0)
+		}
+	})
+	qs.Normalize()
+	return parity, probability, nil
+}
+
+// MeasureParity measures the joint parity of distinct machine qubits (see QuantumState.MeasureParity)
+func (m *QuantumRISCVMachine) MeasureParity(qubits ...int) (int, float64, error) {
+	if len(qubits) == 0 {
+		return 0, 0, fmt.Errorf("parity needs at least one qubit")
+	}
+	if err := m.validateQubits(qubits...); err != nil {
+		return 0, 0, err
+	}
+	parity, probability, err := m.state.MeasureParity(qubits, m.rng)
+	if err != nil {
+		return 0, 0, err
+	}
+	m.recordGate("PARITY", qubits[0], qubits[1:])
+	return parity, probability, nil
+}
+
+// ResetQubit returns a machine qubit to |0⟩ by measuring it and applying X when the outcome is 1.
+// Because it measures, qubits entangled with it collapse along with it. The measurement result
+// is returned so callers can tell whether the qubit was in superposition.
+func (m *QuantumRISCVMachine) ResetQubit(qubit int) (MeasurementResult, error) {
+	result, err := m.MeasureQubit(qubit)
+	if err != nil {
+		return MeasurementResult{}, err
+	}
+	if result.Outcome == 1 {
+		if err := m.executeInstruction(Instruction{Opcode: 0x00, Target: qubit}); err != nil {
+			return MeasurementResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// CollapseTo projects the state onto computational basis state `index`, zeroing every other
+// amplitude and renormalizing: a measurement of all qubits with a forced outcome
+func (qs *QuantumState) CollapseTo(index int) error {
+	if !qs.inRange(index) {
+		return fmt.Errorf("basis state %d is out of range for %d qubits", index, qs.numQubits)
+	}
+	basis := NewBasisState(index)
+	amp := qs.amp(basis)
+	if weight := real(amp * cmplx.Conj(amp)); !(weight > 0) { // Also rejects NaN amplitudes
+		return fmt.Errorf("cannot collapse to |%0*b>: amplitude is zero (impossible outcome)", qs.numQubits, index)
+	}
+
+	qs.each(func(b BasisState, _ Complex128) {
+		qs.setAmp(b, 0)
+	})
+	// Keep the surviving amplitude's phase, scaled to unit magnitude
+	qs.setAmp(basis, amp/complex(cmplx.Abs(amp), 0))
+	qs.invalidatePolar()
+	return nil
+}
+
+// measureToMemory implements qmeas.mem: it measures machine qubit `qubit` and stores the outcome
+// as a byte (0 or 1) at the memory address held in register addrReg. The address is checked
+// first so that a bad store does not collapse the state.
+func (m *QuantumRISCVMachine) measureToMemory(qubit int64, addrReg uint8) error {
+	addr := m.registers[addrReg]
+	if addr >= uint64(len(m.memory)) {
+		return fmt.Errorf("memory access out of bounds: address 0x%X from x%d", addr, addrReg)
+	}
+	if qubit < 0 || qubit >= int64(m.state.NumQubits()) {
+		return fmt.Errorf("invalid qubit number: %d", qubit)
+	}
+	result, err := m.MeasureQubit(int(qubit))
+	if err != nil {
+		return err
+	}
+	m.memory[addr] = byte(result.Outcome)
+	return nil
+}
@@ -0,0 +1,211 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQMeasMem(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   uint64 // Word at 0x100 afterwards
+	}{
+		{"zero", "addi x10, x0, 0x100\nqmeas.mem 0, x10\nlw x11, 0(x10)\n", 0},
+		{"one", "addi x10, x0, 0x100\nqgate.if x10, X, 1\nqmeas.mem 1, x10\nlw x11, 0(x10)\n", 1},
+		{"writes one byte", "addi x10, x0, 0x100\naddi x5, x0, -1\nsw x5, 0(x10)\nqmeas.mem 0, x10\n" +
+			"lw x11, 0(x10)\n", 0xFFFFFF00},
+		{"offset address", "addi x10, x0, 0x101\naddi x12, x0, 0x100\nqgate.if x10, X, 1\nqmeas.mem 1, x10\n" +
+			"lw x11, 0(x12)\n", 0x100},
+	}
+	for _, tt := range tests {
+		m := runMachine(t, tt.source, 2)
+		if got := m.GetRegisters()[11]; got != tt.want {
+			t.Errorf("%s: word at 0x100 = %#x, want %#x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQMeasMemErrors(t *testing.T) {
+	for _, source := range []string{
+		"addi x10, x0, 0x100\nqmeas.mem 2, x10\n",  // Qubit out of range
+		"addi x10, x0, 0x100\nqmeas.mem -1, x10\n", // Negative qubit
+		"lui x10, 0x100\nqmeas.mem 0, x10\n",       // Address past the end of memory
+		"qmeas.mem 0\n",
+	} {
+		m := NewQuantumRISCVMachine(2)
+		err := m.LoadRISCSource(source)
+		if err == nil {
+			err = m.ExecuteRISCProgram()
+		}
+		if err == nil {
+			t.Errorf("%q ran without an error", source)
+		}
+	}
+
+	// The address is checked before measuring, so a bad store leaves the superposition intact
+	m := NewQuantumRISCVMachine(1)
+	if err := m.LoadRISCSource("lui x10, 0x100\nqgate.if x10, H, 0\nqmeas.mem 0, x10\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExecuteRISCProgram(); err == nil {
+		t.Fatal("qmeas.mem to 0x100000 succeeded")
+	}
+	if p := m.GetState().MeasureProbability(0, 1); math.Abs(p-0.5) > 1e-12 {
+		t.Errorf("failed qmeas.mem collapsed the state: P(q0 = 1) = %g, want 0.5", p)
+	}
+}
+
+func TestMeasureParity(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(m *QuantumRISCVMachine) error
+		qubits      []int
+		wantParity  int     // -1 when either parity may come out
+		wantSupport int     // Nonzero amplitudes left afterwards
+		wantP       float64 // Probability of the observed parity
+	}{
+		{"bell pair", func(m *QuantumRISCVMachine) error { return m.PrepareBell(0, 1) }, []int{0, 1}, 0, 2, 1},
+		{"odd basis state", func(m *QuantumRISCVMachine) error {
+			X.Apply(m.GetState(), 0, nil)
+			return nil
+		}, []int{0, 1}, 1, 1, 1},
+		{"uniform", func(m *QuantumRISCVMachine) error {
+			for q := 0; q < 3; q++ {
+				H.Apply(m.GetState(), q, nil)
+			}
+			return nil
+		}, []int{0, 2}, -1, 4, 0.5},
+		{"single qubit", func(m *QuantumRISCVMachine) error {
+			X.Apply(m.GetState(), 2, nil)
+			return nil
+		}, []int{2}, 1, 1, 1},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		m.SetSeed(3)
+		if err := tt.prepare(m); err != nil {
+			t.Fatal(err)
+		}
+		parity, p, err := m.MeasureParity(tt.qubits...)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if (tt.wantParity >= 0 && parity != tt.wantParity) || math.Abs(p-tt.wantP) > 1e-12 {
+			t.Errorf("%s: parity %d (p=%g), want %d (p=%g)", tt.name, parity, p, tt.wantParity, tt.wantP)
+		}
+		if n := len(m.GetState().NonzeroAmplitudes()); n != tt.wantSupport {
+			t.Errorf("%s: %d nonzero amplitudes after the parity measurement, want %d", tt.name, n, tt.wantSupport)
+		}
+		// Every surviving basis state has the observed parity
+		for _, basis := range m.GetState().NonzeroAmplitudes() {
+			ones := 0
+			for _, q := range tt.qubits {
+				ones += basis.Index >> q & 1
+			}
+			if ones%2 != parity {
+				t.Errorf("%s: basis state %b survived parity %d", tt.name, basis.Index, parity)
+			}
+		}
+		history := m.GetGateHistory()
+		if len(history) == 0 || history[len(history)-1].Name != "PARITY" {
+			t.Errorf("%s: parity measurement not recorded: %v", tt.name, history)
+		}
+	}
+}
+
+func TestMeasureParityErrors(t *testing.T) {
+	for _, qubits := range [][]int{nil, {0, 0}, {0, 3}, {-1}} {
+		m := NewQuantumRISCVMachine(3)
+		if _, _, err := m.MeasureParity(qubits...); err == nil {
+			t.Errorf("MeasureParity(%v) succeeded", qubits)
+		}
+		if len(m.GetGateHistory()) != 0 {
+			t.Errorf("MeasureParity(%v) recorded a rejected measurement", qubits)
+		}
+	}
+}
+
+func TestResetQubit(t *testing.T) {
+	for seed := int64(0); seed < 8; seed++ {
+		m := NewQuantumRISCVMachine(3)
+		m.SetSeed(seed)
+		X.Apply(m.GetState(), 2, nil)
+		if err := m.PrepareBell(0, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		bell, err := m.ResetQubit(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(bell.Probability-0.5) > 1e-12 {
+			t.Errorf("seed %d: reset of a Bell qubit measured with p=%g, want 0.5", seed, bell.Probability)
+		}
+		one, err := m.ResetQubit(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if one.Outcome != 1 || one.Probability != 1 {
+			t.Errorf("seed %d: reset of |1⟩ measured %d (p=%g), want 1 (p=1)", seed, one.Outcome, one.Probability)
+		}
+
+		state := m.GetState()
+		for _, q := range []int{0, 2} {
+			if p := state.MeasureProbability(q, 0); math.Abs(p-1) > 1e-12 {
+				t.Errorf("seed %d: P(q%d = 0) = %g after reset, want 1", seed, q, p)
+			}
+		}
+		// The partner of the reset Bell qubit collapsed to the measured outcome and is not reset
+		if p := state.MeasureProbability(1, bell.Outcome); math.Abs(p-1) > 1e-12 {
+			t.Errorf("seed %d: P(q1 = %d) = %g after resetting q0, want 1", seed, bell.Outcome, p)
+		}
+	}
+
+	if _, err := NewQuantumRISCVMachine(2).ResetQubit(2); err == nil {
+		t.Error("ResetQubit accepted qubit 2 of a 2-qubit machine")
+	}
+}
+
+func TestMostProbable(t *testing.T) {
+	tests := []struct {
+		name      string
+		prepare   func(qs *QuantumState)
+		wantIndex int
+		wantP     float64
+		wantTies  int
+	}{
+		{"ground", func(qs *QuantumState) {}, 0, 1, 0},
+		{"basis", func(qs *QuantumState) { X.Apply(qs, 1, nil) }, 2, 1, 0},
+		{"bell", func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 2, []int{0})
+		}, 0, 0.5, 1},
+		{"uniform", func(qs *QuantumState) {
+			for q := 0; q < 3; q++ {
+				H.Apply(qs, q, nil)
+			}
+		}, 0, 0.125, 7},
+		{"biased towards 0", func(qs *QuantumState) { RY(0.5).Apply(qs, 0, nil) }, 0, math.Pow(math.Cos(0.25), 2), 0},
+		{"biased towards 1", func(qs *QuantumState) { RY(2.5).Apply(qs, 0, nil) }, 1, math.Pow(math.Sin(1.25), 2), 0},
+	}
+	for _, tt := range tests {
+		for _, backend := range []string{BackendDense, BackendSparse} {
+			qs := NewQuantumState(3)
+			if err := qs.SetBackend(backend); err != nil {
+				t.Fatal(err)
+			}
+			tt.prepare(qs)
+			support := qs.SupportSize()
+			basis, p, ties := qs.MostProbable()
+			if basis != NewBasisState(tt.wantIndex) || math.Abs(p-tt.wantP) > 1e-12 || ties != tt.wantTies {
+				t.Errorf("%s (%s): MostProbable() = %v, %g, %d ties, want %d, %g, %d ties", tt.name, backend,
+					basis, p, ties, tt.wantIndex, tt.wantP, tt.wantTies)
+			}
+			if qs.SupportSize() != support {
+				t.Errorf("%s (%s): MostProbable changed the state", tt.name, backend)
+			}
+		}
+	}
+}
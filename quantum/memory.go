@@ -0,0 +1,42 @@
+package quantum
+
+import "fmt"
+
+// loadMemory reads a little-endian byte, halfword or word (size 1, 2 or 4) from memory at addr
+func loadMemory(memory []byte, addr uint32, size uint8) (uint64, error) {
+	if size != 1 && size != 2 && size != 4 {
+		return 0, fmt.Errorf("invalid memory access size: %d", size)
+	}
+	if uint64(addr)+uint64(size) > uint64(len(memory)) {
+		return 0, fmt.Errorf("memory access out of bounds: addr %d", addr)
+	}
+	var value uint64
+	for i := uint32(0); i < uint32(size); i++ {
+		value |= uint64(memory[addr+i]) << (8 * i)
+	}
+	return value, nil
+}
+
+// storeMemory writes the low size bytes (1, 2 or 4) of value to memory at addr, little-endian
+func storeMemory(memory []byte, addr uint32, value uint64, size uint8) error {
+	if size != 1 && size != 2 && size != 4 {
+		return fmt.Errorf("invalid memory access size: %d", size)
+	}
+	if uint64(addr)+uint64(size) > uint64(len(memory)) {
+		return fmt.Errorf("memory access out of bounds: addr %d", addr)
+	}
+	for i := uint32(0); i < uint32(size); i++ {
+		memory[addr+i] = byte(value >> (8 * i))
+	}
+	return nil
+}
+
+// LoadMemory loads a byte, halfword or word (size 1, 2 or 4) from memory, zero-extended
+func (m *QuantumRISCVMachine) LoadMemory(addr uint32, size uint8) (uint64, error) {
+	return loadMemory(m.memory, addr, size)
+}
+
+// StoreMemory stores the low byte, halfword or word (size 1, 2 or 4) of value to memory
+func (m *QuantumRISCVMachine) StoreMemory(addr uint32, value uint64, size uint8) error {
+	return storeMemory(m.memory, addr, value, size)
+}
@@ -0,0 +1,49 @@
+package quantum
+
+import "testing"
+
+func TestLoadStoreMemory(t *testing.T) {
+	type memoryMachine interface {
+		LoadMemory(addr uint32, size uint8) (uint64, error)
+		StoreMemory(addr uint32, value uint64, size uint8) error
+	}
+	tests := []struct {
+		addr    uint32
+		size    uint8
+		value   uint64
+		want    uint64
+		wantErr bool
+	}{
+		{0, 1, 0x1FF, 0xFF, false},
+		{100, 2, 0x12345, 0x2345, false},
+		{DataBase, 4, 0xDEADBEEF, 0xDEADBEEF, false},
+		{MemorySize - 4, 4, 0x01020304, 0x01020304, false},
+		{MemorySize - 1, 1, 7, 7, false},
+		{MemorySize - 1, 2, 7, 0, true},
+		{MemorySize - 3, 4, 7, 0, true},
+		{MemorySize, 1, 7, 0, true},
+		{0xFFFFFFFF, 4, 7, 0, true}, // addr+size wraps around in 32 bits
+		{0, 3, 7, 0, true},
+		{0, 8, 7, 0, true},
+	}
+	machines := map[string]func() memoryMachine{
+		"vm":   func() memoryMachine { return NewQuantumRISCVMachine(1) },
+		"host": func() memoryMachine { return NewHostQuantumMachine(1) },
+	}
+	for name, newMachine := range machines {
+		for _, tt := range tests {
+			m := newMachine()
+			err := m.StoreMemory(tt.addr, tt.value, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s: store %d bytes at %#x: error %v, want error %v", name, tt.size, tt.addr, err, tt.wantErr)
+			}
+			got, err := m.LoadMemory(tt.addr, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s: load %d bytes at %#x: error %v, want error %v", name, tt.size, tt.addr, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s: load %d bytes at %#x = %#x, want %#x", name, tt.size, tt.addr, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package quantum
+
+import "fmt"
+
+// NoiseModel sets the strength of the noise channels a machine applies after every gate to the
+// qubits the gate touched (see ApplyBitFlipNoise and ApplyAmplitudeDamping). The zero value is
+// a noiseless machine.
+type NoiseModel struct {
+	BitFlip          float64 // Probability that each touched qubit is flipped
+	AmplitudeDamping float64 // Decay probability gamma of a touched qubit in |1⟩
+}
+
+// SetNoise selects the noise applied after each gate. Noise is sampled from the measurement RNG,
+// so SetSeed makes noisy runs reproducible.
+func (m *QuantumRISCVMachine) SetNoise(noise NoiseModel) error {
+	for _, p := range []float64{noise.BitFlip, noise.AmplitudeDamping} {
+		if !(p >= 0 && p <= 1) {
+			return fmt.Errorf("noise probability must be between 0 and 1, got %g", p)
+		}
+	}
+	m.noise = noise
+	return nil
+}
+
+// Noise returns the noise model set with SetNoise
+func (m *QuantumRISCVMachine) Noise() NoiseModel {
+	return m.noise
+}
+
+// applyNoise runs the noise channels on the qubits of a gate that was just applied and records
+// them as a NOISE entry, so the history never claims to describe a noiseless circuit.
+// Measurements are left noiseless.
+func (m *QuantumRISCVMachine) applyNoise(r GateRecord) {
+	if m.noise == (NoiseModel{}) || isMeasurement(r.Name) {
+		return
+	}
+	qubits := append([]int{r.Target}, r.Controls...)
+	// The qubits were validated when the gate was applied, so neither channel can fail
+	m.state.ApplyBitFlipNoise(m.noise.BitFlip, m.rng, qubits...)
+	m.state.ApplyAmplitudeDamping(m.noise.AmplitudeDamping, m.rng, qubits...)
+	m.history = append(m.history, GateRecord{Name: "NOISE", Target: qubits[0], Controls: qubits[1:]})
+}
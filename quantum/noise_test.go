@@ -0,0 +1,177 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestApplyBitFlipNoise(t *testing.T) {
+	tests := []struct {
+		p        float64
+		wantFlip float64 // Expected fraction of runs that flip qubit 0
+		wantErr  bool
+	}{
+		{0, 0, false},
+		{1, 1, false},
+		{0.3, 0.3, false},
+		{-0.1, 0, true},
+		{1.5, 0, true},
+	}
+	const runs = 4000
+	for _, tt := range tests {
+		rng := NewSplitMix64(1)
+		flips := 0
+		for i := 0; i < runs; i++ {
+			state := NewQuantumState(1)
+			err := state.ApplyBitFlipNoise(tt.p, rng)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ApplyBitFlipNoise(%g) succeeded, want an error", tt.p)
+				}
+				break
+			}
+			if err != nil {
+				t.Fatalf("ApplyBitFlipNoise(%g): %v", tt.p, err)
+			}
+			flips += int(state.MeasureProbability(0, 1))
+		}
+		if got := float64(flips) / runs; !tt.wantErr && math.Abs(got-tt.wantFlip) > 0.03 {
+			t.Errorf("ApplyBitFlipNoise(%g) flipped %.3f of runs, want %.3f", tt.p, got, tt.wantFlip)
+		}
+	}
+}
+
+func TestApplyAmplitudeDamping(t *testing.T) {
+	tests := []struct {
+		gamma float64
+		want  float64 // Average P(1) after damping |+⟩, which starts at 1/2
+	}{
+		{0, 0.5},
+		{0.5, 0.25},
+		{1, 0},
+	}
+	const runs = 4000
+	for _, tt := range tests {
+		rng := NewSplitMix64(2)
+		var total float64
+		for i := 0; i < runs; i++ {
+			state := NewQuantumState(2)
+			H.Apply(state, 0, nil)
+			if err := state.ApplyAmplitudeDamping(tt.gamma, rng, 0); err != nil {
+				t.Fatal(err)
+			}
+			if norm := state.Norm(); math.Abs(norm-1) > 1e-12 {
+				t.Fatalf("damping(%g) left norm %g", tt.gamma, norm)
+			}
+			total += state.MeasureProbability(0, 1)
+		}
+		if got := total / runs; math.Abs(got-tt.want) > 0.02 {
+			t.Errorf("damping(%g): average P(1) = %.3f, want %.3f", tt.gamma, got, tt.want)
+		}
+	}
+}
+
+func TestRandomizedBenchmarkNoise(t *testing.T) {
+	tests := []struct {
+		noise       NoiseModel
+		minSurvival float64
+		maxSurvival float64
+	}{
+		{NoiseModel{}, 1 - 1e-9, 1 + 1e-9},
+		{NoiseModel{BitFlip: 0.05}, 0, 0.9},
+		{NoiseModel{AmplitudeDamping: 0.1}, 0, 0.99},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(1)
+		m.SetSeed(3)
+		if err := m.SetNoise(tt.noise); err != nil {
+			t.Fatal(err)
+		}
+		var total float64
+		const sequences = 20
+		for i := 0; i < sequences; i++ {
+			result, err := m.RandomizedBenchmark(40, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			total += result.Survival
+		}
+		if got := total / sequences; got < tt.minSurvival || got > tt.maxSurvival {
+			t.Errorf("noise %+v: average survival %.4f, want between %g and %g", tt.noise, got,
+				tt.minSurvival, tt.maxSurvival)
+		}
+	}
+}
+
+func TestSetNoiseValidates(t *testing.T) {
+	m := NewQuantumRISCVMachine(1)
+	for _, noise := range []NoiseModel{{BitFlip: -1}, {AmplitudeDamping: 2}, {BitFlip: math.NaN()}} {
+		if err := m.SetNoise(noise); err == nil {
+			t.Errorf("SetNoise(%+v) succeeded, want an error", noise)
+		}
+	}
+	if m.Noise() != (NoiseModel{}) {
+		t.Errorf("a rejected noise model was kept: %+v", m.Noise())
+	}
+}
+
+func TestNoiseAfterProgramGates(t *testing.T) {
+	tests := []struct {
+		name   string
+		noise  NoiseModel
+		source string
+		want   int // Basis state the program ends in
+	}{
+		{"noiseless", NoiseModel{}, "addi x10, x0, 1\nqgate.if x10, X, 0\n", 0b001},
+		// A certain bit flip undoes the X on the target and leaves untouched qubits alone
+		{"bit flip", NoiseModel{BitFlip: 1}, "addi x10, x0, 1\nqgate.if x10, X, 0\n", 0b000},
+		// CNOT touches control and target: q0 and q1 flip after the gate
+		{"bit flip on controls", NoiseModel{BitFlip: 1}, "addi x10, x0, 1\nqgate.c X, 0, 1\n", 0b011},
+		{"full damping", NoiseModel{AmplitudeDamping: 1}, "addi x10, x0, 1\nqgate.if x10, X, 2\n", 0b000},
+		// The measurement itself stays noiseless, so it does not flip q1 back
+		{"measure", NoiseModel{BitFlip: 1}, "addi x10, x0, 1\nqgate.if x10, X, 1\nqmeas.mem 1, x0\n", 0b000},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		m.SetSeed(1)
+		if err := m.SetNoise(tt.noise); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.LoadRISCSource(tt.source); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ExecuteRISCProgram(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if amp := m.GetState().GetAmplitude(tt.want); math.Abs(cmplx.Abs(amp)-1) > 1e-9 {
+			t.Errorf("%s: amplitude of |%03b⟩ is %v, want magnitude 1", tt.name, tt.want, amp)
+		}
+	}
+}
+
+func TestNoiseIsRecorded(t *testing.T) {
+	m := NewQuantumRISCVMachine(2)
+	if err := m.SetNoise(NoiseModel{BitFlip: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExecuteInstruction(Instruction{Opcode: 0x06, Target: 1, Controls: []int{0}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.MeasureQubit(0); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"CNOT q1 (controls: q0)", "NOISE q1 q0", "MEASURE q0"}
+	history := m.GetGateHistory()
+	if len(history) != len(want) {
+		t.Fatalf("history %v, want %v", history, want)
+	}
+	for i, record := range history {
+		if record.String() != want[i] {
+			t.Errorf("history entry %d = %s, want %s", i, record, want[i])
+		}
+	}
+	if est := m.EstimateResources(); est.Total != 1 || est.Measurements != 1 {
+		t.Errorf("resources %+v, want 1 gate and 1 measurement", est)
+	}
+}
@@ -0,0 +1,65 @@
+package quantum
+
+import "sort"
+
+// OpInfo describes one supported instruction or gate
+type OpInfo struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // "riscv", "quantum", "pseudo", "gate" or "custom"
+	Operands    string `json:"operands"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+}
+
+// replGates are the REPL gates without an instruction opcode, with their operands
+var replGates = map[string]string{
+	"RX": "target theta [controls...]", "RY": "target theta [controls...]", "RZ": "target theta [controls...]",
+	"P": "target theta [controls...]", "SWAP": "qubit qubit", "CCNOT": "target control control",
+}
+
+// kindOrder controls how SupportedOps groups its entries
+var kindOrder = map[string]int{"riscv": 0, "pseudo": 1, "quantum": 2, "gate": 3, "custom": 4}
+
+// SupportedOps lists every instruction and gate the machine accepts. It is generated from the
+// parser's instruction table, the pseudo-instruction table and the gate table, so it always
+// matches what is actually implemented; descriptions and examples come from opDocs.
+func SupportedOps() []OpInfo {
+	var ops []OpInfo
+	for name, spec := range instructionTable {
+		ops = append(ops, OpInfo{Name: name, Kind: spec.kind, Operands: spec.format.signature})
+	}
+	for name, spec := range pseudoTable {
+		ops = append(ops, OpInfo{Name: name, Kind: "pseudo", Operands: spec.signature})
+	}
+	for name := range customInstructions {
+		ops = append(ops, OpInfo{Name: name, Kind: "custom", Operands: "[rd, rs1, rs2 | imm]",
+			Description: "Custom instruction added with RegisterInstruction"})
+	}
+	for _, name := range gateNames {
+		switch name {
+		case "MEASURE":
+			continue // Measurement has its own REPL command rather than a gate form
+		case "CNOT":
+			ops = append(ops, OpInfo{Name: name, Kind: "gate", Operands: "target control"})
+		default:
+			ops = append(ops, OpInfo{Name: name, Kind: "gate", Operands: "target [controls...]"})
+		}
+	}
+
+	for name, operands := range replGates {
+		ops = append(ops, OpInfo{Name: name, Kind: "gate", Operands: operands})
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Kind != ops[j].Kind {
+			return kindOrder[ops[i].Kind] < kindOrder[ops[j].Kind]
+		}
+		return ops[i].Name < ops[j].Name
+	})
+	for i := range ops {
+		if doc, ok := opDocs[ops[i].Name]; ok {
+			ops[i].Description, ops[i].Example = doc.description, doc.example
+		}
+	}
+	return ops
+}
@@ -0,0 +1,382 @@
+package quantum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// instructionFormat describes the operand syntax shared by a class of instructions
+type instructionFormat struct {
+	signature string // Operand signature shown to users, e.g. "rd, rs1, imm"
+	operands  int    // Number of operand tokens expected after the mnemonic
+	parse     func(inst *RISCInstruction, ops []string) error
+}
+
+// Operand formats used by the instruction table
+var (
+	formatR        = instructionFormat{"rd, rs1, rs2", 3, parseRFormat}
+	formatI        = instructionFormat{"rd, rs1, imm", 3, parseIFormat}
+	formatU        = instructionFormat{"rd, imm", 2, parseUFormat}
+	formatJ        = instructionFormat{"rd, offset", 2, parseJFormat}
+	formatJALR     = instructionFormat{"rd, rs1, offset", 3, parseJALRFormat}
+	formatB        = instructionFormat{"rs1, rs2, offset", 3, parseBFormat}
+	formatLoad     = instructionFormat{"rd, offset(rs1)", 2, parseLoadFormat}
+	formatStore    = instructionFormat{"rs2, offset(rs1)", 2, parseStoreFormat}
+	formatQInit    = instructionFormat{"rd", 1, parseQInitFormat}
+	formatQApply   = instructionFormat{"rd, rs1, imm", 3, parseQApplyFormat}
+	formatQMeasure = instructionFormat{"rd, rs1", 2, parseQMeasureFormat}
+	formatQGateIf  = instructionFormat{"rs, GATE, target", 3, parseQGateIfFormat}
+	formatQGateR   = instructionFormat{"GATE, rs", 2, parseQGateRFormat}
+	formatQGateC   = instructionFormat{"GATE, control, target", 3, parseQGateCFormat}
+	formatQGateMC  = instructionFormat{"GATE, target, rs1, rs2", 4, parseQGateMCFormat}
+	formatQMeasMem = instructionFormat{"qubit, rs", 2, parseQMeasMemFormat}
+	formatNone     = instructionFormat{"", 0, parseNoOperands}
+)
+
+// instructionSpec is one entry of the instruction table
+type instructionSpec struct {
+	kind   string // "riscv" for the base ISA, "quantum" for the Q-RISC-V extensions
+	format instructionFormat
+}
+
+// instructionTable is the dispatch table of every instruction the parser accepts
+var instructionTable = map[string]instructionSpec{
+	"qinit":     {"quantum", formatQInit},
+	"qapply":    {"quantum", formatQApply},
+	"qmeasure":  {"quantum", formatQMeasure},
+	"qentangle": {"quantum", formatR},
+	"qmov":      {"quantum", formatQMeasure}, // Same "rd, rs1" operands as qmeasure
+	"qgate.if":  {"quantum", formatQGateIf},
+	"qgate.r":   {"quantum", formatQGateR},
+	"qgate.c":   {"quantum", formatQGateC},
+	"qgate.mc":  {"quantum", formatQGateMC},
+	"qmeas.mem": {"quantum", formatQMeasMem},
+
+	"add": {"riscv", formatR}, "sub": {"riscv", formatR}, "and": {"riscv", formatR},
+	"or": {"riscv", formatR}, "xor": {"riscv", formatR}, "sll": {"riscv", formatR},
+	"srl": {"riscv", formatR}, "sra": {"riscv", formatR}, "slt": {"riscv", formatR},
+	"sltu": {"riscv", formatR},
+
+	"mul": {"riscv", formatR}, "mulh": {"riscv", formatR}, "mulhu": {"riscv", formatR},
+	"mulhsu": {"riscv", formatR}, "div": {"riscv", formatR}, "divu": {"riscv", formatR},
+	"rem": {"riscv", formatR}, "remu": {"riscv", formatR},
+
+	"addi": {"riscv", formatI}, "slli": {"riscv", formatI}, "srli": {"riscv", formatI},
+	"srai": {"riscv", formatI}, "andi": {"riscv", formatI}, "ori": {"riscv", formatI},
+	"xori": {"riscv", formatI}, "slti": {"riscv", formatI}, "sltiu": {"riscv", formatI},
+
+	"lui": {"riscv", formatU}, "auipc": {"riscv", formatU},
+	"jal": {"riscv", formatJ}, "jalr": {"riscv", formatJALR},
+
+	"beq": {"riscv", formatB}, "bne": {"riscv", formatB}, "blt": {"riscv", formatB},
+	"bge": {"riscv", formatB}, "bltu": {"riscv", formatB}, "bgeu": {"riscv", formatB},
+
+	"lw": {"riscv", formatLoad}, "lh": {"riscv", formatLoad}, "lb": {"riscv", formatLoad},
+	"lwu": {"riscv", formatLoad}, "lhu": {"riscv", formatLoad}, "lbu": {"riscv", formatLoad},
+	"sw": {"riscv", formatStore}, "sh": {"riscv", formatStore}, "sb": {"riscv", formatStore},
+
+	"ecall": {"riscv", formatNone}, "ebreak": {"riscv", formatNone},
+}
+
+// IsKnownInstruction reports whether opcode is a real (non-pseudo) instruction the machine executes
+func IsKnownInstruction(opcode string) bool {
+	_, ok := instructionTable[opcode]
+	_, custom := customInstructions[opcode]
+	return ok || custom
+}
+
+// parseRISCInstruction parses a RISC-V instruction string, keeping its text as Source
+func parseRISCInstruction(instruction string) (RISCInstruction, error) {
+	source := stripComment(instruction)
+	parts := instructionFields(source)
+	if len(parts) == 0 {
+		return RISCInstruction{}, fmt.Errorf("empty instruction")
+	}
+
+	spec, ok := instructionTable[parts[0]]
+	if _, custom := customInstructions[parts[0]]; !ok && custom {
+		inst := RISCInstruction{Opcode: parts[0], Source: source}
+		return inst, parseCustomOperands(&inst, parts[1:])
+	}
+	if !ok {
+		return RISCInstruction{}, fmt.Errorf("unknown instruction: %s", parts[0])
+	}
+	if len(parts)-1 != spec.format.operands {
+		return RISCInstruction{}, fmt.Errorf("invalid number of arguments for %s", parts[0])
+	}
+
+	inst := RISCInstruction{Opcode: parts[0], Source: source}
+	if err := spec.format.parse(&inst, parts[1:]); err != nil {
+		return RISCInstruction{}, err
+	}
+	return inst, nil
+}
+
+// parseRegisters parses consecutive register operands into the given fields
+func parseRegisters(ops []string, fields ...*uint8) error {
+	for i, field := range fields {
+		reg, err := parseRegister(ops[i])
+		if err != nil {
+			return err
+		}
+		*field = reg
+	}
+	return nil
+}
+
+// parseImmediate parses a decimal, hex (0x), binary (0b), octal (0o) or character-literal
+// ('A', '\n') immediate operand that has no fixed field width
+func parseImmediate(op string) (int64, error) {
+	imm, _, err := parseIntLiteral(op)
+	if err != nil {
+		return 0, fmt.Errorf("invalid immediate value: %v", err)
+	}
+	return imm, nil
+}
+
+// parseOffset parses a jal offset operand
+func parseOffset(op string) (int64, error) {
+	offset, _, err := parseIntLiteral(op)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset value: %v", err)
+	}
+	return offset, nil
+}
+
+// isShift reports whether an I-type opcode takes an unsigned shift amount instead of a
+// signed 12-bit immediate
+func isShift(opcode string) bool {
+	return opcode == "slli" || opcode == "srli" || opcode == "srai"
+}
+
+// parseRFormat parses "rd, rs1, rs2"
+func parseRFormat(inst *RISCInstruction, ops []string) error {
+	return parseRegisters(ops, &inst.Rd, &inst.Rs1, &inst.Rs2)
+}
+
+// parseIFormat parses "rd, rs1, imm"; a register in the immediate slot turns the instruction into add
+func parseIFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rd, &inst.Rs1); err != nil {
+		return err
+	}
+	// Check if the immediate is a register reference
+	if strings.HasPrefix(ops[2], "x") {
+		inst.Opcode = "add"
+		return parseRegisters(ops[2:], &inst.Rs2)
+	}
+	parse := parseSignedField
+	if isShift(inst.Opcode) {
+		parse = parseImmediate
+	}
+	imm, err := parse(ops[2])
+	inst.Imm = imm
+	return err
+}
+
+// parseUFormat parses "rd, imm"
+func parseUFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rd); err != nil {
+		return err
+	}
+	imm, err := parseImmediate(ops[1])
+	inst.Imm = imm
+	return err
+}
+
+// parseJFormat parses "rd, offset"
+func parseJFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rd); err != nil {
+		return err
+	}
+	offset, err := parseOffset(ops[1])
+	inst.Offset = offset
+	return err
+}
+
+// parseJALRFormat parses "rd, rs1, offset"
+func parseJALRFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rd, &inst.Rs1); err != nil {
+		return err
+	}
+	offset, err := parseSignedField(ops[2])
+	inst.Offset = offset
+	return err
+}
+
+// parseBFormat parses "rs1, rs2, offset"
+func parseBFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rs1, &inst.Rs2); err != nil {
+		return err
+	}
+	offset, err := parseSignedField(ops[2])
+	inst.Offset = offset
+	return err
+}
+
+// parseLoadFormat parses "rd, offset(rs1)"
+func parseLoadFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rd); err != nil {
+		return err
+	}
+	rs1, offset, err := parseLoadStore(ops[1])
+	inst.Rs1, inst.Offset = rs1, offset
+	return err
+}
+
+// parseStoreFormat parses "rs2, offset(rs1)"
+func parseStoreFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rs2); err != nil {
+		return err
+	}
+	rs1, offset, err := parseLoadStore(ops[1])
+	inst.Rs1, inst.Offset = rs1, offset
+	return err
+}
+
+// parseNoOperands accepts instructions such as ecall that take no operands
+func parseNoOperands(inst *RISCInstruction, ops []string) error {
+	return nil
+}
+
+// parseQInitFormat parses "rd"
+func parseQInitFormat(inst *RISCInstruction, ops []string) error {
+	return parseRegisters(ops, &inst.Rd)
+}
+
+// parseQApplyFormat parses "rd, rs1, imm" where imm selects the gate
+func parseQApplyFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rd, &inst.Rs1); err != nil {
+		return err
+	}
+	imm, err := parseImmediate(ops[2])
+	inst.Imm = imm
+	return err
+}
+
+// parseQMeasureFormat parses "rd, rs1"
+func parseQMeasureFormat(inst *RISCInstruction, ops []string) error {
+	return parseRegisters(ops, &inst.Rd, &inst.Rs1)
+}
+
+// parseQGateIfFormat parses "rs, GATE, target"
+func parseQGateIfFormat(inst *RISCInstruction, ops []string) error {
+	if err := parseRegisters(ops, &inst.Rs1); err != nil {
+		return err
+	}
+	inst.Gate = strings.ToUpper(strings.TrimRight(ops[1], ","))
+	if _, ok := singleQubitGateOpcode(inst.Gate); !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", inst.Gate)
+	}
+	target, err := strconv.ParseInt(ops[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid target qubit: %v", err)
+	}
+	inst.Imm = target
+	return nil
+}
+
+// parseQGateRFormat parses "GATE, rs", where rs holds the target qubit index at run time
+func parseQGateRFormat(inst *RISCInstruction, ops []string) error {
+	inst.Gate = strings.ToUpper(strings.TrimRight(ops[0], ","))
+	if _, ok := singleQubitGateOpcode(inst.Gate); !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", inst.Gate)
+	}
+	return parseRegisters(ops[1:], &inst.Rs1)
+}
+
+// parseQGateCFormat parses "GATE, control, target": the target qubit goes in Imm and the control
+// qubit in Offset
+func parseQGateCFormat(inst *RISCInstruction, ops []string) error {
+	inst.Gate = strings.ToUpper(strings.TrimRight(ops[0], ","))
+	if _, ok := singleQubitGateOpcode(inst.Gate); !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", inst.Gate)
+	}
+	control, err := strconv.ParseInt(strings.TrimRight(ops[1], ","), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid control qubit: %v", err)
+	}
+	target, err := strconv.ParseInt(ops[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid target qubit: %v", err)
+	}
+	inst.Offset, inst.Imm = control, target
+	return nil
+}
+
+// parseQGateMCFormat parses "GATE, target, rs1, rs2", where rs1 holds the address of the control
+// qubit list and rs2 its length at run time
+func parseQGateMCFormat(inst *RISCInstruction, ops []string) error {
+	inst.Gate = strings.ToUpper(strings.TrimRight(ops[0], ","))
+	if _, ok := singleQubitGateOpcode(inst.Gate); !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", inst.Gate)
+	}
+	target, err := strconv.ParseInt(strings.TrimRight(ops[1], ","), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid target qubit: %v", err)
+	}
+	inst.Imm = target
+	return parseRegisters(ops[2:], &inst.Rs1, &inst.Rs2)
+}
+
+// parseQMeasMemFormat parses "qubit, rs", where rs holds the memory address of the result byte
+func parseQMeasMemFormat(inst *RISCInstruction, ops []string) error {
+	qubit, err := parseImmediate(ops[0])
+	if err != nil {
+		return fmt.Errorf("invalid qubit: %v", err)
+	}
+	inst.Imm = qubit
+	return parseRegisters(ops[1:], &inst.Rs1)
+}
+
+// parseRegister parses a register name (e.g., "x0", "x1", etc.)
+func parseRegister(reg string) (uint8, error) {
+	// Remove any trailing commas
+	reg = strings.TrimRight(reg, ",")
+
+	if num, ok := abiRegisters[reg]; ok {
+		return num, nil
+	}
+	if !strings.HasPrefix(reg, "x") {
+		return 0, fmt.Errorf("invalid register: %s (use x0-x%d or an ABI name such as a0, sp or t1)",
+			reg, NumRegisters-1)
+	}
+	num, err := strconv.ParseUint(reg[1:], 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid register number: %v", err)
+	}
+	if num >= NumRegisters {
+		return 0, fmt.Errorf("register number out of range: %d", num)
+	}
+	return uint8(num), nil
+}
+
+// abiRegisters maps the standard RISC-V ABI register names to x0-x31 (fp is an alias of s0)
+var abiRegisters = map[string]uint8{
+	"zero": 0, "ra": 1, "sp": 2, "gp": 3, "tp": 4, "t0": 5, "t1": 6, "t2": 7,
+	"s0": 8, "fp": 8, "s1": 9, "a0": 10, "a1": 11, "a2": 12, "a3": 13, "a4": 14, "a5": 15,
+	"a6": 16, "a7": 17, "s2": 18, "s3": 19, "s4": 20, "s5": 21, "s6": 22, "s7": 23,
+	"s8": 24, "s9": 25, "s10": 26, "s11": 27, "t3": 28, "t4": 29, "t5": 30, "t6": 31,
+}
+
+// parseLoadStore parses load/store instruction arguments (e.g., "4(x1)")
+func parseLoadStore(arg string) (uint8, int64, error) {
+	parts := strings.Split(arg, "(")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid load/store format: %s", arg)
+	}
+
+	offset, err := parseSignedField(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Remove any trailing commas and closing parenthesis
+	reg := strings.TrimRight(strings.TrimRight(parts[1], ","), ")")
+	rs1, err := parseRegister(reg)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return rs1, offset, nil
+}
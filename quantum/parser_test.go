@@ -0,0 +1,68 @@
+package quantum
+
+import "testing"
+
+func TestParseRegister(t *testing.T) {
+	tests := []struct {
+		reg     string
+		want    uint8
+		wantErr bool
+	}{
+		{"x0", 0, false},
+		{"x31,", 31, false},
+		{"x127", 127, false},
+		{"zero", 0, false},
+		{"ra", 1, false},
+		{"sp", 2, false},
+		{"gp", 3, false},
+		{"tp", 4, false},
+		{"t0", 5, false},
+		{"t2", 7, false},
+		{"s0", 8, false},
+		{"fp", 8, false},
+		{"s1", 9, false},
+		{"a0,", 10, false},
+		{"a7", 17, false},
+		{"s2", 18, false},
+		{"s11", 27, false},
+		{"t3", 28, false},
+		{"t6", 31, false},
+		{"x128", 0, true},
+		{"x-1", 0, true},
+		{"a8", 0, true},
+		{"s12", 0, true},
+		{"t7", 0, true},
+		{"SP", 0, true},
+		{"r1", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRegister(tt.reg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRegister(%q): error %v, want error %v", tt.reg, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("parseRegister(%q) = x%d, want x%d", tt.reg, got, tt.want)
+		}
+	}
+}
+
+func TestABIRegisterNamesInPrograms(t *testing.T) {
+	source := `li a0, 5
+li a1, 7
+add t0, a0, a1
+addi s1, t0, 0
+addi sp, sp, -4
+sw s1, 0(sp)
+lw a2, 0(sp)
+addi zero, zero, 9
+`
+	regs := runSource(t, source, DefaultXLEN)
+	want := map[int]uint64{0: 0, 5: 12, 9: 12, 10: 5, 11: 7, 12: 12, StackPointer: DefaultStackTop - 4}
+	for reg, value := range want {
+		if regs[reg] != value {
+			t.Errorf("x%d = %d, want %d", reg, regs[reg], value)
+		}
+	}
+}
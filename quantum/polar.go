@@ -0,0 +1,75 @@
+package quantum
+
+import "math/cmplx"
+
+// Polar phase mode: diagonal gates (Z, S, T, global phase) only rotate phases, but multiplying
+// rectangular complex numbers also perturbs each amplitude's magnitude by rounding error, so over
+// a deep phase-rotation circuit the magnitudes drift. In polar mode the state keeps each
+// amplitude's magnitude and diagonal gates re-render amplitudes from (magnitude, new phase), so
+// magnitudes stay exact across any run of phase gates. The cached magnitudes are refreshed after
+// any operation that changes magnitudes (every such operation calls invalidatePolar, directly or
+// through Normalize). The magnitude cache parallels a denseStore, so sparse states (see
+// backend.go) always apply phases in rectangular form.
+
+// SetPolarPhases enables or disables applying diagonal gates in polar form
+func (qs *QuantumState) SetPolarPhases(enabled bool) {
+	qs.polar = enabled
+	qs.invalidatePolar()
+}
+
+// PolarPhases reports whether diagonal gates are applied in polar form
+func (qs *QuantumState) PolarPhases() bool {
+	return qs.polar
+}
+
+// usePolar reports whether diagonal gates should take the polar path
+func (qs *QuantumState) usePolar() bool {
+	_, dense := qs.store.(denseStore)
+	return qs.polar && dense
+}
+
+// invalidatePolar drops the cached magnitudes after amplitudes changed magnitude
+func (qs *QuantumState) invalidatePolar() {
+	qs.magnitudes = nil
+}
+
+// rotatePhase multiplies amplitude i of a dense state by e^{iθ}, using its cached exact magnitude
+func (qs *QuantumState) rotatePhase(i int, theta float64) {
+	amplitudes := qs.store.(denseStore)
+	if len(qs.magnitudes) != len(amplitudes) {
+		qs.magnitudes = make([]float64, len(amplitudes))
+		for j, amp := range amplitudes {
+			qs.magnitudes[j] = cmplx.Abs(amp)
+		}
+	}
+	amplitudes[i] = cmplx.Rect(qs.magnitudes[i], cmplx.Phase(amplitudes[i])+theta)
+}
+
+// isDiagonal reports whether the gate only multiplies |0⟩ and |1⟩ by phases
+func (g *SingleQubitGate) isDiagonal() bool {
+	return g.matrix[0][1] == 0 && g.matrix[1][0] == 0
+}
+
+// applyPolarDiagonal applies a diagonal gate in place as phase rotations. The entries of a
+// unitary diagonal gate have magnitude 1 by definition, so only their phases are used.
+func (g *SingleQubitGate) applyPolarDiagonal(state *QuantumState, target int, controls []int) {
+	phases := [2]float64{cmplx.Phase(g.matrix[0][0]), cmplx.Phase(g.matrix[1][1])}
+	for i, amp := range state.store.(denseStore) {
+		if amp == 0 || !controlsSet(i, controls) {
+			continue
+		}
+		if theta := phases[(i>>target)&1]; theta != 0 {
+			state.rotatePhase(i, theta)
+		}
+	}
+}
+
+// controlsSet reports whether every control qubit is 1 in basis state index
+func controlsSet(index int, controls []int) bool {
+	for _, control := range controls {
+		if (index>>control)&1 == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// driftQubits and driftAngle shape the deep phase-rotation circuit of phaseDrift
+const (
+	driftQubits = 3
+	driftAngle  = 0.1234567
+)
+
+// phaseDrift applies gates alternating T and RZ(driftAngle) gates, cycling over the qubits of a
+// superposition, and returns how far the amplitudes' magnitudes and phases ended up from the
+// exact result
+func phaseDrift(polar bool, gates int) (magnitudeDrift, phaseDrift float64) {
+	state := NewQuantumState(driftQubits)
+	state.SetPolarPhases(polar)
+	for q := 0; q < driftQubits; q++ {
+		RY(0.5+0.4*float64(q)).Apply(state, q, nil)
+	}
+	before := state.GetAmplitudes()
+
+	rz := RZ(driftAngle)
+	var tCount, rzCount [driftQubits]int
+	for g := 0; g < gates; g++ {
+		q := g % driftQubits
+		if (g/driftQubits)%2 == 0 {
+			T.Apply(state, q, nil)
+			tCount[q]++
+		} else {
+			rz.Apply(state, q, nil)
+			rzCount[q]++
+		}
+	}
+
+	for i, amp := range state.GetAmplitudes() {
+		// T adds π/4 to |1⟩; RZ(θ) adds -θ/2 to |0⟩ and θ/2 to |1⟩
+		var shift float64
+		for q := 0; q < driftQubits; q++ {
+			sign := -1.0
+			if (i>>q)&1 == 1 {
+				shift += math.Mod(float64(tCount[q])*math.Pi/4, 2*math.Pi)
+				sign = 1
+			}
+			shift += sign * math.Mod(float64(rzCount[q])*driftAngle/2, 2*math.Pi)
+		}
+		magnitudeDrift = math.Max(magnitudeDrift, math.Abs(cmplx.Abs(amp)-cmplx.Abs(before[i])))
+		wrong := math.Remainder(cmplx.Phase(amp)-cmplx.Phase(before[i])-shift, 2*math.Pi)
+		phaseDrift = math.Max(phaseDrift, math.Abs(wrong))
+	}
+	return magnitudeDrift, phaseDrift
+}
+
+func TestPolarPhasesKeepMagnitudes(t *testing.T) {
+	magnitude, phase := phaseDrift(true, 100000)
+	if magnitude > 1e-15 {
+		t.Errorf("polar mode: magnitudes drifted by %g over 100000 phase gates", magnitude)
+	}
+	if phase > 1e-9 {
+		t.Errorf("polar mode: phases drifted by %g over 100000 phase gates", phase)
+	}
+}
+
+// BenchmarkPhaseDrift compares the accuracy of rectangular and polar phase gates over a deep
+// circuit of 10^6 T and RZ gates, reporting the largest magnitude and phase error of any amplitude
+// in units of 10^-15
+func BenchmarkPhaseDrift(b *testing.B) {
+	for _, mode := range []struct {
+		name  string
+		polar bool
+	}{{"rectangular", false}, {"polar", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			var magnitude, phase float64
+			for i := 0; i < b.N; i++ {
+				magnitude, phase = phaseDrift(mode.polar, 1000000)
+			}
+			b.ReportMetric(magnitude*1e15, "fmagnitude-drift")
+			b.ReportMetric(phase*1e15, "fphase-drift")
+		})
+	}
+}
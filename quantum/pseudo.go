@@ -0,0 +1,109 @@
+package quantum
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+// pseudoSpec describes a pseudo-instruction and how it expands
+type pseudoSpec struct {
+	signature string // Operand signature shown to users
+	operands  int    // Number of operand tokens expected after the mnemonic
+	expand    func(ops []string, xlen int) ([]string, error)
+}
+
+// pseudoTable lists every pseudo-instruction the assembler expands
+var pseudoTable = map[string]pseudoSpec{
+	"li": {"rd, imm", 2, expandLoadImmediate},
+}
+
+// expandPseudo expands a pseudo-instruction into the real instructions that implement it.
+// Lines that are not pseudo-instructions are returned unchanged.
+func expandPseudo(text string, xlen int) ([]string, error) {
+	fields := instructionFields(text)
+	if len(fields) == 0 {
+		return []string{text}, nil
+	}
+	spec, ok := pseudoTable[fields[0]]
+	if !ok {
+		return []string{text}, nil
+	}
+	if len(fields)-1 != spec.operands {
+		return nil, fmt.Errorf("invalid number of arguments for %s", fields[0])
+	}
+	return spec.expand(fields[1:], xlen)
+}
+
+// expandLoadImmediate expands "li rd, imm". On RV32 the constant must fit in 32 bits and is
+// sign-extended, so "li x1, 0xFFFFFFFF" loads -1 with a lui/addi pair as RV32 assemblers do.
+func expandLoadImmediate(ops []string, xlen int) ([]string, error) {
+	rd, err := parseRegister(ops[0])
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseImmediate64(ops[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid immediate value: %v", err)
+	}
+	if xlen == 32 {
+		if value < -(1<<31) || value >= 1<<32 {
+			return nil, fmt.Errorf("immediate %s does not fit in 32 bits", ops[1])
+		}
+		value = signExtend(value, 32)
+	}
+	return materializeConstant(rd, value), nil
+}
+
+// parseImmediate64 parses a decimal, hex (0x), octal (0o), binary (0b) or character ('A') constant.
+// Unsigned values above the int64 range keep their bit pattern (e.g. 0xFFFFFFFFFFFFFFFF is -1).
+func parseImmediate64(s string) (int64, error) {
+	if isCharLiteral(s) {
+		return parseCharLiteral(s)
+	}
+	if value, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return value, nil
+	}
+	value, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(value), nil
+}
+
+// materializeConstant builds the lui/addi/slli sequence an assembler emits for `li rd, value`.
+// Values that fit a signed 32-bit lui/addi pair use at most two instructions; wider values
+// are built recursively from their upper bits, shifted into place, plus the low 12 bits.
+func materializeConstant(rd uint8, value int64) []string {
+	lo12 := signExtend(value&0xFFF, 12)
+	hi20 := (value + 0x800) >> 12
+
+	if hi20 >= -(1<<19) && hi20 < 1<<19 {
+		if hi20 == 0 {
+			return []string{fmt.Sprintf("addi x%d, x0, %d", rd, lo12)}
+		}
+		seq := []string{fmt.Sprintf("lui x%d, %d", rd, hi20)}
+		if lo12 != 0 {
+			seq = append(seq, fmt.Sprintf("addi x%d, x%d, %d", rd, rd, lo12))
+		}
+		return seq
+	}
+
+	// Strip the low 12 bits, then drop trailing zeros so the upper part is as small as possible
+	upper := (value - lo12) >> 12
+	shift := 12 + bits.TrailingZeros64(uint64(upper))
+	upper = (value - lo12) >> shift
+
+	seq := materializeConstant(rd, upper)
+	seq = append(seq, fmt.Sprintf("slli x%d, x%d, %d", rd, rd, shift))
+	if lo12 != 0 {
+		seq = append(seq, fmt.Sprintf("addi x%d, x%d, %d", rd, rd, lo12))
+	}
+	return seq
+}
+
+// signExtend interprets the low `width` bits of value as a two's-complement number
+func signExtend(value int64, width uint) int64 {
+	shift := 64 - width
+	return (value << shift) >> shift
+}
@@ -0,0 +1,88 @@
+package quantum
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// qasmGates maps recorded single-qubit gate names to their qelib1.inc names
+var qasmGates = map[string]string{
+	"X": "x", "Y": "y", "Z": "z", "H": "h", "S": "s", "T": "t",
+	"SDG": "sdg", "TDG": "tdg", "I": "id", "RX": "rx", "RY": "ry", "RZ": "rz",
+	"P": "u1",
+}
+
+// qasmControlledGates gives the qelib1.inc form of gates with one control qubit. Controlled phase
+// gates become cu1 and controlled RX/RY become cu3; %s is the angle of parameterized gates.
+var qasmControlledGates = map[string]string{
+	"X": "cx", "Y": "cy", "Z": "cz", "H": "ch",
+	"S": "cu1(pi/2)", "T": "cu1(pi/4)", "SDG": "cu1(-pi/2)", "TDG": "cu1(-pi/4)",
+	"RX": "cu3(%s,-pi/2,pi/2)", "RY": "cu3(%s,0,0)", "RZ": "crz(%s)", "P": "cu1(%s)",
+}
+
+// ExportQASM writes the recorded gate history as an OpenQASM 2.0 program that reproduces it from
+// |0...0⟩ (the history starts at the last reset or import-state). The quantum register covers
+// qubits 0 up to the highest qubit used, and measurements write a classical register of the same
+// size. Operations OpenQASM 2.0 cannot express, such as joint parity measurements or gates with
+// several controls (other than the Toffoli), are reported as errors before anything is written.
+func (m *QuantumRISCVMachine) ExportQASM(w io.Writer) error {
+	lines := make([]string, 0, len(m.history))
+	width, measured := 1, false
+	for _, record := range m.history {
+		line, err := qasmStatement(record)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line...)
+		for _, q := range append([]int{record.Target}, record.Controls...) {
+			width = max(width, q+1)
+		}
+		measured = measured || record.Name == "MEASURE"
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "OPENQASM 2.0;\ninclude \"qelib1.inc\";\nqreg q[%d];\n", width)
+	if measured {
+		fmt.Fprintf(out, "creg c[%d];\n", width)
+	}
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+	return out.Flush()
+}
+
+// qasmStatement translates one recorded operation into OpenQASM 2.0 statements
+func qasmStatement(r GateRecord) ([]string, error) {
+	angle := fmt.Sprintf("%.17g", r.Angle)
+	switch {
+	case r.Name == "MEASURE":
+		return []string{fmt.Sprintf("measure q[%d] -> c[%d];", r.Target, r.Target)}, nil
+	case r.Name == "SWAP": // Three alternating CNOTs exchange two qubits
+		a, b := r.Target, r.Controls[0]
+		return []string{
+			fmt.Sprintf("cx q[%d],q[%d];", a, b),
+			fmt.Sprintf("cx q[%d],q[%d];", b, a),
+			fmt.Sprintf("cx q[%d],q[%d];", a, b),
+		}, nil
+	case r.Name == "CNOT" && len(r.Controls) == 1:
+		return []string{fmt.Sprintf("cx q[%d],q[%d];", r.Controls[0], r.Target)}, nil
+	case (r.Name == "X" || r.Name == "CNOT") && len(r.Controls) == 2:
+		return []string{fmt.Sprintf("ccx q[%d],q[%d],q[%d];", r.Controls[0], r.Controls[1], r.Target)}, nil
+	case r.Name == "I": // The identity is the same with or without controls
+		return []string{fmt.Sprintf("id q[%d];", r.Target)}, nil
+	case len(r.Controls) == 0 && qasmGates[r.Name] != "":
+		name := qasmGates[r.Name]
+		if IsRotationGate(r.Name) {
+			name += "(" + angle + ")"
+		}
+		return []string{fmt.Sprintf("%s q[%d];", name, r.Target)}, nil
+	case len(r.Controls) == 1 && qasmControlledGates[r.Name] != "":
+		name := qasmControlledGates[r.Name]
+		if IsRotationGate(r.Name) {
+			name = fmt.Sprintf(name, angle)
+		}
+		return []string{fmt.Sprintf("%s q[%d],q[%d];", name, r.Controls[0], r.Target)}, nil
+	}
+	return nil, fmt.Errorf("cannot export %s: OpenQASM 2.0 has no equivalent operation", r)
+}
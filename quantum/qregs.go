@@ -0,0 +1,117 @@
+package quantum
+
+import "fmt"
+
+// Quantum registers: qinit, qapply, qmeasure, qmov and qentangle operate on small states held in
+// quantumRegs, separate from the machine qubits that gates and qgate.* act on. The VM mirrors the
+// host's semantics (HostQuantumMachine.ExecuteQuantumRISCV) so a program behaves the same in
+// both execution modes, including the "quantum register xN not initialized" error on use before
+// qinit.
+
+// registerGates maps a qapply immediate to the gate it applies to qubit 0 of the register
+var registerGates = map[int64]*SingleQubitGate{0: X, 1: Y, 2: Z, 3: H, 4: S, 5: T}
+
+// executeQuantumRegister executes one quantum register instruction
+func (m *QuantumRISCVMachine) executeQuantumRegister(inst RISCInstruction) error {
+	switch inst.Opcode {
+	case "qinit":
+		// Initialize a quantum register with |0⟩ state
+		m.quantumRegs[inst.Rd] = NewQuantumState(1)
+	case "qapply":
+		state, err := m.quantumRegister(inst.Rs1)
+		if err != nil {
+			return err
+		}
+		if err := applyRegisterGate(state, inst.Imm); err != nil {
+			return err
+		}
+		m.countGate(gateNames[uint8(inst.Imm)])
+	case "qmeasure":
+		// Measure qubit 0 of the register, collapse it and write the outcome to rd
+		state, err := m.quantumRegister(inst.Rs1)
+		if err != nil {
+			return err
+		}
+		result, err := state.Measure(0, m.rng)
+		if err != nil {
+			return fmt.Errorf("error measuring quantum register: %v", err)
+		}
+		m.stats.Measurements++
+		if m.onMeasure != nil {
+			m.onMeasure(int(inst.Rs1), uint64(result.Outcome), result.Probability)
+		}
+		m.setRegister(inst.Rd, uint64(result.Outcome))
+	case "qmov":
+		// Move a quantum register reference; the source is invalidated because no-cloning forbids copies
+		if _, err := m.quantumRegister(inst.Rs1); err != nil {
+			return err
+		}
+		if inst.Rd != inst.Rs1 {
+			m.quantumRegs[inst.Rd] = m.quantumRegs[inst.Rs1]
+			m.quantumRegs[inst.Rs1] = nil
+		}
+	case "qentangle":
+		if m.quantumRegs[inst.Rs1] == nil || m.quantumRegs[inst.Rs2] == nil {
+			return fmt.Errorf("quantum registers not initialized")
+		}
+		entangled, err := entangleRegisters(m.quantumRegs[inst.Rs1], m.quantumRegs[inst.Rs2])
+		if err != nil {
+			return err
+		}
+		m.quantumRegs[inst.Rd] = entangled
+		m.countGate("H")
+		m.countGate("CNOT")
+	default:
+		return fmt.Errorf("unknown quantum instruction: %s", inst.Opcode)
+	}
+	return nil
+}
+
+// quantumRegister returns the state held in quantum register reg, or an error before qinit
+func (m *QuantumRISCVMachine) quantumRegister(reg uint8) (*QuantumState, error) {
+	if m.quantumRegs[reg] == nil {
+		return nil, fmt.Errorf("quantum register x%d not initialized", reg)
+	}
+	return m.quantumRegs[reg], nil
+}
+
+// applyRegisterGate applies the qapply gate `imm` to a register state. Single-qubit gates act on
+// qubit 0; CNOT (6) needs a two-qubit register and flips qubit 0 when qubit 1 is set; I (8) is
+// a no-op.
+func applyRegisterGate(state *QuantumState, imm int64) error {
+	switch imm {
+	case 6:
+		if err := RequireQubits("CNOT", 2, state.NumQubits()); err != nil {
+			return err
+		}
+		X.Apply(state, 0, []int{1})
+		return nil
+	case 8:
+		return nil
+	}
+	gate, ok := registerGates[imm]
+	if !ok {
+		return fmt.Errorf("error applying quantum gate: unknown opcode: %x", imm)
+	}
+	gate.Apply(state, 0, nil)
+	return nil
+}
+
+// entangleRegisters mirrors the host's qentangle: the tensor product of two single-qubit states
+// (first is qubit 0, second qubit 1), followed by H on qubit 0 and a CNOT from qubit 0 to qubit 1.
+// Two |0⟩ inputs give the Bell state |Φ+⟩ = (|00⟩ + |11⟩)/√2.
+func entangleRegisters(first, second *QuantumState) (*QuantumState, error) {
+	if first.NumQubits() != 1 || second.NumQubits() != 1 {
+		return nil, fmt.Errorf("qentangle needs two single-qubit registers, got %d and %d qubits",
+			first.NumQubits(), second.NumQubits())
+	}
+	entangled := NewUninitializedQuantumState(2)
+	for q0 := 0; q0 < 2; q0++ {
+		for q1 := 0; q1 < 2; q1++ {
+			entangled.SetAmplitude(q0|q1<<1, first.GetAmplitude(q0)*second.GetAmplitude(q1))
+		}
+	}
+	H.Apply(entangled, 0, nil)
+	X.Apply(entangled, 1, []int{0})
+	return entangled, nil
+}
@@ -0,0 +1,87 @@
+package quantum
+
+import "testing"
+
+// runBothModes runs source on a VM machine and on a host machine loaded with the same program,
+// returning each machine's final registers and execution error
+func runBothModes(t *testing.T, source string) (vmRegs, hostRegs [NumRegisters]uint64, vmErr, hostErr error) {
+	t.Helper()
+	vm := NewQuantumRISCVMachine(2)
+	vm.SetSeed(1)
+	if err := vm.LoadRISCSource(source); err != nil {
+		t.Fatalf("loading %q: %v", source, err)
+	}
+	host := NewHostQuantumMachine(2)
+	host.SetSeed(1)
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+	vmErr, hostErr = vm.ExecuteRISCProgram(), host.ExecuteProgram()
+	return vm.GetRegisters(), host.GetRegisters(), vmErr, hostErr
+}
+
+func TestQMov(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantX5  uint64
+		wantErr bool
+	}{
+		{"move keeps the state", "qinit x1\nqapply x1, x1, 0\nqmov x2, x1\nqmeasure x5, x2\n", 1, false},
+		{"move onto itself", "qinit x1\nqapply x1, x1, 0\nqmov x1, x1\nqmeasure x5, x1\n", 1, false},
+		{"source invalidated", "qinit x1\nqmov x2, x1\nqmeasure x5, x1\n", 0, true},
+		{"uninitialized source", "qmov x2, x1\n", 0, true},
+	}
+	for _, tt := range tests {
+		vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, tt.source)
+		for mode, err := range map[string]error{"VM": vmErr, "host": hostErr} {
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s (%s): error %v, want error %v", tt.name, mode, err, tt.wantErr)
+			}
+		}
+		if !tt.wantErr && (vmRegs[5] != tt.wantX5 || hostRegs[5] != tt.wantX5) {
+			t.Errorf("%s: x5 = %d (VM), %d (host), want %d", tt.name, vmRegs[5], hostRegs[5], tt.wantX5)
+		}
+	}
+}
+
+func TestQuantumRegistersMatchHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantX5  uint64
+		wantErr bool
+	}{
+		{"fresh register", "qinit x1\nqmeasure x5, x1\n", 0, false},
+		{"X", "qinit x1\nqapply x1, x1, 0\nqmeasure x5, x1\n", 1, false},
+		{"Y", "qinit x1\nqapply x1, x1, 1\nqmeasure x5, x1\n", 1, false},
+		{"Z keeps |1⟩", "qinit x1\nqapply x1, x1, 0\nqapply x1, x1, 2\nqmeasure x5, x1\n", 1, false},
+		{"H twice", "qinit x1\nqapply x1, x1, 3\nqapply x1, x1, 3\nqmeasure x5, x1\n", 0, false},
+		{"HZH is X", "qinit x1\nqapply x1, x1, 3\nqapply x1, x1, 2\nqapply x1, x1, 3\nqmeasure x5, x1\n", 1, false},
+		{"I", "qinit x1\nqapply x1, x1, 8\nqmeasure x5, x1\n", 0, false},
+		// CNOT (control qubit 1) turns the Bell pair into (|00⟩ + |10⟩)/√2: qubit 0 is 0
+		{"CNOT on a Bell pair", "qinit x1\nqinit x2\nqentangle x3, x1, x2\nqapply x3, x3, 6\nqmeasure x5, x3\n",
+			0, false},
+		{"qapply before qinit", "qapply x1, x1, 0\n", 0, true},
+		{"qmeasure before qinit", "qmeasure x5, x1\n", 0, true},
+		{"qentangle before qinit", "qinit x1\nqentangle x3, x1, x2\n", 0, true},
+		{"CNOT on one qubit", "qinit x1\nqapply x1, x1, 6\n", 0, true},
+		{"unknown gate", "qinit x1\nqapply x1, x1, 9\n", 0, true},
+	}
+	for _, tt := range tests {
+		vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, tt.source)
+		for mode, err := range map[string]error{"VM": vmErr, "host": hostErr} {
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s (%s): error %v, want error %v", tt.name, mode, err, tt.wantErr)
+			}
+		}
+		if !tt.wantErr && (vmRegs[5] != tt.wantX5 || hostRegs[5] != tt.wantX5) {
+			t.Errorf("%s: x5 = %d (VM), %d (host), want %d", tt.name, vmRegs[5], hostRegs[5], tt.wantX5)
+		}
+	}
+}
+
+func TestQuantumRegistersLeaveMachineQubits(t *testing.T) {
+	m := runMachine(t, "qinit x1\nqapply x1, x1, 0\nqmeasure x5, x1\n", 2)
+	if p := m.GetState().MeasureProbability(0, 1); p != 0 {
+		t.Errorf("qapply on a quantum register changed machine qubit 0: P(1) = %g", p)
+	}
+}
@@ -0,0 +1,48 @@
+package quantum
+
+// ResourceEstimate summarizes the recorded circuit with the cost metrics of fault-tolerant
+// quantum computing, where T gates dominate: Clifford gates are cheap to implement on an error
+// corrected machine, while each T gate needs an expensive magic state
+type ResourceEstimate struct {
+	TCount       int // T and T† gates
+	CNOTCount    int // CNOTs (X gates with one control); a SWAP counts as the three CNOTs it takes
+	Clifford     int // Other uncontrolled Clifford gates (X, Y, Z, H, S, S†)
+	Rotations    int // Arbitrary-angle rotations, each costing many T gates once synthesized
+	Other        int // Remaining controlled gates (controlled phases, Toffolis, ...)
+	Measurements int // Measurements, including parity measurements and forced collapses
+	Total        int // Every gate except identities, noise and measurements
+}
+
+// cliffordGateNames are the recorded single-qubit gates that are Clifford gates when uncontrolled
+var cliffordGateNames = map[string]bool{"X": true, "Y": true, "Z": true, "H": true, "S": true, "SDG": true}
+
+// EstimateResources aggregates the gate history into a ResourceEstimate
+func (m *QuantumRISCVMachine) EstimateResources() ResourceEstimate {
+	var est ResourceEstimate
+	for _, record := range m.history {
+		_, rotation := rotationGates[record.Name]
+		switch {
+		case isMeasurement(record.Name):
+			est.Measurements++
+			continue
+		case record.Name == "I" || record.Name == "NOISE":
+			continue
+		case record.Name == "SWAP":
+			est.CNOTCount += 3
+			est.Total += 3
+			continue
+		case len(record.Controls) == 0 && (record.Name == "T" || record.Name == "TDG"):
+			est.TCount++
+		case len(record.Controls) == 1 && (record.Name == "CNOT" || record.Name == "X"):
+			est.CNOTCount++
+		case len(record.Controls) == 0 && cliffordGateNames[record.Name]:
+			est.Clifford++
+		case rotation:
+			est.Rotations++
+		default:
+			est.Other++
+		}
+		est.Total++
+	}
+	return est
+}
@@ -0,0 +1,44 @@
+package quantum
+
+import "testing"
+
+func TestEstimateResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []GateRecord
+		want    ResourceEstimate
+	}{
+		{"empty", nil, ResourceEstimate{}},
+		{"T and T†", []GateRecord{{Name: "T"}, {Name: "TDG", Target: 1}}, ResourceEstimate{TCount: 2, Total: 2}},
+		{"CNOT", []GateRecord{{Name: "CNOT", Target: 1, Controls: []int{0}}, {Name: "X", Target: 0, Controls: []int{1}}},
+			ResourceEstimate{CNOTCount: 2, Total: 2}},
+		{"SWAP is three CNOTs", []GateRecord{{Name: "SWAP", Target: 0, Controls: []int{1}}},
+			ResourceEstimate{CNOTCount: 3, Total: 3}},
+		{"Clifford", []GateRecord{{Name: "H"}, {Name: "S"}, {Name: "SDG"}, {Name: "Z"}},
+			ResourceEstimate{Clifford: 4, Total: 4}},
+		{"rotations", []GateRecord{{Name: "RX", Angle: 0.3}, {Name: "RZ", Angle: 1}},
+			ResourceEstimate{Rotations: 2, Total: 2}},
+		{"other controlled", []GateRecord{{Name: "X", Target: 2, Controls: []int{0, 1}},
+			{Name: "T", Target: 1, Controls: []int{0}}, {Name: "H", Target: 1, Controls: []int{0}}},
+			ResourceEstimate{Other: 3, Total: 3}},
+		{"measurements and identities", []GateRecord{{Name: "MEASURE"}, {Name: "PARITY", Controls: []int{1}},
+			{Name: "I"}}, ResourceEstimate{Measurements: 2}},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		m.history = tt.history
+		if got := m.EstimateResources(); got != tt.want {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateResourcesOfProgram(t *testing.T) {
+	// A Bell pair and a T on each half, then a measurement of both qubits
+	m := runMachine(t, "addi x5, x0, 1\nqgate.if x5, H, 0\nqgate.c X, 0, 1\nqgate.if x5, T, 0\nqgate.if x5, T, 1\n"+
+		"addi x10, x0, 0x100\nqmeas.mem 0, x10\nqmeas.mem 1, x10\n", 2)
+	want := ResourceEstimate{TCount: 2, CNOTCount: 1, Clifford: 1, Measurements: 2, Total: 4}
+	if got := m.EstimateResources(); got != want {
+		t.Errorf("%+v, want %+v", got, want)
+	}
+}
@@ -2,17 +2,22 @@ package quantum
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
+	"time"
 )
 
+// NumRegisters is the size of the classical and quantum register files (x0-x127)
+const NumRegisters = 128
+
 // Instruction represents a RISC-V instruction for quantum operations
 type Instruction struct {
 	Opcode    uint8
-	Target    uint8
-	Controls  []uint8
+	Target    int   // Qubit the gate acts on
+	Controls  []int // Control qubits (the gate applies only when all are 1)
 	Immediate uint16
 }
 
@@ -24,6 +29,18 @@ type RISCInstruction struct {
 	Rs2    uint8
 	Imm    int64
 	Offset int64
+	Gate   string // Gate mnemonic for gate-applying instructions (qgate.*)
+
+	Source     string // Instruction text as written (comments and labels stripped)
+	LineNumber int    // 1-based source line, or 0 when not loaded from a file
+}
+
+// Position describes where an instruction sits, e.g. "PC 3 (line 7: bne x1, x2, loop)"
+func (inst RISCInstruction) Position(pc uint32) string {
+	if inst.LineNumber == 0 {
+		return fmt.Sprintf("PC %d", pc)
+	}
+	return fmt.Sprintf("PC %d (line %d: %s)", pc, inst.LineNumber, inst.Source)
 }
 
 // QuantumRISCVMachine represents our quantum computer with RISC-V instruction set
@@ -35,19 +52,124 @@ type QuantumRISCVMachine struct {
 	registers   [128]uint64
 	quantumRegs [128]*QuantumState
 	memory      []byte
+	rng         RNG
+	rngName     string // Measurement RNG algorithm (see NewRNG)
+	seed        int64  // Seed the RNG was last initialized from
+	onMeasure   MeasurementObserver
+	history     []GateRecord
+	cbits       map[string]int     // Classical-bit store written by MeasureToBit
+	ignored     []IgnoredDirective // Directives the loaded program used that the assembler skipped
+
+	xlen        int           // Register width in bits (32 for RV32, 64 for RV64)
+	skipUnknown bool          // Load unknown instructions as no-ops instead of rejecting the program
+	skipped     int           // Unknown instructions skipped during the last program run
+	executed    int           // Instructions executed during the last program run
+	detectLoops bool          // Stop with an error when a backward jump repeats an earlier machine state
+	entry       string        // Label where ExecuteRISCProgram starts ("" starts at instruction 0)
+	entryPC     uint32        // Resolved instruction index of entry
+	jumped      bool          // The executing instruction moved the PC itself (taken branch or jump)
+	freshRun    bool          // ExecuteRISCProgram resets the quantum state before running
+	globals     []string      // Symbols the loaded program declared with .globl
+	data        []byte        // The loaded program's .data section (copied into memory at DataBase)
+	output      io.Writer     // Where ecall print syscalls write (nil means standard output)
+	breakpoints breakpointSet // Instruction indices where Continue stops
+	stats       Stats         // Instructions, gates and measurements executed (see GetStats)
+	noise       NoiseModel    // Noise channels applied after each gate (zero: noiseless)
+	stackTop    uint64        // Initial sp set by SetStackTop, restored by Reset
 }
 
 // NewQuantumRISCVMachine creates a new quantum RISC-V machine
 func NewQuantumRISCVMachine(numQubits int) *QuantumRISCVMachine {
-	return &QuantumRISCVMachine{
+	seed := time.Now().UnixNano()
+	m := &QuantumRISCVMachine{
 		state:       NewQuantumState(numQubits),
 		program:     make([]Instruction, 0),
 		riscProgram: make([]RISCInstruction, 0),
 		pc:          0,
 		registers:   [128]uint64{},
 		quantumRegs: [128]*QuantumState{},
-		memory:      make([]byte, 1024*1024), // 1MB of memory
+		memory:      make([]byte, MemorySize), // 1MB of memory
+		rng:         NewSplitMix64(seed),
+		rngName:     DefaultRNG,
+		seed:        seed,
+		xlen:        DefaultXLEN,
+		stackTop:    DefaultStackTop,
+	}
+	m.registers[StackPointer] = DefaultStackTop // Like a loader, point sp at the top of memory
+	return m
+}
+
+// SetXLEN selects RV32 (32) or RV64 (64) register width; results wrap modulo 2^XLEN
+func (m *QuantumRISCVMachine) SetXLEN(xlen int) error {
+	if err := validateXLEN(xlen); err != nil {
+		return err
+	}
+	m.xlen = xlen
+	return nil
+}
+
+// GetXLEN returns the register width in bits
+func (m *QuantumRISCVMachine) GetXLEN() int {
+	return m.xlen
+}
+
+// SetEntry selects the label where the next loaded program starts executing ("" for instruction 0)
+func (m *QuantumRISCVMachine) SetEntry(label string) {
+	m.entry = label
+}
+
+// EntryPC returns the instruction index the loaded program starts at
+func (m *QuantumRISCVMachine) EntryPC() uint32 {
+	return m.entryPC
+}
+
+// GetGlobalSymbols returns the symbols the loaded program declared with .globl, sorted
+func (m *QuantumRISCVMachine) GetGlobalSymbols() []string {
+	return m.globals
+}
+
+// SetSkipUnknown makes LoadRISCProgram accept unknown instructions, which then execute as no-ops
+func (m *QuantumRISCVMachine) SetSkipUnknown(skip bool) {
+	m.skipUnknown = skip
+}
+
+// DataSegment returns the loaded program's .data section and the address it is loaded at
+func (m *QuantumRISCVMachine) DataSegment() (uint32, []byte) {
+	return DataBase, m.data
+}
+
+// IgnoredDirectives returns the unimplemented directives the loaded program used, in source order
+func (m *QuantumRISCVMachine) IgnoredDirectives() []IgnoredDirective {
+	return m.ignored
+}
+
+// ExecutedInstructions returns how many instructions the last program run executed
+func (m *QuantumRISCVMachine) ExecutedInstructions() int {
+	return m.executed
+}
+
+// SkippedInstructions returns how many unknown instructions the last program run skipped
+func (m *QuantumRISCVMachine) SkippedInstructions() int {
+	return m.skipped
+}
+
+// setRegister writes a result to rd, masked to XLEN bits; writes to x0 are discarded
+func (m *QuantumRISCVMachine) setRegister(rd uint8, value uint64) {
+	if rd == 0 {
+		return // x0 is hardwired to zero
 	}
+	m.registers[rd] = maskXLEN(value, m.xlen)
+}
+
+// SetSeed re-initializes the measurement RNG so subsequent measurements are reproducible
+func (m *QuantumRISCVMachine) SetSeed(seed int64) {
+	m.seed = seed
+	m.rng, _ = NewRNG(m.rngName, seed) // rngName is validated by SetRNGAlgorithm
+}
+
+// Seed returns the seed the measurement RNG was last initialized from
+func (m *QuantumRISCVMachine) Seed() int64 {
+	return m.seed
 }
 
 // LoadRISCProgram loads a RISC-V program from a file
@@ -71,44 +193,83 @@ func (m *QuantumRISCVMachine) LoadRISCProgram(filename string) error {
 	if err != nil {
 		return fmt.Errorf("error reading file: %v", err)
 	}
+	return m.LoadRISCSource(string(content))
+}
 
-	lines := strings.Split(string(content), "\n")
-	m.riscProgram = make([]RISCInstruction, 0)
+// LoadRISCSource assembles program source text and makes it the loaded program
+func (m *QuantumRISCVMachine) LoadRISCSource(source string) error {
+	asm, err := assembleProgram(source, assembleOptions{xlen: m.xlen, skipUnknown: m.skipUnknown})
+	if err != nil {
+		return err
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	entryPC := uint32(0)
+	if m.entry != "" {
+		if entryPC, err = asm.entryPoint(m.entry); err != nil {
+			return err
 		}
-
-		inst, err := parseRISCInstruction(line)
-		if err != nil {
-			return fmt.Errorf("error parsing instruction '%s': %v", line, err)
-		}
-		m.riscProgram = append(m.riscProgram, inst)
 	}
+	if DataBase+len(asm.data) > len(m.memory) {
+		return fmt.Errorf("the .data section (%d bytes) does not fit in memory", len(asm.data))
+	}
+	m.riscProgram = asm.program
+	m.breakpoints = nil // Indices of the previous program mean nothing in the new one
+	m.entryPC = entryPC
+	m.pc = entryPC
+	m.data = asm.data
+	m.ignored = asm.ignored
+	copy(m.memory[DataBase:], asm.data)
+	m.globals = make([]string, 0, len(asm.globals))
+	for symbol := range asm.globals {
+		m.globals = append(m.globals, symbol)
+	}
+	sort.Strings(m.globals)
 
 	return nil
 }
 
-// ExecuteRISCInstruction executes a single RISC-V instruction
+// ExecuteRISCInstruction executes a single RISC-V instruction (pseudo-instructions such as li
+// execute their whole expansion)
 func (m *QuantumRISCVMachine) ExecuteRISCInstruction(instruction string) error {
-	inst, err := parseRISCInstruction(instruction)
+	expanded, err := expandPseudo(stripComment(instruction), m.xlen)
 	if err != nil {
 		return err
 	}
 
-	return m.executeRISCInstruction(inst)
+	for _, text := range expanded {
+		inst, err := parseRISCInstruction(text)
+		if err != nil {
+			return err
+		}
+		if err := m.executeRISCInstruction(inst); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ExecuteRISCProgram executes the loaded RISC-V program
 func (m *QuantumRISCVMachine) ExecuteRISCProgram() error {
-	m.pc = 0
-	for m.pc < uint32(len(m.riscProgram)) {
-		if err := m.executeRISCInstruction(m.riscProgram[m.pc]); err != nil {
-			return fmt.Errorf("error at PC %d: %v", m.pc, err)
+	if m.freshRun {
+		m.ResetQuantumState()
+	}
+	m.pc = m.entryPC
+	m.skipped = 0
+	m.executed = 0
+	var loops *LoopDetector
+	if m.detectLoops {
+		loops = NewLoopDetector()
+	}
+	for !m.Finished() {
+		prev := m.pc
+		if err := m.StepOnce(); err != nil {
+			return err
+		}
+		if loops != nil && m.pc <= prev { // Backward branch or jump taken
+			if err := loops.Check(m.pc, m.stateHash()); err != nil {
+				return err
+			}
 		}
-		m.pc++
 	}
 	return nil
 }
@@ -120,238 +281,231 @@ func (m *QuantumRISCVMachine) ExecuteInstruction(inst Instruction) error {
 
 // executeInstruction executes a single quantum instruction
 func (m *QuantumRISCVMachine) executeInstruction(inst Instruction) error {
+	if err := m.validateQubits(append([]int{inst.Target}, inst.Controls...)...); err != nil {
+		return err
+	}
 	switch inst.Opcode {
 	case 0x00: // QX - Pauli-X gate
-		X.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		X.Apply(m.state, inst.Target, inst.Controls)
 	case 0x01: // QY - Pauli-Y gate
-		Y.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		Y.Apply(m.state, inst.Target, inst.Controls)
 	case 0x02: // QZ - Pauli-Z gate
-		Z.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		Z.Apply(m.state, inst.Target, inst.Controls)
 	case 0x03: // QH - Hadamard gate
-		H.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		H.Apply(m.state, inst.Target, inst.Controls)
 	case 0x04: // QS - Phase gate
-		S.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		S.Apply(m.state, inst.Target, inst.Controls)
 	case 0x05: // QT - T gate
-		T.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		T.Apply(m.state, inst.Target, inst.Controls)
 	case 0x06: // QCNOT - CNOT gate
-		CNOT.Apply(m.state, int(inst.Target), intSlice(inst.Controls))
+		if err := RequireQubits("CNOT", 2, m.state.NumQubits()); err != nil {
+			return err
+		}
+		CNOT.Apply(m.state, inst.Target, inst.Controls)
 	case 0x07: // QMEASURE - Measure qubit
-		return m.MeasureQubit(int(inst.Target))
+		_, err := m.MeasureQubit(inst.Target)
+		return err
+	case 0x08: // QI - Identity gate (no-op, recorded for circuit timing)
+	case 0x09: // QSDG - Inverse phase gate S†
+		SDG.Apply(m.state, inst.Target, inst.Controls)
+	case 0x0A: // QTDG - Inverse T gate T†
+		TDG.Apply(m.state, inst.Target, inst.Controls)
 	default:
 		return fmt.Errorf("unknown opcode: %x", inst.Opcode)
 	}
+	m.recordGate(gateNames[inst.Opcode], inst.Target, inst.Controls)
 	return nil
 }
 
-// MeasureQubit performs a measurement on the specified qubit
-func (m *QuantumRISCVMachine) MeasureQubit(target int) error {
-	if target < 0 || target >= m.state.NumQubits() {
-		return fmt.Errorf("invalid qubit number: %d", target)
+// ApplyGlobalPhase multiplies the whole machine state by e^{iθ}
+func (m *QuantumRISCVMachine) ApplyGlobalPhase(theta float64) {
+	m.state.ApplyGlobalPhase(theta)
+}
+
+// MeasureQubit measures the specified qubit, collapsing the state onto the sampled outcome
+func (m *QuantumRISCVMachine) MeasureQubit(target int) (MeasurementResult, error) {
+	result, err := m.state.Measure(target, m.rng)
+	if err != nil {
+		return MeasurementResult{}, err
+	}
+	m.recordGate("MEASURE", target, nil)
+	if m.onMeasure != nil {
+		m.onMeasure(result.Qubit, uint64(result.Outcome), result.Probability)
 	}
-	// In a real quantum computer, this would collapse the state
-	// For simulation, we'll just return the probability distribution
+	return result, nil
+}
+
+// ForceCollapse collapses the machine state onto basis state `index` without sampling the RNG,
+// and records the collapse so the history cannot be inverted past it
+func (m *QuantumRISCVMachine) ForceCollapse(index int) error {
+	if err := m.state.CollapseTo(index); err != nil {
+		return err
+	}
+	m.record(GateRecord{Name: "COLLAPSE", Target: index})
 	return nil
 }
 
-// Helper function to convert []uint8 to []int
-func intSlice(s []uint8) []int {
-	result := make([]int, len(s))
-	for i, v := range s {
-		result[i] = int(v)
+// SetMeasurementObserver registers a callback invoked after every measurement (nil disables it)
+func (m *QuantumRISCVMachine) SetMeasurementObserver(observer MeasurementObserver) {
+	m.onMeasure = observer
+}
+
+// applyGateToQubit applies a single-qubit gate by mnemonic to a qubit of the machine state
+func (m *QuantumRISCVMachine) applyGateToQubit(gate string, target int64) error {
+	opcode, ok := singleQubitGateOpcode(gate)
+	if !ok {
+		return fmt.Errorf("unknown single-qubit gate: %s", gate)
 	}
-	return result
+	if target < 0 || target >= int64(m.state.NumQubits()) {
+		return fmt.Errorf("invalid qubit number: %d", target)
+	}
+	return m.executeInstruction(Instruction{Opcode: opcode, Target: int(target)})
+}
+
+// CheckRegisters returns an error if any register operand of inst lies outside the register file.
+// Register fields are uint8, so instructions built directly (not parsed) can name x128-x255.
+func CheckRegisters(inst RISCInstruction) error {
+	for _, reg := range []uint8{inst.Rd, inst.Rs1, inst.Rs2} {
+		if int(reg) >= NumRegisters {
+			return fmt.Errorf("register x%d out of range (x0-x%d)", reg, NumRegisters-1)
+		}
+	}
+	return nil
 }
 
 // executeRISCInstruction executes a single RISC-V instruction
 func (m *QuantumRISCVMachine) executeRISCInstruction(inst RISCInstruction) error {
+	if err := CheckRegisters(inst); err != nil {
+		return err
+	}
+	m.countInstruction(inst.Opcode)
 	switch inst.Opcode {
-	case "qinit":
-		// Initialize a quantum register with |0⟩ state
-		m.quantumRegs[inst.Rd] = NewQuantumState(1)
-		m.quantumRegs[inst.Rd].InitializeZeroState()
-	case "qapply":
-		// Apply a quantum gate to a quantum register
-		if m.quantumRegs[inst.Rs1] == nil {
-			return fmt.Errorf("quantum register x%d not initialized", inst.Rs1)
-		}
-		// Use the immediate value as the gate type
-		gateType := uint8(inst.Imm)
-		instruction := Instruction{
-			Opcode:   gateType,
-			Target:   0, // Target the first qubit in the register
-			Controls: []uint8{},
-		}
-		if err := m.executeInstruction(instruction); err != nil {
-			return fmt.Errorf("error applying quantum gate: %v", err)
-		}
-	case "qmeasure":
-		// Measure a quantum register
-		if m.quantumRegs[inst.Rs1] == nil {
-			return fmt.Errorf("quantum register x%d not initialized", inst.Rs1)
-		}
-		if err := m.MeasureQubit(0); err != nil {
-			return fmt.Errorf("error measuring quantum register: %v", err)
-		}
-	case "qentangle":
-		// Entangle two quantum registers
-		if m.quantumRegs[inst.Rs1] == nil || m.quantumRegs[inst.Rs2] == nil {
-			return fmt.Errorf("quantum registers not initialized")
-		}
-		// Create a new 2-qubit state
-		entangled := NewQuantumState(2)
-		// Apply CNOT gate
-		instruction := Instruction{
-			Opcode:   0x06, // CNOT gate
-			Target:   1,    // Target the second qubit
-			Controls: []uint8{0},
-		}
-		if err := m.executeInstruction(instruction); err != nil {
-			return fmt.Errorf("error entangling quantum registers: %v", err)
+	case "qinit", "qapply", "qmeasure", "qmov", "qentangle":
+		return m.executeQuantumRegister(inst)
+	case "qgate.if":
+		// Classically controlled gate: apply only when rs holds a nonzero value
+		if m.registers[inst.Rs1] == 0 {
+			return nil
 		}
-		// Store the entangled state in the destination register
-		m.quantumRegs[inst.Rd] = entangled
-	case "add":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] + m.registers[inst.Rs2]
-	case "sub":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] - m.registers[inst.Rs2]
-	case "and":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] & m.registers[inst.Rs2]
-	case "or":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] | m.registers[inst.Rs2]
-	case "xor":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] ^ m.registers[inst.Rs2]
-	case "sll":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] << m.registers[inst.Rs2]
-	case "srl":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] >> m.registers[inst.Rs2]
-	case "sra":
-		m.registers[inst.Rd] = uint64(int64(m.registers[inst.Rs1]) >> m.registers[inst.Rs2])
-	case "slt":
-		if int64(m.registers[inst.Rs1]) < int64(m.registers[inst.Rs2]) {
-			m.registers[inst.Rd] = 1
-		} else {
-			m.registers[inst.Rd] = 0
+		return m.applyGateToQubit(inst.Gate, inst.Imm)
+	case "qgate.r":
+		// Register-addressed gate: the target qubit index is read from rs at execution time
+		target := signedXLEN(m.registers[inst.Rs1], m.xlen)
+		if err := m.applyGateToQubit(inst.Gate, target); err != nil {
+			return fmt.Errorf("qgate.r target from x%d: %v", inst.Rs1, err)
+		}
+	case "qgate.c":
+		// Quantum-controlled gate: apply to the target qubit where the control qubit is |1⟩
+		return m.applyControlledGate(inst.Gate, inst.Offset, inst.Imm)
+	case "qgate.mc":
+		// Multi-controlled gate whose control list is read from memory at run time
+		return m.applyMemoryControlledGate(inst)
+	case "qmeas.mem":
+		return m.measureToMemory(inst.Imm, inst.Rs1)
+	case "add", "sub", "and", "or", "xor", "sll", "srl", "sra", "slt", "sltu",
+		"mul", "mulh", "mulhu", "mulhsu", "div", "divu", "rem", "remu":
+		result, err := ExecuteALU(inst.Opcode, m.registers[inst.Rs1], m.registers[inst.Rs2], m.xlen)
+		if err != nil {
+			return err
 		}
-	case "sltu":
-		if m.registers[inst.Rs1] < m.registers[inst.Rs2] {
-			m.registers[inst.Rd] = 1
-		} else {
-			m.registers[inst.Rd] = 0
+		m.setRegister(inst.Rd, result)
+	case "addi", "slli", "srli", "srai", "andi", "ori", "xori", "slti", "sltiu":
+		result, err := ExecuteALU(inst.Opcode, m.registers[inst.Rs1], uint64(inst.Imm), m.xlen)
+		if err != nil {
+			return err
 		}
-	case "addi":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] + uint64(inst.Imm)
-	case "slli":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] << uint64(inst.Imm)
-	case "srli":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] >> uint64(inst.Imm)
-	case "srai":
-		m.registers[inst.Rd] = uint64(int64(m.registers[inst.Rs1]) >> inst.Imm)
-	case "andi":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] & uint64(inst.Imm)
-	case "ori":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] | uint64(inst.Imm)
-	case "xori":
-		m.registers[inst.Rd] = m.registers[inst.Rs1] ^ uint64(inst.Imm)
-	case "slti":
-		if int64(m.registers[inst.Rs1]) < inst.Imm {
-			m.registers[inst.Rd] = 1
-		} else {
-			m.registers[inst.Rd] = 0
+		m.setRegister(inst.Rd, result)
+	case "ecall":
+		halt, err := syscall(&m.registers, m.memory, m.xlen, outputOrStdout(m.output))
+		if err != nil {
+			return err
 		}
-	case "sltiu":
-		if m.registers[inst.Rs1] < uint64(inst.Imm) {
-			m.registers[inst.Rd] = 1
-		} else {
-			m.registers[inst.Rd] = 0
+		if halt {
+			m.jumpTo(int64(len(m.riscProgram))) // Moving the PC past the end stops the run cleanly
 		}
+	case "ebreak":
+		m.jumpTo(int64(len(m.riscProgram))) // No debugger is attached, so a breakpoint halts
 	case "lui":
-		m.registers[inst.Rd] = uint64(inst.Imm) << 12
+		m.setRegister(inst.Rd, uint64(inst.Imm)<<12)
 	case "auipc":
-		m.registers[inst.Rd] = uint64(m.pc) + (uint64(inst.Imm) << 12)
+		m.setRegister(inst.Rd, uint64(m.pc)+(uint64(inst.Imm)<<12))
 	case "jal":
-		nextPC := uint64(m.pc) + uint64(inst.Offset)
-		m.registers[inst.Rd] = uint64(m.pc) + 4
-		m.pc = uint32(nextPC)
-		return nil
+		// PCs count instructions, so the return address is the next instruction, pc + 1
+		target := int64(m.pc) + inst.Offset
+		m.setRegister(inst.Rd, uint64(m.pc)+1)
+		m.jumpTo(target)
 	case "jalr":
-		nextPC := m.registers[inst.Rs1] + uint64(inst.Offset)
-		m.registers[inst.Rd] = uint64(m.pc) + 4
-		m.pc = uint32(nextPC)
-		return nil
+		target := int64(m.registers[inst.Rs1]) + inst.Offset // Read rs1 before rd may overwrite it
+		m.setRegister(inst.Rd, uint64(m.pc)+1)
+		m.jumpTo(target)
 	case "beq":
 		if m.registers[inst.Rs1] == m.registers[inst.Rs2] {
-			m.pc = uint32(int64(m.pc) + inst.Offset)
-			return nil
+			m.jumpTo(int64(m.pc) + inst.Offset)
 		}
 	case "bne":
 		if m.registers[inst.Rs1] != m.registers[inst.Rs2] {
-			m.pc = uint32(int64(m.pc) + inst.Offset)
-			return nil
+			m.jumpTo(int64(m.pc) + inst.Offset)
 		}
 	case "blt":
-		if int64(m.registers[inst.Rs1]) < int64(m.registers[inst.Rs2]) {
-			m.pc = uint32(int64(m.pc) + inst.Offset)
-			return nil
+		if signedXLEN(m.registers[inst.Rs1], m.xlen) < signedXLEN(m.registers[inst.Rs2], m.xlen) {
+			m.jumpTo(int64(m.pc) + inst.Offset)
 		}
 	case "bge":
-		if int64(m.registers[inst.Rs1]) >= int64(m.registers[inst.Rs2]) {
-			m.pc = uint32(int64(m.pc) + inst.Offset)
-			return nil
+		if signedXLEN(m.registers[inst.Rs1], m.xlen) >= signedXLEN(m.registers[inst.Rs2], m.xlen) {
+			m.jumpTo(int64(m.pc) + inst.Offset)
 		}
 	case "bltu":
 		if m.registers[inst.Rs1] < m.registers[inst.Rs2] {
-			m.pc = uint32(int64(m.pc) + inst.Offset)
-			return nil
+			m.jumpTo(int64(m.pc) + inst.Offset)
 		}
 	case "bgeu":
 		if m.registers[inst.Rs1] >= m.registers[inst.Rs2] {
-			m.pc = uint32(int64(m.pc) + inst.Offset)
-			return nil
+			m.jumpTo(int64(m.pc) + inst.Offset)
 		}
 	case "lw":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr+4 > uint64(len(m.memory)) {
 			return fmt.Errorf("memory access out of bounds")
 		}
-		m.registers[inst.Rd] = uint64(m.memory[addr]) |
-			uint64(m.memory[addr+1])<<8 |
-			uint64(m.memory[addr+2])<<16 |
-			uint64(m.memory[addr+3])<<24
+		m.setRegister(inst.Rd, uint64(m.memory[addr])|
+			uint64(m.memory[addr+1])<<8|
+			uint64(m.memory[addr+2])<<16|
+			uint64(m.memory[addr+3])<<24)
 	case "lh":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr+2 > uint64(len(m.memory)) {
 			return fmt.Errorf("memory access out of bounds")
 		}
-		m.registers[inst.Rd] = uint64(int16(uint16(m.memory[addr]) |
-			uint16(m.memory[addr+1])<<8))
+		m.setRegister(inst.Rd, uint64(int16(uint16(m.memory[addr])|
+			uint16(m.memory[addr+1])<<8)))
 	case "lb":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr >= uint64(len(m.memory)) {
 			return fmt.Errorf("memory access out of bounds")
 		}
-		m.registers[inst.Rd] = uint64(int8(m.memory[addr]))
+		m.setRegister(inst.Rd, uint64(int8(m.memory[addr])))
 	case "lwu":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr+4 > uint64(len(m.memory)) {
 			return fmt.Errorf("memory access out of bounds")
 		}
-		m.registers[inst.Rd] = uint64(m.memory[addr]) |
-			uint64(m.memory[addr+1])<<8 |
-			uint64(m.memory[addr+2])<<16 |
-			uint64(m.memory[addr+3])<<24
+		m.setRegister(inst.Rd, uint64(m.memory[addr])|
+			uint64(m.memory[addr+1])<<8|
+			uint64(m.memory[addr+2])<<16|
+			uint64(m.memory[addr+3])<<24)
 	case "lhu":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr+2 > uint64(len(m.memory)) {
 			return fmt.Errorf("memory access out of bounds")
 		}
-		m.registers[inst.Rd] = uint64(m.memory[addr]) |
-			uint64(m.memory[addr+1])<<8
+		m.setRegister(inst.Rd, uint64(m.memory[addr])|
+			uint64(m.memory[addr+1])<<8)
 	case "lbu":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr >= uint64(len(m.memory)) {
 			return fmt.Errorf("memory access out of bounds")
 		}
-		m.registers[inst.Rd] = uint64(m.memory[addr])
+		m.setRegister(inst.Rd, uint64(m.memory[addr]))
 	case "sw":
 		addr := m.registers[inst.Rs1] + uint64(inst.Offset)
 		if addr+4 > uint64(len(m.memory)) {
@@ -377,305 +531,28 @@ func (m *QuantumRISCVMachine) executeRISCInstruction(inst RISCInstruction) error
 		}
 		m.memory[addr] = byte(m.registers[inst.Rs2])
 	default:
+		if handler, ok := customInstructions[inst.Opcode]; ok {
+			return handler(m, inst)
+		}
 		return fmt.Errorf("unknown RISC-V instruction: %s", inst.Opcode)
 	}
 
 	return nil
 }
 
-// parseRISCInstruction parses a RISC-V instruction string
-func parseRISCInstruction(instruction string) (RISCInstruction, error) {
-	// Remove comments
-	if commentIndex := strings.Index(instruction, "#"); commentIndex != -1 {
-		instruction = instruction[:commentIndex]
-	}
-
-	// Trim whitespace
-	instruction = strings.TrimSpace(instruction)
-	if instruction == "" {
-		return RISCInstruction{}, fmt.Errorf("empty instruction")
-	}
-
-	parts := strings.Fields(instruction)
-	if len(parts) == 0 {
-		return RISCInstruction{}, fmt.Errorf("empty instruction")
-	}
-
-	inst := RISCInstruction{
-		Opcode: parts[0],
-	}
-
-	switch inst.Opcode {
-	case "qinit":
-		if len(parts) != 2 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments for qinit")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		inst.Rd = rd
-
-	case "qapply":
-		if len(parts) != 4 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments for qapply")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		imm, err := strconv.ParseInt(parts[3], 10, 64)
-		if err != nil {
-			return RISCInstruction{}, fmt.Errorf("invalid immediate value: %v", err)
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-		inst.Imm = imm
-
-	case "qmeasure":
-		if len(parts) != 3 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments for qmeasure")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-
-	case "qentangle":
-		if len(parts) != 4 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments for qentangle")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs2, err := parseRegister(parts[3])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-		inst.Rs2 = rs2
-
-	case "add", "sub", "and", "or", "xor", "sll", "srl", "sra", "slt", "sltu":
-		if len(parts) != 4 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs2, err := parseRegister(parts[3])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-		inst.Rs2 = rs2
-
-	case "addi", "slli", "srli", "srai", "andi", "ori", "xori", "slti", "sltiu":
-		if len(parts) != 4 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		// Check if the immediate is a register reference
-		if strings.HasPrefix(parts[3], "x") {
-			rs2, err := parseRegister(parts[3])
-			if err != nil {
-				return RISCInstruction{}, err
-			}
-			// Convert to regular add instruction
-			inst.Opcode = "add"
-			inst.Rd = rd
-			inst.Rs1 = rs1
-			inst.Rs2 = rs2
-			return inst, nil
-		}
-		imm, err := strconv.ParseInt(parts[3], 10, 64)
-		if err != nil {
-			return RISCInstruction{}, fmt.Errorf("invalid immediate value: %v", err)
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-		inst.Imm = imm
-
-	case "lui", "auipc":
-		if len(parts) != 3 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		imm, err := strconv.ParseInt(parts[2], 10, 64)
-		if err != nil {
-			return RISCInstruction{}, fmt.Errorf("invalid immediate value: %v", err)
-		}
-		inst.Rd = rd
-		inst.Imm = imm
-
-	case "jal":
-		if len(parts) != 3 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		offset, err := strconv.ParseInt(parts[2], 10, 64)
-		if err != nil {
-			return RISCInstruction{}, fmt.Errorf("invalid offset value: %v", err)
-		}
-		inst.Rd = rd
-		inst.Offset = offset
-
-	case "jalr":
-		if len(parts) != 4 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		offset, err := strconv.ParseInt(parts[3], 10, 64)
-		if err != nil {
-			return RISCInstruction{}, fmt.Errorf("invalid offset value: %v", err)
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-		inst.Offset = offset
-
-	case "beq", "bne", "blt", "bge", "bltu", "bgeu":
-		if len(parts) != 4 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rs1, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs2, err := parseRegister(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		offset, err := strconv.ParseInt(parts[3], 10, 64)
-		if err != nil {
-			return RISCInstruction{}, fmt.Errorf("invalid offset value: %v", err)
-		}
-		inst.Rs1 = rs1
-		inst.Rs2 = rs2
-		inst.Offset = offset
-
-	case "lw", "lh", "lb", "lwu", "lhu", "lbu":
-		if len(parts) != 3 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rd, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, offset, err := parseLoadStore(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		inst.Rd = rd
-		inst.Rs1 = rs1
-		inst.Offset = offset
-
-	case "sw", "sh", "sb":
-		if len(parts) != 3 {
-			return RISCInstruction{}, fmt.Errorf("invalid number of arguments")
-		}
-		rs2, err := parseRegister(parts[1])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		rs1, offset, err := parseLoadStore(parts[2])
-		if err != nil {
-			return RISCInstruction{}, err
-		}
-		inst.Rs1 = rs1
-		inst.Rs2 = rs2
-		inst.Offset = offset
-
-	default:
-		return RISCInstruction{}, fmt.Errorf("unknown instruction: %s", inst.Opcode)
-	}
-
-	return inst, nil
-}
-
-// parseRegister parses a register name (e.g., "x0", "x1", etc.)
-func parseRegister(reg string) (uint8, error) {
-	// Remove any trailing commas
-	reg = strings.TrimRight(reg, ",")
-
-	if !strings.HasPrefix(reg, "x") {
-		return 0, fmt.Errorf("invalid register format: %s", reg)
-	}
-	num, err := strconv.ParseUint(reg[1:], 10, 8)
-	if err != nil {
-		return 0, fmt.Errorf("invalid register number: %v", err)
-	}
-	if num > 127 {
-		return 0, fmt.Errorf("register number out of range: %d", num)
-	}
-	return uint8(num), nil
+// GetRegisters returns the current state of all registers
+func (m *QuantumRISCVMachine) GetRegisters() [128]uint64 {
+	return m.registers
 }
 
-// parseLoadStore parses load/store instruction arguments (e.g., "4(x1)")
-func parseLoadStore(arg string) (uint8, int64, error) {
-	parts := strings.Split(arg, "(")
-	if len(parts) != 2 {
-		return 0, 0, fmt.Errorf("invalid load/store format: %s", arg)
-	}
-
-	offset, err := strconv.ParseInt(parts[0], 10, 64)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid offset value: %v", err)
-	}
-
-	// Remove any trailing commas and closing parenthesis
-	reg := strings.TrimRight(strings.TrimRight(parts[1], ","), ")")
-	rs1, err := parseRegister(reg)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	return rs1, offset, nil
+// SetRegister sets the value of a register, masked to XLEN bits; writes to x0 are discarded
+func (m *QuantumRISCVMachine) SetRegister(reg uint8, value uint64) {
+	m.setRegister(reg, value)
 }
 
-// GetRegisters returns the current state of all registers
-func (m *QuantumRISCVMachine) GetRegisters() [128]uint64 {
-	return m.registers
+// GetRegister gets the value of a register
+func (m *QuantumRISCVMachine) GetRegister(reg uint8) uint64 {
+	return m.registers[reg]
 }
 
 // GetState returns the current quantum state
@@ -0,0 +1,376 @@
+package quantum
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCheckRegisters(t *testing.T) {
+	tests := []struct {
+		inst    RISCInstruction
+		wantErr bool
+	}{
+		{RISCInstruction{Opcode: "add", Rd: 1, Rs1: 2, Rs2: 3}, false},
+		{RISCInstruction{Opcode: "add", Rd: 127, Rs1: 127, Rs2: 127}, false},
+		{RISCInstruction{Opcode: "add", Rd: 128, Rs1: 2, Rs2: 3}, true},
+		{RISCInstruction{Opcode: "qinit", Rd: 200}, true},
+		{RISCInstruction{Opcode: "qmeasure", Rd: 1, Rs1: 255}, true},
+		{RISCInstruction{Opcode: "qentangle", Rd: 3, Rs1: 1, Rs2: 130}, true},
+	}
+	for _, tt := range tests {
+		wantErr := func(name string, err error) {
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s %+v: error %v, want error %v", name, tt.inst, err, tt.wantErr)
+			}
+		}
+		wantErr("CheckRegisters", CheckRegisters(tt.inst))
+		if tt.wantErr {
+			// Both machines must reject the operands instead of indexing past their register files
+			wantErr("VM", NewQuantumRISCVMachine(2).executeRISCInstruction(tt.inst))
+			wantErr("host", NewHostQuantumMachine(2).ExecuteQuantumRISCV(tt.inst))
+		}
+	}
+}
+
+// runMachine loads and runs source on a fresh numQubits machine and returns the machine
+func runMachine(t *testing.T, source string, numQubits int) *QuantumRISCVMachine {
+	t.Helper()
+	m := NewQuantumRISCVMachine(numQubits)
+	if err := m.LoadRISCSource(source); err != nil {
+		t.Fatalf("loading %q: %v", source, err)
+	}
+	if err := m.ExecuteRISCProgram(); err != nil {
+		t.Fatalf("running %q: %v", source, err)
+	}
+	return m
+}
+
+func TestQGateIf(t *testing.T) {
+	tests := []struct {
+		condition int
+		gate      string
+		target    int
+		wantOne   float64 // P(target = 1) afterwards
+	}{
+		{0, "X", 1, 0},
+		{1, "X", 1, 1},
+		{-5, "X", 0, 1},
+		{1, "H", 2, 0.5},
+		{0, "H", 2, 0},
+		{1, "I", 0, 0},
+	}
+	for _, tt := range tests {
+		source := fmt.Sprintf("addi x5, x0, %d\nqgate.if x5, %s, %d\n", tt.condition, tt.gate, tt.target)
+		m := runMachine(t, source, 3)
+		if got := m.GetState().MeasureProbability(tt.target, 1); math.Abs(got-tt.wantOne) > 1e-12 {
+			t.Errorf("x5=%d qgate.if %s on q%d: P(1) = %g, want %g", tt.condition, tt.gate, tt.target, got, tt.wantOne)
+		}
+		applied := len(m.GetGateHistory()) > 0
+		if applied != (tt.condition != 0) {
+			t.Errorf("x5=%d: gate recorded = %v", tt.condition, applied)
+		}
+	}
+}
+
+func TestQGateIfErrors(t *testing.T) {
+	for _, source := range []string{
+		"qgate.if x5, X, 3\n",    // Qubit out of range
+		"qgate.if x5, CNOT, 0\n", // Not a single-qubit gate
+		"qgate.if x5, X\n",
+	} {
+		m := NewQuantumRISCVMachine(3)
+		err := m.LoadRISCSource(source)
+		if err == nil {
+			m.setRegister(5, 1)
+			err = m.ExecuteRISCProgram()
+		}
+		if err == nil {
+			t.Errorf("%q ran without an error", source)
+		}
+	}
+}
+
+func TestExecuteInstructionQubitRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		inst    Instruction
+		wantErr bool
+	}{
+		{"X on last qubit", Instruction{Opcode: 0x00, Target: 2}, false},
+		{"controlled X", Instruction{Opcode: 0x00, Target: 0, Controls: []int{1, 2}}, false},
+		{"target 256 does not wrap to 0", Instruction{Opcode: 0x00, Target: 256}, true},
+		{"target past the register", Instruction{Opcode: 0x03, Target: 3}, true},
+		{"negative target", Instruction{Opcode: 0x03, Target: -1}, true},
+		{"control 257 does not wrap to 1", Instruction{Opcode: 0x00, Target: 0, Controls: []int{257}}, true},
+		{"control equals target", Instruction{Opcode: 0x00, Target: 1, Controls: []int{1}}, true},
+		{"measure out of range", Instruction{Opcode: 0x07, Target: 300}, true},
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(3)
+		err := m.ExecuteInstruction(tt.inst)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && len(m.GetGateHistory()) != 0 {
+			t.Errorf("%s: rejected instruction was recorded", tt.name)
+		}
+	}
+}
+
+func TestExecuteInstructionWideMachine(t *testing.T) {
+	m := NewQuantumRISCVMachine(40)
+	if err := m.ExecuteInstruction(Instruction{Opcode: 0x00, Target: 39}); err != nil {
+		t.Fatal(err)
+	}
+	if p := m.GetState().MeasureProbability(39, 1); math.Abs(p-1) > 1e-12 {
+		t.Errorf("P(q39 = 1) = %g after X, want 1", p)
+	}
+
+	// Every qubit of a 2000-qubit machine is addressable, far past the bits of an int index
+	for seed := int64(1); seed <= 4; seed++ {
+		m := NewQuantumRISCVMachine(2000)
+		m.SetSeed(seed)
+		for _, inst := range []Instruction{
+			{Opcode: 0x00, Target: 300},                         // X
+			{Opcode: 0x03, Target: 1000},                        // H
+			{Opcode: 0x06, Target: 1999, Controls: []int{1000}}, // CNOT
+		} {
+			if err := m.ExecuteInstruction(inst); err != nil {
+				t.Fatalf("%+v on 2000 qubits: %v", inst, err)
+			}
+		}
+		state := m.GetState()
+		if p := state.MeasureProbability(300, 1); math.Abs(p-1) > 1e-12 {
+			t.Errorf("P(q300 = 1) = %g after X, want 1", p)
+		}
+		if p := state.MeasureProbability(1000, 1); math.Abs(p-0.5) > 1e-12 {
+			t.Errorf("P(q1000 = 1) = %g after H, want 0.5", p)
+		}
+		result, err := m.MeasureQubit(1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := state.MeasureProbability(1999, result.Outcome); math.Abs(p-1) > 1e-12 {
+			t.Errorf("seed %d: q1000 measured %d but P(q1999 = %d) = %g", seed, result.Outcome, result.Outcome, p)
+		}
+		if state.SupportSize() != 1 {
+			t.Errorf("seed %d: %d basis states populated after measuring, want 1", seed, state.SupportSize())
+		}
+	}
+}
+
+func TestQGateR(t *testing.T) {
+	tests := []struct {
+		index   int
+		gate    string
+		wantOne float64 // P(qubit index = 1) afterwards
+	}{
+		{0, "X", 1},
+		{2, "X", 1},
+		{1, "H", 0.5},
+		{2, "Z", 0},
+	}
+	for _, tt := range tests {
+		source := fmt.Sprintf("addi x5, x0, %d\nqgate.r %s, x5\n", tt.index, tt.gate)
+		m := runMachine(t, source, 3)
+		if got := m.GetState().MeasureProbability(tt.index, 1); math.Abs(got-tt.wantOne) > 1e-12 {
+			t.Errorf("qgate.r %s with x5=%d: P(1) = %g, want %g", tt.gate, tt.index, got, tt.wantOne)
+		}
+		history := m.GetGateHistory()
+		if len(history) != 1 || history[0].Target != tt.index {
+			t.Errorf("qgate.r %s with x5=%d: history %v", tt.gate, tt.index, history)
+		}
+	}
+
+	// The index is read at run time, so a loop can sweep the register
+	loop := "addi x5, x0, 0\naddi x6, x0, 3\nloop: qgate.r X, x5\naddi x5, x5, 1\nblt x5, x6, loop\n"
+	m := runMachine(t, loop, 3)
+	for q := 0; q < 3; q++ {
+		if got := m.GetState().MeasureProbability(q, 1); math.Abs(got-1) > 1e-12 {
+			t.Errorf("loop: P(q%d = 1) = %g, want 1", q, got)
+		}
+	}
+}
+
+func TestQGateRErrors(t *testing.T) {
+	for _, source := range []string{
+		"addi x5, x0, 3\nqgate.r X, x5\n",  // Index past the last qubit
+		"addi x5, x0, -1\nqgate.r X, x5\n", // Negative index
+		"qgate.r CNOT, x5\n",               // Not a single-qubit gate
+		"qgate.r X\n",                      // Missing register
+		"qgate.r X, x200\n",                // Register out of range
+	} {
+		m := NewQuantumRISCVMachine(3)
+		err := m.LoadRISCSource(source)
+		if err == nil {
+			err = m.ExecuteRISCProgram()
+		}
+		if err == nil {
+			t.Errorf("%q ran without an error", source)
+		}
+	}
+}
+
+func TestBranchesLandOnTarget(t *testing.T) {
+	tests := []struct {
+		branch    string
+		a, b      int
+		wantTaken bool
+	}{
+		{"beq", 3, 3, true},
+		{"beq", 3, 4, false},
+		{"bne", 3, 4, true},
+		{"bne", 3, 3, false},
+		{"blt", -1, 0, true},
+		{"blt", 0, -1, false},
+		{"bge", 0, -1, true},
+		{"bge", -1, 0, false},
+		{"bltu", 0, -1, true}, // -1 is the largest unsigned value
+		{"bltu", -1, 0, false},
+		{"bgeu", -1, 0, true},
+		{"bgeu", 0, -1, false},
+	}
+	for _, tt := range tests {
+		// Taken, the branch skips exactly one instruction and lands on the one at its label
+		source := fmt.Sprintf("addi x5, x0, %d\naddi x6, x0, %d\n%s x5, x6, skip\naddi x7, x0, 1\n"+
+			"skip: addi x8, x0, 1\n", tt.a, tt.b, tt.branch)
+		vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, source)
+		if vmErr != nil || hostErr != nil {
+			t.Errorf("%s %d, %d: VM error %v, host error %v", tt.branch, tt.a, tt.b, vmErr, hostErr)
+			continue
+		}
+		wantX7 := uint64(1)
+		if tt.wantTaken {
+			wantX7 = 0
+		}
+		for mode, regs := range map[string][NumRegisters]uint64{"VM": vmRegs, "host": hostRegs} {
+			if regs[7] != wantX7 || regs[8] != 1 {
+				t.Errorf("%s %d, %d (%s): x7 = %d, x8 = %d, want %d and 1", tt.branch, tt.a, tt.b, mode,
+					regs[7], regs[8], wantX7)
+			}
+		}
+	}
+}
+
+func TestBackwardBranchAndJumpLandOnTarget(t *testing.T) {
+	// Five passes of the loop body; jal over the poison instruction lands on done
+	source := "addi x5, x0, 5\nloop: addi x6, x6, 1\naddi x5, x5, -1\nbne x5, x0, loop\njal x0, done\n" +
+		"addi x6, x0, 99\ndone: addi x7, x6, 0\n"
+	vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, source)
+	if vmErr != nil || hostErr != nil {
+		t.Fatalf("VM error %v, host error %v", vmErr, hostErr)
+	}
+	for mode, regs := range map[string][NumRegisters]uint64{"VM": vmRegs, "host": hostRegs} {
+		if regs[6] != 5 || regs[7] != 5 {
+			t.Errorf("%s: x6 = %d, x7 = %d, want 5 and 5", mode, regs[6], regs[7])
+		}
+	}
+}
+
+func TestQGateC(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []int // Basis states left with nonzero amplitude
+	}{
+		{"qgate.c X, 0, 1\n", []int{0}},                                       // Control |0⟩: nothing happens
+		{"addi x5, x0, 1\nqgate.if x5, X, 0\nqgate.c X, 0, 1\n", []int{3}},    // Control |1⟩ flips the target
+		{"addi x5, x0, 1\nqgate.if x5, H, 2\nqgate.c X, 2, 0\n", []int{0, 5}}, // Bell pair on qubits 0 and 2
+		{"addi x5, x0, 1\nqgate.if x5, X, 1\nqgate.c Z, 1, 0\n", []int{2}},
+	}
+	for _, tt := range tests {
+		m := runMachine(t, tt.source, 3)
+		nonzero := m.GetState().NonzeroAmplitudes()
+		if len(nonzero) != len(tt.want) {
+			t.Errorf("%q: nonzero amplitudes %v, want basis states %v", tt.source, nonzero, tt.want)
+			continue
+		}
+		for i, basis := range nonzero {
+			if basis.Index != tt.want[i] {
+				t.Errorf("%q: nonzero amplitudes %v, want basis states %v", tt.source, nonzero, tt.want)
+				break
+			}
+		}
+	}
+
+	for _, source := range []string{
+		"qgate.c X, 0, 3\n", // Target out of range
+		"qgate.c X, 3, 0\n", // Control out of range
+		"qgate.c X, 1, 1\n", // Control is the target
+		"qgate.c CNOT, 0, 1\n",
+		"qgate.c X, a, 1\n",
+	} {
+		m := NewQuantumRISCVMachine(3)
+		err := m.LoadRISCSource(source)
+		if err == nil {
+			err = m.ExecuteRISCProgram()
+		}
+		if err == nil {
+			t.Errorf("%q ran without an error", source)
+		}
+	}
+}
+
+func TestJumpAndLinkAddresses(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   map[int]uint64
+	}{
+		// jal at index 1 links 2, the index of the next instruction
+		{"jal link", "addi x5, x0, 1\njal x1, skip\naddi x5, x0, 99\nskip: addi x6, x0, 2\n",
+			map[int]uint64{1: 2, 5: 1, 6: 2}},
+		{"jal x0 does not link", "jal x0, done\naddi x5, x0, 99\ndone: addi x6, x0, 1\n",
+			map[int]uint64{0: 0, 5: 0, 6: 1}},
+		// A call to a subroutine that returns with jalr to the link register
+		{"call and return", "jal x1, sub\naddi x6, x5, 1\njal x0, end\nsub: addi x5, x0, 41\njalr x0, x1, 0\n" +
+			"end: addi x7, x0, 7\n", map[int]uint64{1: 1, 5: 41, 6: 42, 7: 7}},
+		// jalr reads rs1 before writing rd, so rd may equal rs1
+		{"jalr rd = rs1", "addi x5, x0, 3\njalr x5, x5, 0\naddi x6, x0, 99\naddi x7, x0, 1\n",
+			map[int]uint64{5: 2, 6: 0, 7: 1}},
+		{"jalr offset", "addi x5, x0, 1\njalr x1, x5, 2\naddi x6, x0, 99\naddi x7, x0, 1\n",
+			map[int]uint64{1: 2, 6: 0, 7: 1}},
+		// A countdown loop followed by jal over a poison instruction
+		{"countdown", "addi x5, x0, 4\nloop: addi x5, x5, -1\naddi x6, x6, 10\nbne x5, x0, loop\njal x1, out\n" +
+			"addi x6, x0, 0\nout: addi x7, x6, 0\n", map[int]uint64{1: 5, 5: 0, 6: 40, 7: 40}},
+	}
+	for _, tt := range tests {
+		vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, tt.source)
+		if vmErr != nil || hostErr != nil {
+			t.Errorf("%s: VM error %v, host error %v", tt.name, vmErr, hostErr)
+			continue
+		}
+		for reg, want := range tt.want {
+			if vmRegs[reg] != want || hostRegs[reg] != want {
+				t.Errorf("%s: x%d = %d (VM), %d (host), want %d", tt.name, reg, vmRegs[reg], hostRegs[reg], want)
+			}
+		}
+	}
+}
+
+func TestX0IsHardwiredToZero(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"addi", "addi x0, x0, 5\n"},
+		{"lui", "lui x0, 0x12345\n"},
+		{"auipc", "addi x5, x0, 1\nauipc x0, 1\n"},
+		{"add", "addi x5, x0, 3\nadd x0, x5, x5\n"},
+		{"load", "addi x5, x0, 9\nsw x5, 256(x0)\nlw x0, 256(x0)\n"},
+		{"jal link", "jal x0, next\nnext: addi x6, x0, 1\n"},
+		{"qmeasure", "qinit x1\nqapply x1, x1, 0\nqmeasure x0, x1\n"},
+	}
+	for _, tt := range tests {
+		// Use x0 as a source afterwards: a stale value would leak into x7
+		vmRegs, hostRegs, vmErr, hostErr := runBothModes(t, tt.source+"add x7, x0, x0\n")
+		if vmErr != nil || hostErr != nil {
+			t.Errorf("%s: VM error %v, host error %v", tt.name, vmErr, hostErr)
+			continue
+		}
+		if vmRegs[0] != 0 || hostRegs[0] != 0 || vmRegs[7] != 0 || hostRegs[7] != 0 {
+			t.Errorf("%s: x0 = %d (VM), %d (host); x7 = %d (VM), %d (host); want all 0", tt.name,
+				vmRegs[0], hostRegs[0], vmRegs[7], hostRegs[7])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package quantum
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Measurement RNG algorithms accepted by NewRNG and the -rng flag
+const (
+	RNGSplitMix64 = "splitmix64" // Portable generator specified in this file (the default)
+	RNGMathRand   = "mathrand"   // Go's math/rand generator
+)
+
+// DefaultRNG is the measurement RNG algorithm machines start with
+const DefaultRNG = RNGSplitMix64
+
+// RNG supplies the uniform [0, 1) samples that decide measurement outcomes
+type RNG interface {
+	Float64() float64
+}
+
+// rngConstructors maps each algorithm name to a constructor taking the seed
+var rngConstructors = map[string]func(seed int64) RNG{
+	RNGSplitMix64: func(seed int64) RNG { return NewSplitMix64(seed) },
+	RNGMathRand:   func(seed int64) RNG { return rand.New(rand.NewSource(seed)) },
+}
+
+// NewRNG returns a measurement RNG of the named algorithm seeded with seed
+func NewRNG(algorithm string, seed int64) (RNG, error) {
+	constructor, ok := rngConstructors[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unknown RNG algorithm '%s' (available: %v)", algorithm, RNGAlgorithms())
+	}
+	return constructor(seed), nil
+}
+
+// RNGAlgorithms lists the supported RNG algorithm names in sorted order
+func RNGAlgorithms() []string {
+	names := make([]string, 0, len(rngConstructors))
+	for name := range rngConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SplitMix64 is Steele, Lea and Flood's splitmix64 generator. Its output depends only on the
+// seed and the arithmetic below, so a seed yields the same measurement sequence on every
+// platform and Go version.
+type SplitMix64 struct {
+	state uint64
+}
+
+// NewSplitMix64 returns a splitmix64 generator whose state starts at seed
+func NewSplitMix64(seed int64) *SplitMix64 {
+	return &SplitMix64{state: uint64(seed)}
+}
+
+// Uint64 advances the state by the golden-ratio increment and returns the mixed result
+func (s *SplitMix64) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a uniform sample in [0, 1) built from the top 53 bits of the next output
+func (s *SplitMix64) Float64() float64 {
+	return float64(s.Uint64()>>11) / (1 << 53)
+}
+
+// SetRNGAlgorithm switches the measurement RNG to the named algorithm, re-seeded from the most
+// recent seed
+func (m *QuantumRISCVMachine) SetRNGAlgorithm(algorithm string) error {
+	rng, err := NewRNG(algorithm, m.seed)
+	if err != nil {
+		return err
+	}
+	m.rng, m.rngName = rng, algorithm
+	return nil
+}
+
+// SetRNGAlgorithm switches the measurement RNG to the named algorithm, re-seeded from the most
+// recent seed
+func (m *HostQuantumMachine) SetRNGAlgorithm(algorithm string) error {
+	rng, err := NewRNG(algorithm, m.seed)
+	if err != nil {
+		return err
+	}
+	m.rng, m.rngName = rng, algorithm
+	return nil
+}
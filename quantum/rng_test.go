@@ -0,0 +1,79 @@
+package quantum
+
+import "testing"
+
+func TestSplitMix64ReferenceOutputs(t *testing.T) {
+	tests := []struct {
+		seed int64
+		want []uint64
+	}{
+		{0, []uint64{0xE220A8397B1DCDAF, 0x6E789E6AA1B965F4, 0x06C45D188009454F, 0xF88BB8A8724C81EC}},
+		{1234567, []uint64{6457827717110365317, 3203168211198807973, 9817491932198370423, 4593380528125082431,
+			16408922859458223821}},
+	}
+	for _, tt := range tests {
+		rng := NewSplitMix64(tt.seed)
+		for i, want := range tt.want {
+			if got := rng.Uint64(); got != want {
+				t.Errorf("seed %d output %d = %#x, want %#x", tt.seed, i, got, want)
+			}
+		}
+	}
+}
+
+func TestNewRNG(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		wantErr   bool
+	}{
+		{RNGSplitMix64, false},
+		{RNGMathRand, false},
+		{"xorshift", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		rng, err := NewRNG(tt.algorithm, 42)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewRNG(%q): error %v, want error %v", tt.algorithm, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		again, _ := NewRNG(tt.algorithm, 42)
+		for i := 0; i < 100; i++ {
+			x := rng.Float64()
+			if x < 0 || x >= 1 {
+				t.Fatalf("%s sample %g outside [0, 1)", tt.algorithm, x)
+			}
+			if y := again.Float64(); x != y {
+				t.Fatalf("%s seeded twice with 42 diverged at sample %d", tt.algorithm, i)
+			}
+		}
+	}
+}
+
+func TestSetRNGAlgorithmReseeds(t *testing.T) {
+	for _, algorithm := range RNGAlgorithms() {
+		vm := NewQuantumRISCVMachine(1)
+		vm.SetSeed(9)
+		vm.rng.Float64() // Advance the old generator; switching must start over from the seed
+		if err := vm.SetRNGAlgorithm(algorithm); err != nil {
+			t.Fatal(err)
+		}
+		host := NewHostQuantumMachine(1)
+		host.SetSeed(9)
+		if err := host.SetRNGAlgorithm(algorithm); err != nil {
+			t.Fatal(err)
+		}
+		want, _ := NewRNG(algorithm, 9)
+		for i := 0; i < 10; i++ {
+			w := want.Float64()
+			if vm.rng.Float64() != w || host.rng.Float64() != w {
+				t.Fatalf("%s: machine sample %d differs from a fresh generator seeded with 9", algorithm, i)
+			}
+		}
+	}
+	if err := NewQuantumRISCVMachine(1).SetRNGAlgorithm("dice"); err == nil {
+		t.Error("SetRNGAlgorithm accepted an unknown algorithm")
+	}
+}
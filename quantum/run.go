@@ -0,0 +1,91 @@
+package quantum
+
+// DefaultRunQubits is the machine size RunProgram uses when Options.NumQubits is zero
+const DefaultRunQubits = 8
+
+// Options configures a headless RunProgram call
+type Options struct {
+	NumQubits   int    // Machine size; 0 selects DefaultRunQubits
+	XLEN        int    // Register width, 32 or 64; 0 selects DefaultXLEN
+	Seed        int64  // Measurement RNG seed, so runs are reproducible
+	RNG         string // Measurement RNG algorithm; "" selects DefaultRNG
+	Entry       string // Label to start at ("" starts at the first instruction)
+	SkipUnknown bool   // Execute unknown instructions as no-ops instead of rejecting the program
+	StackTop    uint64 // Initial sp (x2); 0 selects DefaultStackTop
+}
+
+// Result is everything a headless run produces
+type Result struct {
+	Registers    [NumRegisters]uint64
+	Measurements []MeasurementResult // Every measurement, in execution order
+	Instructions int                 // Instructions executed
+	Skipped      int                 // Unknown instructions skipped (with SkipUnknown)
+	ExitCode     int                 // 0 when the program ran to completion
+}
+
+// RunProgram assembles and executes source on a fresh VM machine without printing anything,
+// and returns the final registers, measurement outcomes and instruction count. It is the
+// programmatic entry point for benchmarks and for embedding qmachine in other test suites.
+// When execution fails, the partial Result is returned together with the error.
+func RunProgram(source string, opts Options) (Result, error) {
+	if opts.NumQubits == 0 {
+		opts.NumQubits = DefaultRunQubits
+	}
+	if opts.XLEN == 0 {
+		opts.XLEN = DefaultXLEN
+	}
+	if opts.RNG == "" {
+		opts.RNG = DefaultRNG
+	}
+	if opts.StackTop == 0 {
+		opts.StackTop = DefaultStackTop
+	}
+
+	m := NewQuantumRISCVMachine(opts.NumQubits)
+	if err := m.SetXLEN(opts.XLEN); err != nil {
+		return Result{}, err
+	}
+	if err := m.SetRNGAlgorithm(opts.RNG); err != nil {
+		return Result{}, err
+	}
+	m.SetSeed(opts.Seed)
+	if err := m.SetStackTop(opts.StackTop); err != nil {
+		return Result{}, err
+	}
+	m.SetEntry(opts.Entry)
+	m.SetSkipUnknown(opts.SkipUnknown)
+	m.SetFreshRun(true)
+
+	var result Result
+	m.SetMeasurementObserver(func(qubit int, outcome uint64, probability float64) {
+		result.Measurements = append(result.Measurements,
+			MeasurementResult{Qubit: qubit, Outcome: int(outcome), Probability: probability})
+	})
+
+	if err := m.LoadRISCSource(source); err != nil {
+		return Result{}, err
+	}
+	err := m.ExecuteRISCProgram()
+	result.Registers = m.GetRegisters()
+	result.Instructions = m.ExecutedInstructions()
+	result.Skipped = m.SkippedInstructions()
+	if err != nil {
+		result.ExitCode = 1
+		return result, err
+	}
+	return result, nil
+}
+
+// RunValue runs the loaded program to completion and returns register reg (e.g. "x10") as the
+// program's result, which is the usual way a computation reports its output. The second value
+// is the result interpreted as a signed XLEN-bit integer.
+func (m *QuantumRISCVMachine) RunValue(reg string) (uint64, int64, error) {
+	r, err := parseRegister(reg)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := m.ExecuteRISCProgram(); err != nil {
+		return 0, 0, err
+	}
+	return m.registers[r], signedXLEN(m.registers[r], m.xlen), nil
+}
@@ -0,0 +1,64 @@
+package quantum
+
+import "testing"
+
+func TestRunProgram(t *testing.T) {
+	tests := []struct {
+		name             string
+		source           string
+		opts             Options
+		wantRegs         map[int]uint64
+		wantInstructions int
+		wantMeasurements int
+		wantErr          bool
+	}{
+		{"arithmetic", "addi x5, x0, 20\naddi x6, x5, 22\n", Options{},
+			map[int]uint64{5: 20, 6: 42, StackPointer: DefaultStackTop}, 2, 0, false},
+		{"measurement", "qgate.r X, x0\nqmeas.mem 0, x0\nlbu x5, 0(x0)\n", Options{NumQubits: 1},
+			map[int]uint64{5: 1}, 3, 1, false},
+		{"RV32 and stack top", "addi x5, x0, -1\n", Options{XLEN: 32, StackTop: 0x1000},
+			map[int]uint64{5: 0xFFFFFFFF, StackPointer: 0x1000}, 1, 0, false},
+		{"entry", "addi x5, x0, 1\nmain: addi x6, x0, 2\n", Options{Entry: "main"},
+			map[int]uint64{5: 0, 6: 2}, 1, 0, false},
+		{"skip unknown", "frobnicate x1\naddi x5, x0, 3\n", Options{SkipUnknown: true},
+			map[int]uint64{5: 3}, 1, 0, false}, // The skipped instruction is not counted as executed
+		{"unknown instruction", "frobnicate x1\n", Options{}, nil, 0, 0, true},
+		{"bad XLEN", "addi x5, x0, 1\n", Options{XLEN: 16}, nil, 0, 0, true},
+	}
+	for _, tt := range tests {
+		result, err := RunProgram(tt.source, tt.opts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		for reg, want := range tt.wantRegs {
+			if got := result.Registers[reg]; got != want {
+				t.Errorf("%s: x%d = %#x, want %#x", tt.name, reg, got, want)
+			}
+		}
+		if result.Instructions != tt.wantInstructions || len(result.Measurements) != tt.wantMeasurements {
+			t.Errorf("%s: %d instructions and %d measurements, want %d and %d", tt.name, result.Instructions,
+				len(result.Measurements), tt.wantInstructions, tt.wantMeasurements)
+		}
+		if result.ExitCode != 0 {
+			t.Errorf("%s: exit code %d", tt.name, result.ExitCode)
+		}
+	}
+}
+
+func TestRunProgramIsReproducible(t *testing.T) {
+	const source = "qgate.r H, x0\nqmeas.mem 0, x0\nlbu x5, 0(x0)\nqgate.r H, x0\nqmeas.mem 0, x0\nlbu x6, 0(x0)\n"
+	for seed := int64(0); seed < 16; seed++ {
+		first, err := RunProgram(source, Options{NumQubits: 1, Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, _ := RunProgram(source, Options{NumQubits: 1, Seed: seed})
+		if first.Registers != second.Registers {
+			t.Fatalf("seed %d gave x5,x6 = %d,%d then %d,%d", seed, first.Registers[5], first.Registers[6],
+				second.Registers[5], second.Registers[6])
+		}
+	}
+}
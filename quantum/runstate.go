@@ -0,0 +1,62 @@
+package quantum
+
+// Run start state: a run either starts from a fresh |0...0⟩ quantum state or continues evolving
+// whatever state the machine holds. Batch execution starts fresh; the interactive REPL keeps the
+// current state by default so a prepared state can be fed into a program.
+
+// ResetQuantumState returns the machine qubits to |0...0⟩ and clears the quantum registers and
+// the gate history. Classical registers, memory, the loaded program and the state settings are
+// kept.
+func (m *QuantumRISCVMachine) ResetQuantumState() {
+	state := NewQuantumState(m.state.NumQubits())
+	state.copySettings(m.state) // Same qubit count, so the backend still fits
+	m.state = state
+	m.quantumRegs = [NumRegisters]*QuantumState{}
+	m.history = nil
+}
+
+// Reset returns the machine to the condition it was configured in: a |0...0⟩ state with the same
+// state settings, zeroed registers and memory with sp at the stack top, no program, breakpoints,
+// classical bits or counters, and the measurement RNG re-seeded from its seed. XLEN, the RNG
+// algorithm, noise, entry label, loader options, output and observer are kept.
+func (m *QuantumRISCVMachine) Reset() {
+	m.ResetQuantumState()
+	m.program = make([]Instruction, 0)
+	m.riscProgram = make([]RISCInstruction, 0)
+	m.pc, m.entryPC, m.jumped = 0, 0, false
+	m.registers = [NumRegisters]uint64{}
+	m.registers[StackPointer] = m.stackTop
+	m.memory = make([]byte, MemorySize)
+	m.cbits, m.ignored, m.globals, m.data, m.breakpoints = nil, nil, nil, nil, nil
+	m.skipped, m.executed = 0, 0
+	m.ResetStats()
+	m.SetSeed(m.seed)
+}
+
+// SetFreshRun selects whether ExecuteRISCProgram resets the quantum state before running (true)
+// or continues from the current state (false, the default)
+func (m *QuantumRISCVMachine) SetFreshRun(fresh bool) {
+	m.freshRun = fresh
+}
+
+// FreshRun reports whether ExecuteRISCProgram starts from a fresh |0...0⟩ state
+func (m *QuantumRISCVMachine) FreshRun() bool {
+	return m.freshRun
+}
+
+// ResetQuantumState returns the machine qubits to |0...0⟩ and clears the quantum registers
+func (m *HostQuantumMachine) ResetQuantumState() {
+	m.state = NewHostQuantumState(m.state.numQubits)
+	m.quantumRegs = [NumRegisters]*HostQuantumState{}
+}
+
+// SetFreshRun selects whether ExecuteProgram resets the quantum state before running (true) or
+// continues from the current state (false, the default)
+func (m *HostQuantumMachine) SetFreshRun(fresh bool) {
+	m.freshRun = fresh
+}
+
+// FreshRun reports whether ExecuteProgram starts from a fresh |0...0⟩ state
+func (m *HostQuantumMachine) FreshRun() bool {
+	return m.freshRun
+}
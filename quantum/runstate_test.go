@@ -0,0 +1,121 @@
+package quantum
+
+import "testing"
+
+// measureSequence measures qubit 0 of m after H, n times, and returns the outcomes
+func measureSequence(t *testing.T, m *QuantumRISCVMachine, n int) []int {
+	t.Helper()
+	outcomes := make([]int, n)
+	for i := range outcomes {
+		H.Apply(m.GetState(), 0, nil)
+		result, err := m.MeasureQubit(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outcomes[i] = result.Outcome
+	}
+	return outcomes
+}
+
+func TestResetKeepsConfiguration(t *testing.T) {
+	m := NewQuantumRISCVMachine(2)
+	if err := m.SetXLEN(32); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetStackTop(0x8000); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetRNGAlgorithm(RNGMathRand); err != nil {
+		t.Fatal(err)
+	}
+	m.SetSeed(7)
+	noise := NoiseModel{BitFlip: 0.25}
+	if err := m.SetNoise(noise); err != nil {
+		t.Fatal(err)
+	}
+	m.GetState().SetPolarPhases(true)
+	if err := m.LoadRISCSource("addi x5, x0, 9\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExecuteRISCProgram(); err != nil {
+		t.Fatal(err)
+	}
+
+	before := measureSequence(t, m, 16)
+	m.Reset()
+
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"XLEN", m.GetXLEN(), 32},
+		{"sp", m.GetRegisters()[StackPointer], uint64(0x8000)},
+		{"x5", m.GetRegisters()[5], uint64(0)},
+		{"noise", m.Noise(), noise},
+		{"polar phases", m.GetState().PolarPhases(), true},
+		{"program length", len(m.Disassemble()), 0},
+		{"gate history", len(m.GetGateHistory()), 0},
+		{"instructions counted", m.GetStats().ClassicalInstructions, 0},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("after Reset %s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	after := measureSequence(t, m, 16)
+	for i := range before {
+		if before[i] != after[i] {
+			t.Fatalf("measurements after Reset %v differ from the seeded run %v", after, before)
+		}
+	}
+}
+
+func TestFreshRun(t *testing.T) {
+	tests := []struct {
+		fresh   bool
+		wantOne float64 // P(q0 = 1) after preparing |1⟩ and running the X program
+	}{
+		{true, 1},  // The run starts from |0⟩ and flips it
+		{false, 0}, // The run flips the prepared |1⟩
+	}
+	for _, tt := range tests {
+		m := NewQuantumRISCVMachine(1)
+		m.SetFreshRun(tt.fresh)
+		if m.FreshRun() != tt.fresh {
+			t.Errorf("FreshRun() = %v after SetFreshRun(%v)", m.FreshRun(), tt.fresh)
+		}
+		X.Apply(m.GetState(), 0, nil)
+		if err := m.LoadRISCSource("addi x5, x0, 1\nqgate.if x5, X, 0\n"); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ExecuteRISCProgram(); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetState().MeasureProbability(0, 1); got != tt.wantOne {
+			t.Errorf("fresh %v: P(q0 = 1) = %g, want %g", tt.fresh, got, tt.wantOne)
+		}
+	}
+}
+
+func TestHostFreshRun(t *testing.T) {
+	for _, fresh := range []bool{true, false} {
+		vm := NewQuantumRISCVMachine(1)
+		host := NewHostQuantumMachine(1)
+		host.SetFreshRun(fresh)
+		if err := vm.LoadRISCSource("qinit x1\n"); err != nil {
+			t.Fatal(err)
+		}
+		host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+		if err := host.ExecuteProgram(); err != nil {
+			t.Fatal(err)
+		}
+		// The second program uses the quantum register the first one initialized
+		if err := vm.LoadRISCSource("qmeasure x5, x1\n"); err != nil {
+			t.Fatal(err)
+		}
+		host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+		if err := host.ExecuteProgram(); (err != nil) != fresh {
+			t.Errorf("fresh %v: measuring the earlier run's register gave error %v", fresh, err)
+		}
+	}
+}
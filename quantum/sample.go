@@ -0,0 +1,80 @@
+package quantum
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxShots bounds how many measurement shots Sample will simulate in one call
+const MaxShots = 10000000
+
+// Sample simulates `shots` measurements of every qubit from the Born-rule distribution without
+// collapsing the state, as if the state were prepared afresh for each shot. It returns how many
+// shots produced each basis state index; outcomes that never occurred are absent. Every populated
+// basis state must have an index (see IndexQubits).
+func (qs *QuantumState) Sample(shots int, rng RNG) (map[int]int, error) {
+	if shots < 1 || shots > MaxShots {
+		return nil, fmt.Errorf("shot count must be between 1 and %d, got %d", MaxShots, shots)
+	}
+	// Cumulative probabilities of the populated basis states, searched once per shot
+	var indices []int
+	var cumulative []float64
+	var total float64
+	var unindexed *BasisState
+	qs.each(func(b BasisState, amp Complex128) {
+		index, ok := b.Index()
+		if !ok && unindexed == nil {
+			unindexed = &b
+		}
+		total += basisProbability(amp)
+		indices = append(indices, index)
+		cumulative = append(cumulative, total)
+	})
+	if unindexed != nil {
+		return nil, errNoIndex("sample", *unindexed)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("cannot sample: state has zero norm")
+	}
+
+	counts := make(map[int]int)
+	for shot := 0; shot < shots; shot++ {
+		r := rng.Float64() * total
+		k := sort.SearchFloat64s(cumulative, r)
+		if k < len(cumulative) && cumulative[k] == r { // SearchFloat64s finds the first bound >= r
+			k++
+		}
+		if k == len(cumulative) { // Rounding at the top of the range
+			k--
+		}
+		counts[indices[k]]++
+	}
+	return counts, nil
+}
+
+// Sample simulates `shots` measurements of the machine qubits with the machine's measurement RNG,
+// leaving the state untouched (see QuantumState.Sample)
+func (m *QuantumRISCVMachine) Sample(shots int) (map[int]int, error) {
+	return m.state.Sample(shots, m.rng)
+}
+
+// MeasureShots measures `qubit` on `shots` independent clones of the machine state, as if the
+// circuit were re-run for each shot, and returns how many shots gave 1. The live state and the
+// gate history are untouched; only the measurement RNG advances.
+func (m *QuantumRISCVMachine) MeasureShots(qubit, shots int) (int, error) {
+	if shots < 1 || shots > MaxShots {
+		return 0, fmt.Errorf("shot count must be between 1 and %d, got %d", MaxShots, shots)
+	}
+	if err := m.validateQubits(qubit); err != nil {
+		return 0, err
+	}
+	ones := 0
+	for shot := 0; shot < shots; shot++ {
+		result, err := m.state.Clone().Measure(qubit, m.rng)
+		if err != nil {
+			return 0, err
+		}
+		ones += result.Outcome
+	}
+	return ones, nil
+}
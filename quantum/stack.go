@@ -0,0 +1,39 @@
+package quantum
+
+import "fmt"
+
+// MemorySize is the size in bytes of each machine's memory
+const MemorySize = 1024 * 1024
+
+// StackPointer is the register the RISC-V ABI uses as the stack pointer (sp)
+const StackPointer = 2
+
+// DefaultStackTop is the initial sp: the top of memory, so the stack grows down from the end
+const DefaultStackTop = MemorySize
+
+// validateStackTop checks that a stack top lies within memory (the first push writes below it)
+func validateStackTop(addr uint64) error {
+	if addr == 0 || addr > MemorySize {
+		return fmt.Errorf("stack top 0x%x is outside memory (must be in 0x1-0x%x)", addr, MemorySize)
+	}
+	return nil
+}
+
+// SetStackTop sets sp (x2) to addr, as a loader does before starting a program
+func (m *QuantumRISCVMachine) SetStackTop(addr uint64) error {
+	if err := validateStackTop(addr); err != nil {
+		return err
+	}
+	m.setRegister(StackPointer, addr)
+	m.stackTop = addr
+	return nil
+}
+
+// SetStackTop sets sp (x2) to addr, as a loader does before starting a program
+func (m *HostQuantumMachine) SetStackTop(addr uint64) error {
+	if err := validateStackTop(addr); err != nil {
+		return err
+	}
+	m.SetRegister(StackPointer, addr)
+	return nil
+}
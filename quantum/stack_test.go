@@ -0,0 +1,76 @@
+package quantum
+
+import "testing"
+
+// pushPop stores x5 and x6 on the stack, clobbers them and pops them back in swapped order
+const pushPop = `addi x5, x0, 11
+addi x6, x0, 22
+addi sp, sp, -8
+sw   x5, 4(sp)
+sw   x6, 0(sp)
+addi x5, x0, 0
+addi x6, x0, 0
+lw   x5, 0(sp)
+lw   x6, 4(sp)
+addi sp, sp, 8
+`
+
+func TestStackTop(t *testing.T) {
+	tests := []struct {
+		stackTop uint64
+		wantSP   uint64
+	}{
+		{0, DefaultStackTop}, // Default
+		{DefaultStackTop, DefaultStackTop},
+		{0x8000, 0x8000},
+	}
+	for _, tt := range tests {
+		result, err := RunProgram(pushPop, Options{StackTop: tt.stackTop})
+		if err != nil {
+			t.Errorf("stack top %#x: %v", tt.stackTop, err)
+			continue
+		}
+		regs := result.Registers
+		if regs[StackPointer] != tt.wantSP || regs[5] != 22 || regs[6] != 11 {
+			t.Errorf("stack top %#x: sp = %#x, x5 = %d, x6 = %d, want sp = %#x, x5 = 22, x6 = 11",
+				tt.stackTop, regs[StackPointer], regs[5], regs[6], tt.wantSP)
+		}
+	}
+}
+
+func TestStackTopOnBothMachines(t *testing.T) {
+	vm, host := NewQuantumRISCVMachine(1), NewHostQuantumMachine(1)
+	if sp := vm.GetRegisters()[StackPointer]; sp != DefaultStackTop {
+		t.Errorf("new VM sp = %#x, want %#x", sp, DefaultStackTop)
+	}
+	if sp := host.GetRegister(StackPointer); sp != DefaultStackTop {
+		t.Errorf("new host sp = %#x, want %#x", sp, DefaultStackTop)
+	}
+
+	for _, addr := range []uint64{0, MemorySize + 1, 1 << 40} {
+		if err := vm.SetStackTop(addr); err == nil {
+			t.Errorf("VM accepted stack top %#x", addr)
+		}
+		if err := host.SetStackTop(addr); err == nil {
+			t.Errorf("host accepted stack top %#x", addr)
+		}
+	}
+	if _, err := RunProgram(pushPop, Options{StackTop: MemorySize + 4}); err == nil {
+		t.Error("RunProgram accepted a stack top past the end of memory")
+	}
+
+	if err := host.SetStackTop(0x4000); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.LoadRISCSource(pushPop); err != nil {
+		t.Fatal(err)
+	}
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+	if err := host.ExecuteProgram(); err != nil {
+		t.Fatal(err)
+	}
+	if host.GetRegister(StackPointer) != 0x4000 || host.GetRegister(5) != 22 || host.GetRegister(6) != 11 {
+		t.Errorf("host push/pop below 0x4000: sp = %#x, x5 = %d, x6 = %d", host.GetRegister(StackPointer),
+			host.GetRegister(5), host.GetRegister(6))
+	}
+}
@@ -1,6 +1,7 @@
 package quantum
 
 import (
+	"fmt"
 	"math"
 	"math/cmplx"
 )
@@ -8,46 +9,203 @@ import (
 // Complex128 represents a complex number with float64 precision
 type Complex128 = complex128
 
+// Storage: a state of up to denseQubitLimit qubits keeps all 2^n amplitudes in a slice. Larger
+// states keep only their nonzero amplitudes in a map, so a fresh 2000-qubit machine stores the
+// single |0...0⟩ amplitude and gates only visit populated basis states. Both are amplitudeStore
+// implementations; code in this package reads and writes amplitudes through amp, setAmp and each.
+// SetBackend can force either form (see backend.go).
+
+// denseQubitLimit is the largest state stored as a dense vector (2^20 amplitudes, 16 MiB)
+const denseQubitLimit = 20
+
+// sparsePruneThreshold is the |amplitude|² below which a sparse state drops an amplitude
+const sparsePruneThreshold = 1e-30
+
 // QuantumState represents the state of a quantum register
 type QuantumState struct {
-	amplitudes []Complex128
+	store      amplitudeStore // The amplitudes, in the form the backend calls for
 	numQubits  int
+	polar      bool      // Apply diagonal (phase) gates in polar form; see polar.go
+	magnitudes []float64 // Exact amplitude magnitudes cached in polar mode (nil when stale)
+
+	renormalizeEvery int // Renormalize after this many gates (0: only measurements renormalize)
+	gatesApplied     int // Gates applied since the last periodic renormalization
+
+	backend string // Storage backend selected with SetBackend ("" is BackendAuto); see backend.go
 }
 
-// NewQuantumState creates a new quantum state with the specified number of qubits
+// NewQuantumState creates a new quantum state with the specified number of qubits, initialized
+// to |0⟩^⊗n
 func NewQuantumState(numQubits int) *QuantumState {
-	size := 1 << numQubits
-	return &QuantumState{
-		amplitudes: make([]Complex128, size),
-		numQubits:  numQubits,
-	}
+	qs := NewUninitializedQuantumState(numQubits)
+	qs.InitializeZeroState()
+	return qs
+}
+
+// NewUninitializedQuantumState creates a state whose amplitudes are all zero. That is not a
+// valid quantum state (it cannot be normalized); it is a buffer for callers that write every
+// amplitude themselves, such as ImportState.
+func NewUninitializedQuantumState(numQubits int) *QuantumState {
+	return &QuantumState{store: newStore(numQubits, numQubits <= denseQubitLimit), numQubits: numQubits}
+}
+
+// newDenseQuantumState creates an all-zero state that stores all 2^n amplitudes regardless of
+// its size
+func newDenseQuantumState(numQubits int) *QuantumState {
+	return &QuantumState{store: newStore(numQubits, true), numQubits: numQubits}
 }
 
 // InitializeZeroState sets the quantum state to |0⟩^⊗n
 func (qs *QuantumState) InitializeZeroState() {
-	qs.amplitudes[0] = 1.0
+	qs.setAmp(BasisState{}, 1.0)
+	qs.invalidatePolar()
 }
 
-// GetAmplitude returns the amplitude at the specified index
+// GetAmplitude returns the amplitude at the specified index (see IndexQubits)
 func (qs *QuantumState) GetAmplitude(index int) Complex128 {
-	return qs.amplitudes[index]
+	return qs.amp(NewBasisState(index))
 }
 
-// SetAmplitude sets the amplitude at the specified index
+// SetAmplitude sets the amplitude at the specified index (see IndexQubits)
 func (qs *QuantumState) SetAmplitude(index int, value Complex128) {
-	qs.amplitudes[index] = value
+	qs.setAmp(NewBasisState(index), value)
+	qs.invalidatePolar()
 }
 
-// Normalize normalizes the quantum state
-func (qs *QuantumState) Normalize() {
-	var sum float64
-	for _, amp := range qs.amplitudes {
-		sum += real(amp*cmplx.Conj(amp))
+// amp returns the amplitude of basis state b
+func (qs *QuantumState) amp(b BasisState) Complex128 {
+	return qs.store.amp(b)
+}
+
+// setAmp writes the amplitude of basis state b; a sparse state drops negligible amplitudes
+func (qs *QuantumState) setAmp(b BasisState, value Complex128) {
+	qs.store.setAmp(b, value)
+}
+
+// each calls fn with every nonzero amplitude in increasing basis state order. fn may overwrite
+// amplitudes through setAmp; entries it removes before they are reached are skipped.
+func (qs *QuantumState) each(fn func(b BasisState, amp Complex128)) {
+	qs.store.each(fn)
+}
+
+// inRange reports whether index names a basis state of this state
+func (qs *QuantumState) inRange(index int) bool {
+	return index >= 0 && (qs.numQubits >= IndexQubits || index < 1<<qs.numQubits)
+}
+
+// checkQubit returns an error unless qubit exists
+func (qs *QuantumState) checkQubit(qubit int) error {
+	if qubit < 0 || qubit >= qs.numQubits {
+		return fmt.Errorf("invalid qubit number: %d", qubit)
 	}
-	norm := 1.0 / math.Sqrt(sum)
-	for i := range qs.amplitudes {
-		qs.amplitudes[i] *= complex(norm, 0)
+	return nil
+}
+
+// Norm returns the total probability Σ|amplitude|² without normalizing.
+// It should stay ≈1; any drift points at a non-unitary operation or accumulated rounding error.
+func (qs *QuantumState) Norm() float64 {
+	return qs.store.norm()
+}
+
+// minNormalizableNorm is the smallest total probability Normalize will rescale; below it (or
+// for a NaN norm) the scale factor would overflow and poison every amplitude with Inf or NaN
+const minNormalizableNorm = 1e-300
+
+// Normalize normalizes the quantum state. A state with zero (or near-zero, or NaN) norm, e.g.
+// after a bad projection, cannot be normalized: it is left untouched and an error is returned.
+func (qs *QuantumState) Normalize() error {
+	sum := qs.Norm()
+	if !(sum >= minNormalizableNorm) {
+		return fmt.Errorf("cannot normalize: state has zero norm (total probability %g)", sum)
+	}
+	qs.invalidatePolar()
+	qs.store.scale(complex(1.0/math.Sqrt(sum), 0))
+	return nil
+}
+
+// RenormalizeEvery makes gates renormalize the state after every n of them, to stop rounding
+// error accumulating over very long circuits. Unitary gates preserve the norm, so by default
+// (n = 0) they never renormalize and only measurements, which project the state, do; a drifting
+// norm then shows up in the norm command instead of being hidden.
+func (qs *QuantumState) RenormalizeEvery(n int) error {
+	if n < 0 {
+		return fmt.Errorf("renormalization interval must be 0 (off) or positive, got %d", n)
 	}
+	qs.renormalizeEvery = n
+	qs.gatesApplied = 0
+	return nil
+}
+
+// RenormalizeInterval returns the number of gates between renormalizations (0 when off)
+func (qs *QuantumState) RenormalizeInterval() int {
+	return qs.renormalizeEvery
+}
+
+// gateApplied counts a gate towards the periodic renormalization set by RenormalizeEvery, and
+// lets the auto backend switch storage
+func (qs *QuantumState) gateApplied() {
+	qs.growBackend()
+	if qs.renormalizeEvery == 0 {
+		return
+	}
+	if qs.gatesApplied++; qs.gatesApplied >= qs.renormalizeEvery {
+		qs.gatesApplied = 0
+		qs.Normalize() // A zero-norm state stays zero; the next measurement reports it
+	}
+}
+
+// ApplyGlobalPhase multiplies every amplitude by e^{iθ}.
+// A global phase alone never changes measurement probabilities, but it becomes a relative
+// phase once the operation is controlled, which is why controlled-phase constructions need it.
+func (qs *QuantumState) ApplyGlobalPhase(theta float64) {
+	phase := cmplx.Exp(complex(0, theta))
+	qs.each(func(b BasisState, amp Complex128) { // Only touch populated amplitudes
+		if qs.usePolar() {
+			qs.rotatePhase(int(b.low), theta)
+		} else {
+			qs.setAmp(b, amp*phase)
+		}
+	})
+}
+
+// BasisAmplitude is the amplitude of one computational basis state
+type BasisAmplitude struct {
+	Index     int // Basis state index; bit q is the value of qubit q. -1 when Basis has no index.
+	Basis     BasisState
+	Amplitude Complex128
+}
+
+// GetAmplitudes returns a copy of the full amplitude vector, indexed by basis state. States of
+// more than MaxDenseQubits qubits are too large for one and return nil; use NonzeroAmplitudes.
+func (qs *QuantumState) GetAmplitudes() []Complex128 {
+	if dense, ok := qs.store.(denseStore); ok {
+		return append([]Complex128(nil), dense...)
+	}
+	if qs.numQubits > MaxDenseQubits {
+		return nil
+	}
+	amplitudes := make([]Complex128, 1<<qs.numQubits)
+	qs.each(func(b BasisState, amp Complex128) { amplitudes[b.low] = amp })
+	return amplitudes
+}
+
+// NonzeroAmplitudes returns the populated amplitudes sorted by basis state
+func (qs *QuantumState) NonzeroAmplitudes() []BasisAmplitude {
+	var nonzero []BasisAmplitude
+	qs.each(func(b BasisState, amp Complex128) {
+		index, ok := b.Index()
+		if !ok {
+			index = -1
+		}
+		nonzero = append(nonzero, BasisAmplitude{Index: index, Basis: b, Amplitude: amp})
+	})
+	return nonzero
+}
+
+// SupportSize returns the number of basis states with a nonzero amplitude: 1 for a basis state,
+// 2 for a Bell pair, 2^n for a uniform superposition. It gauges how spread out the state is.
+func (qs *QuantumState) SupportSize() int {
+	return qs.store.support()
 }
 
 // NumQubits returns the number of qubits in the quantum state
@@ -55,9 +213,114 @@ func (qs *QuantumState) NumQubits() int {
 	return qs.numQubits
 }
 
+// InnerProduct returns ⟨qs|other⟩, the sum over basis states of conj(qs amplitude) × other
+// amplitude. Both states must have the same number of qubits.
+func (qs *QuantumState) InnerProduct(other *QuantumState) (Complex128, error) {
+	if qs.numQubits != other.numQubits {
+		return 0, fmt.Errorf("cannot compare states with %d and %d qubits", qs.numQubits, other.numQubits)
+	}
+	var sum Complex128
+	// Only basis states populated in qs contribute, so sparse states stay cheap
+	qs.each(func(b BasisState, amp Complex128) {
+		sum += cmplx.Conj(amp) * other.amp(b)
+	})
+	return sum, nil
+}
+
+// Fidelity returns |⟨qs|other⟩|², the probability that other passes a test for being qs: 1 for
+// identical states (up to a global phase), 0 for orthogonal ones. Both states are normalized
+// first, so rounding drift in either does not push the fidelity past 1.
+func (qs *QuantumState) Fidelity(other *QuantumState) (float64, error) {
+	overlap, err := qs.InnerProduct(other)
+	if err != nil {
+		return 0, err
+	}
+	norms := qs.Norm() * other.Norm()
+	if norms == 0 {
+		return 0, fmt.Errorf("cannot compute fidelity: a state has zero norm")
+	}
+	return real(overlap*cmplx.Conj(overlap)) / norms, nil
+}
+
 // Clone creates a deep copy of the quantum state
 func (qs *QuantumState) Clone() *QuantumState {
-	clone := NewQuantumState(qs.numQubits)
-	copy(clone.amplitudes, qs.amplitudes)
-	return clone
-} 
\ No newline at end of file
+	return &QuantumState{
+		store:            qs.store.clone(),
+		numQubits:        qs.numQubits,
+		polar:            qs.polar,
+		renormalizeEvery: qs.renormalizeEvery,
+		backend:          qs.backend,
+	}
+}
+
+// Noise: ApplyBitFlipNoise and ApplyAmplitudeDamping are simple single-qubit channels applied to
+// each qubit independently. They are simulated as quantum trajectories: instead of evolving a
+// density matrix, the state vector follows one branch of the channel sampled with rng, so the
+// average over many seeded runs reproduces the noisy statistics.
+
+// noiseQubits validates a channel strength and the qubits it acts on; no qubits selects every
+// qubit
+func (qs *QuantumState) noiseQubits(strength float64, qubits []int) ([]int, error) {
+	if !(strength >= 0 && strength <= 1) {
+		return nil, fmt.Errorf("noise probability must be between 0 and 1, got %g", strength)
+	}
+	if len(qubits) == 0 {
+		for q := 0; q < qs.numQubits; q++ {
+			qubits = append(qubits, q)
+		}
+	}
+	for _, q := range qubits {
+		if err := qs.checkQubit(q); err != nil {
+			return nil, err
+		}
+	}
+	return qubits, nil
+}
+
+// ApplyBitFlipNoise flips each of qubits (every qubit when none are given) with probability p,
+// i.e. applies X with probability p and leaves the qubit alone otherwise
+func (qs *QuantumState) ApplyBitFlipNoise(p float64, rng RNG, qubits ...int) error {
+	qubits, err := qs.noiseQubits(p, qubits)
+	if err != nil {
+		return err
+	}
+	for _, q := range qubits {
+		if rng.Float64() < p {
+			X.Apply(qs, q, nil)
+		}
+	}
+	return nil
+}
+
+// ApplyAmplitudeDamping models energy loss (T1 decay) on each of qubits (every qubit when none
+// are given): with probability gamma·P(1) the qubit decays, |1⟩ → |0⟩; otherwise the |1⟩
+// amplitudes shrink by √(1-gamma), which is the evidence of not having decayed. gamma = 1 resets
+// every qubit to |0⟩.
+func (qs *QuantumState) ApplyAmplitudeDamping(gamma float64, rng RNG, qubits ...int) error {
+	qubits, err := qs.noiseQubits(gamma, qubits)
+	if err != nil || gamma == 0 {
+		return err
+	}
+	keep := complex(math.Sqrt(1-gamma), 0)
+	for _, q := range qubits {
+		decayed := rng.Float64() < gamma*qs.MeasureProbability(q, 1)
+		entries := qs.NonzeroAmplitudes()
+		for _, entry := range entries {
+			qs.setAmp(entry.Basis, 0)
+		}
+		for _, entry := range entries {
+			switch excited := entry.Basis.Bit(q) == 1; {
+			case decayed && excited: // The |1⟩ amplitudes move to |0⟩
+				qs.setAmp(entry.Basis.flip(q), entry.Amplitude)
+			case !decayed && excited:
+				qs.setAmp(entry.Basis, entry.Amplitude*keep)
+			case !decayed:
+				qs.setAmp(entry.Basis, entry.Amplitude)
+			}
+		}
+		if err := qs.Normalize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+	"path/filepath"
+	"testing"
+)
+
+// approxEqual reports whether two amplitudes agree to within rounding
+func approxEqual(a, b Complex128) bool {
+	return cmplx.Abs(a-b) < 1e-9
+}
+
+// dense returns the amplitudes of a dense state, for tests that read or write them directly
+func dense(qs *QuantumState) denseStore {
+	return qs.store.(denseStore)
+}
+
+func TestApplyGlobalPhase(t *testing.T) {
+	tests := []struct {
+		theta float64
+		polar bool
+	}{
+		{0, false},
+		{math.Pi / 3, false},
+		{-math.Pi / 2, false},
+		{math.Pi, true},
+		{2.5, true},
+	}
+	for _, tt := range tests {
+		state := NewQuantumState(2)
+		state.SetPolarPhases(tt.polar)
+		H.Apply(state, 0, nil)
+		RY(0.8).Apply(state, 1, nil)
+		before := state.GetAmplitudes()
+		state.ApplyGlobalPhase(tt.theta)
+		phase := cmplx.Exp(complex(0, tt.theta))
+		for i, amp := range state.GetAmplitudes() {
+			if !approxEqual(amp, before[i]*phase) {
+				t.Errorf("theta %g (polar %v): amplitude %d = %v, want %v", tt.theta, tt.polar, i, amp,
+					before[i]*phase)
+			}
+		}
+		if p := state.MeasureProbability(0, 1); math.Abs(p-0.5) > 1e-9 {
+			t.Errorf("theta %g: P(q0=1) changed to %g", tt.theta, p)
+		}
+	}
+}
+
+func TestSupportSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(qs *QuantumState)
+		want    int
+	}{
+		{"ground", func(qs *QuantumState) {}, 1},
+		{"basis", func(qs *QuantumState) { X.Apply(qs, 2, nil) }, 1},
+		{"bell", func(qs *QuantumState) {
+			H.Apply(qs, 0, nil)
+			CNOT.Apply(qs, 1, []int{0})
+		}, 2},
+		{"uniform", func(qs *QuantumState) {
+			for q := 0; q < 4; q++ {
+				H.Apply(qs, q, nil)
+			}
+		}, 16},
+		{"interference", func(qs *QuantumState) { // H twice returns to |0⟩ exactly
+			H.Apply(qs, 1, nil)
+			H.Apply(qs, 1, nil)
+		}, 1},
+	}
+	for _, tt := range tests {
+		for _, backend := range []string{BackendDense, BackendSparse} {
+			qs := NewQuantumState(4)
+			if err := qs.SetBackend(backend); err != nil {
+				t.Fatal(err)
+			}
+			tt.prepare(qs)
+			if got := qs.SupportSize(); got != tt.want {
+				t.Errorf("%s (%s): SupportSize() = %d, want %d", tt.name, backend, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		amps    []Complex128
+		want    []Complex128
+		wantErr bool
+	}{
+		{"scaled", []Complex128{3, 4i}, []Complex128{0.6, 0.8i}, false},
+		{"already normalized", []Complex128{0, 1}, []Complex128{0, 1}, false},
+		{"tiny but normalizable", []Complex128{1e-100, 0}, []Complex128{1, 0}, false},
+		{"zero", []Complex128{0, 0}, []Complex128{0, 0}, true},
+		{"below the limit", []Complex128{1e-160, 0}, []Complex128{1e-160, 0}, true},
+		{"NaN", []Complex128{complex(math.NaN(), 0), 0}, nil, true},
+	}
+	for _, tt := range tests {
+		qs := NewQuantumState(1)
+		copy(dense(qs), tt.amps)
+		err := qs.Normalize()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		for i, want := range tt.want { // A rejected state is left untouched
+			if !approxEqual(dense(qs)[i], want) {
+				t.Errorf("%s: amplitude %d = %v, want %v", tt.name, i, dense(qs)[i], want)
+			}
+		}
+		for _, amp := range dense(qs) {
+			if cmplx.IsInf(amp) {
+				t.Errorf("%s: Normalize produced %v", tt.name, amp)
+			}
+		}
+	}
+
+	// A zero-norm state is reported by the next measurement instead of yielding NaN probabilities
+	qs := NewQuantumState(1)
+	dense(qs)[0] = 0
+	if _, err := qs.Measure(0, NewSplitMix64(1)); err == nil {
+		t.Error("measuring a zero-norm state succeeded")
+	}
+}
+
+func TestHostNormalizeZeroNorm(t *testing.T) {
+	m := NewHostQuantumMachine(1)
+	state := NewHostQuantumState(1)
+	state.amplitudes[0] = 0
+	if err := m.normalizeHostState(state); err == nil {
+		t.Error("normalizeHostState accepted a zero-norm register")
+	}
+	for i, amp := range state.amplitudes {
+		if amp != 0 {
+			t.Errorf("zero-norm register amplitude %d became %v", i, amp)
+		}
+	}
+}
+
+func TestNewStatesStartInZeroState(t *testing.T) {
+	for _, numQubits := range []int{1, 3, 10, 30, 2000} {
+		qs := NewQuantumState(numQubits)
+		nonzero := qs.NonzeroAmplitudes()
+		if len(nonzero) != 1 || nonzero[0].Index != 0 || nonzero[0].Amplitude != 1 {
+			t.Errorf("%d qubits: nonzero amplitudes %v, want 1 at |0...0⟩", numQubits, nonzero)
+		}
+		if norm := qs.Norm(); norm != 1 {
+			t.Errorf("%d qubits: norm %g, want 1", numQubits, norm)
+		}
+		if blank := NewUninitializedQuantumState(numQubits); blank.SupportSize() != 0 {
+			t.Errorf("%d qubits: uninitialized state has %d nonzero amplitudes", numQubits, blank.SupportSize())
+		}
+	}
+
+	for _, numQubits := range []int{1, 2, 4} {
+		host := NewHostQuantumState(numQubits)
+		for i, amp := range host.amplitudes {
+			want := Complex128(0)
+			if i == 0 {
+				want = 1
+			}
+			if amp != want {
+				t.Errorf("host %d qubits: amplitude %d = %v, want %v", numQubits, i, amp, want)
+			}
+		}
+	}
+
+	// A fresh quantum register measures 0 without any explicit initialization
+	if result, err := NewQuantumState(1).Measure(0, NewSplitMix64(1)); err != nil || result.Outcome != 0 {
+		t.Errorf("measuring a new state: outcome %d, error %v, want 0", result.Outcome, err)
+	}
+}
+
+func TestInnerProductAndFidelity(t *testing.T) {
+	bell := func(qs *QuantumState) {
+		H.Apply(qs, 0, nil)
+		CNOT.Apply(qs, 1, []int{0})
+	}
+	tests := []struct {
+		name        string
+		left, right func(qs *QuantumState)
+		overlap     Complex128
+		fidelity    float64
+	}{
+		{"identical ground", func(qs *QuantumState) {}, func(qs *QuantumState) {}, 1, 1},
+		{"orthogonal basis", func(qs *QuantumState) {}, func(qs *QuantumState) { X.Apply(qs, 0, nil) }, 0, 0},
+		{"zero and plus", func(qs *QuantumState) {}, func(qs *QuantumState) { H.Apply(qs, 0, nil) },
+			complex(1/math.Sqrt2, 0), 0.5},
+		{"bell with itself", bell, bell, 1, 1},
+		{"global phase", bell, func(qs *QuantumState) {
+			bell(qs)
+			qs.ApplyGlobalPhase(math.Pi / 2)
+		}, 1i, 1},
+		{"bell and ground", bell, func(qs *QuantumState) {}, complex(1/math.Sqrt2, 0), 0.5},
+	}
+	for _, tt := range tests {
+		for _, backend := range []string{BackendDense, BackendSparse} {
+			left, right := NewQuantumState(2), NewQuantumState(2)
+			if err := left.SetBackend(backend); err != nil {
+				t.Fatal(err)
+			}
+			tt.left(left)
+			tt.right(right)
+			overlap, err := left.InnerProduct(right)
+			if err != nil {
+				t.Fatalf("%s (%s): InnerProduct: %v", tt.name, backend, err)
+			}
+			if !approxEqual(overlap, tt.overlap) {
+				t.Errorf("%s (%s): inner product %v, want %v", tt.name, backend, overlap, tt.overlap)
+			}
+			fidelity, err := left.Fidelity(right)
+			if err != nil {
+				t.Fatalf("%s (%s): Fidelity: %v", tt.name, backend, err)
+			}
+			if math.Abs(fidelity-tt.fidelity) > 1e-9 {
+				t.Errorf("%s (%s): fidelity %g, want %g", tt.name, backend, fidelity, tt.fidelity)
+			}
+		}
+	}
+
+	// ⟨a|b⟩ is the conjugate of ⟨b|a⟩
+	a, b := NewQuantumState(1), NewQuantumState(1)
+	H.Apply(a, 0, nil)
+	RY(0.7).Apply(b, 0, nil)
+	RZ(1.1).Apply(b, 0, nil)
+	ab, _ := a.InnerProduct(b)
+	ba, _ := b.InnerProduct(a)
+	if !approxEqual(ab, cmplx.Conj(ba)) {
+		t.Errorf("⟨a|b⟩ = %v, want conj(⟨b|a⟩) = %v", ab, cmplx.Conj(ba))
+	}
+}
+
+func TestInnerProductErrors(t *testing.T) {
+	if _, err := NewQuantumState(2).InnerProduct(NewQuantumState(3)); err == nil {
+		t.Error("inner product of 2- and 3-qubit states succeeded")
+	}
+	if _, err := NewQuantumState(1).Fidelity(NewQuantumState(2)); err == nil {
+		t.Error("fidelity of 1- and 2-qubit states succeeded")
+	}
+	if _, err := NewQuantumState(2).Fidelity(NewUninitializedQuantumState(2)); err == nil {
+		t.Error("fidelity with a zero-norm state succeeded")
+	}
+}
+
+func TestRenormalizeEvery(t *testing.T) {
+	tests := []struct {
+		interval int
+		gates    int
+		want     []float64 // Norm after each gate, starting from norm 4
+	}{
+		{0, 5, []float64{4, 4, 4, 4, 4}},
+		{1, 3, []float64{1, 1, 1}},
+		{3, 5, []float64{4, 4, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		for _, backend := range []string{BackendDense, BackendSparse} {
+			qs := NewQuantumState(2)
+			if err := qs.SetBackend(backend); err != nil {
+				t.Fatal(err)
+			}
+			if err := qs.RenormalizeEvery(tt.interval); err != nil {
+				t.Fatal(err)
+			}
+			qs.SetAmplitude(0, 2)
+			for g := 0; g < tt.gates; g++ {
+				// Single- and two-qubit gates both count towards the interval
+				if g%2 == 0 {
+					H.Apply(qs, 0, nil)
+				} else {
+					CNOT.Apply(qs, 1, []int{0})
+				}
+				if norm := qs.Norm(); math.Abs(norm-tt.want[g]) > 1e-9 {
+					t.Errorf("every %d (%s): norm after gate %d is %g, want %g", tt.interval, backend, g+1, norm,
+						tt.want[g])
+				}
+			}
+		}
+	}
+
+	qs := NewQuantumState(1)
+	if err := qs.RenormalizeEvery(-1); err == nil {
+		t.Error("RenormalizeEvery(-1) succeeded")
+	}
+	if got := qs.RenormalizeInterval(); got != 0 {
+		t.Errorf("rejected interval changed the setting to %d", got)
+	}
+}
+
+func TestRenormalizeIntervalIsKept(t *testing.T) {
+	qs := NewQuantumState(2)
+	qs.RenormalizeEvery(7)
+	if got := qs.Clone().RenormalizeInterval(); got != 7 {
+		t.Errorf("clone: interval %d, want 7", got)
+	}
+	sparse := NewQuantumState(2)
+	sparse.SetBackend(BackendSparse)
+	sparse.RenormalizeEvery(7)
+	if got := sparse.Clone().RenormalizeInterval(); got != 7 {
+		t.Errorf("sparse clone: interval %d, want 7", got)
+	}
+
+	filename := filepath.Join(t.TempDir(), "state.bin")
+	if err := NewQuantumState(3).SaveState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := qs.LoadState(filename); err != nil {
+		t.Fatal(err)
+	}
+	if got := qs.RenormalizeInterval(); got != 7 {
+		t.Errorf("checkpoint load: interval %d, want 7", got)
+	}
+
+	m := NewQuantumRISCVMachine(2)
+	m.GetState().RenormalizeEvery(5)
+	m.ResetQuantumState()
+	if got := m.GetState().RenormalizeInterval(); got != 5 {
+		t.Errorf("machine reset: interval %d, want 5", got)
+	}
+	if err := m.LoadState(NewQuantumState(2)); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetState().RenormalizeInterval(); got != 5 {
+		t.Errorf("machine state load: interval %d, want 5", got)
+	}
+}
@@ -0,0 +1,199 @@
+package quantum
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// State interchange: ExportState and ImportState move the nonzero amplitudes of a state to and
+// from a file as index/re/im rows, in JSON or CSV chosen by the file extension. Unlike a full
+// machine snapshot this carries only the quantum state, so other tools can produce or consume it.
+
+// importNormTolerance is how far an imported state's total probability may drift from 1
+const importNormTolerance = 1e-6
+
+// csvStateHeader is the header row of a CSV state file
+var csvStateHeader = []string{"index", "re", "im"}
+
+// amplitudeRecord is one basis state amplitude in an exported state file
+type amplitudeRecord struct {
+	Index int     `json:"index"`
+	Re    float64 `json:"re"`
+	Im    float64 `json:"im"`
+}
+
+// stateFile is the JSON layout of an exported state
+type stateFile struct {
+	NumQubits  int               `json:"num_qubits"`
+	Amplitudes []amplitudeRecord `json:"amplitudes"`
+}
+
+// stateFormat returns "json" or "csv" from the extension of path
+func stateFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return "json", nil
+	case ".csv":
+		return "csv", nil
+	default:
+		return "", fmt.Errorf("unsupported state file extension %q (use .json or .csv)", ext)
+	}
+}
+
+// ExportState writes the nonzero amplitudes of qs to path as JSON or CSV
+func ExportState(qs *QuantumState, path string) error {
+	format, err := stateFormat(path)
+	if err != nil {
+		return err
+	}
+	var records []amplitudeRecord
+	for _, basis := range qs.NonzeroAmplitudes() {
+		if basis.Index < 0 {
+			return errNoIndex("export the state", basis.Basis)
+		}
+		records = append(records, amplitudeRecord{basis.Index, real(basis.Amplitude), imag(basis.Amplitude)})
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %v", err)
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(stateFile{NumQubits: qs.NumQubits(), Amplitudes: records})
+	} else {
+		err = writeStateCSV(file, records)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	return nil
+}
+
+// writeStateCSV writes records under an index,re,im header, with floats in shortest exact form
+func writeStateCSV(w io.Writer, records []amplitudeRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvStateHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			strconv.Itoa(r.Index),
+			strconv.FormatFloat(r.Re, 'g', -1, 64),
+			strconv.FormatFloat(r.Im, 'g', -1, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportState reads a state file written by ExportState (or by hand) and reconstructs a
+// numQubits state from it. Amplitudes not listed are zero. The file is rejected if an index is
+// out of range or repeated, a value is not finite, or the state is not normalized.
+func ImportState(path string, numQubits int) (*QuantumState, error) {
+	format, err := stateFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %v", err)
+	}
+	defer file.Close()
+
+	var records []amplitudeRecord
+	if format == "json" {
+		var parsed stateFile
+		if err := json.NewDecoder(file).Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("malformed JSON state file: %v", err)
+		}
+		if parsed.NumQubits != 0 && parsed.NumQubits != numQubits {
+			return nil, fmt.Errorf("state file has %d qubits, machine has %d", parsed.NumQubits, numQubits)
+		}
+		records = parsed.Amplitudes
+	} else if records, err = readStateCSV(file); err != nil {
+		return nil, fmt.Errorf("malformed CSV state file: %v", err)
+	}
+	return stateFromRecords(records, numQubits)
+}
+
+// readStateCSV parses index,re,im rows after the header
+func readStateCSV(r io.Reader) ([]amplitudeRecord, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != strings.Join(csvStateHeader, ",") {
+		return nil, fmt.Errorf("expected header %q", strings.Join(csvStateHeader, ","))
+	}
+	records := make([]amplitudeRecord, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		line := i + 2 // 1-based, after the header
+		if len(row) != len(csvStateHeader) {
+			return nil, fmt.Errorf("line %d: expected 3 fields, got %d", line, len(row))
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(row[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid index %q", line, row[0])
+		}
+		re, errRe := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		im, errIm := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)
+		if errRe != nil || errIm != nil {
+			return nil, fmt.Errorf("line %d: invalid amplitude %q, %q", line, row[1], row[2])
+		}
+		records = append(records, amplitudeRecord{index, re, im})
+	}
+	return records, nil
+}
+
+// stateFromRecords builds a numQubits state from amplitude records and validates it
+func stateFromRecords(records []amplitudeRecord, numQubits int) (*QuantumState, error) {
+	state := NewUninitializedQuantumState(numQubits)
+	seen := make(map[int]bool, len(records))
+	for _, r := range records {
+		if !state.inRange(r.Index) {
+			return nil, fmt.Errorf("basis state %d is out of range for %d qubits", r.Index, numQubits)
+		}
+		if seen[r.Index] {
+			return nil, fmt.Errorf("basis state %d is listed more than once", r.Index)
+		}
+		if math.IsNaN(r.Re) || math.IsInf(r.Re, 0) || math.IsNaN(r.Im) || math.IsInf(r.Im, 0) {
+			return nil, fmt.Errorf("basis state %d has a non-finite amplitude", r.Index)
+		}
+		seen[r.Index] = true
+		state.setAmp(NewBasisState(r.Index), complex(r.Re, r.Im))
+	}
+	if norm := state.Norm(); math.Abs(norm-1) > importNormTolerance {
+		return nil, fmt.Errorf("state is not normalized: total probability is %.9f", norm)
+	}
+	return state, nil
+}
+
+// LoadState replaces the machine qubits with state, which must have the machine's qubit count,
+// and gives it the machine's state settings. The gate history no longer describes how the state
+// was prepared, so it is cleared.
+func (m *QuantumRISCVMachine) LoadState(state *QuantumState) error {
+	if state.NumQubits() != m.state.NumQubits() {
+		return fmt.Errorf("state has %d qubits, machine has %d", state.NumQubits(), m.state.NumQubits())
+	}
+	if err := state.copySettings(m.state); err != nil {
+		return err
+	}
+	m.state = state
+	m.history = nil
+	return nil
+}
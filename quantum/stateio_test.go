@@ -0,0 +1,96 @@
+package quantum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// samplePhaseState returns a 3-qubit state with unequal magnitudes and complex phases
+func samplePhaseState() *QuantumState {
+	state := NewQuantumState(3)
+	H.Apply(state, 0, nil)
+	T.Apply(state, 0, nil)
+	RY(0.7).Apply(state, 1, nil)
+	CNOT.Apply(state, 2, []int{1})
+	S.Apply(state, 2, nil)
+	return state
+}
+
+func TestExportImportStateRoundTrip(t *testing.T) {
+	for _, name := range []string{"state.json", "state.csv", "STATE.JSON"} {
+		path := filepath.Join(t.TempDir(), name)
+		want := samplePhaseState()
+		if err := ExportState(want, path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := ImportState(path, 3)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for i, amp := range want.GetAmplitudes() {
+			if got.GetAmplitudes()[i] != amp { // Floats are written in shortest exact form
+				t.Errorf("%s: amplitude %d = %v, want %v", name, i, got.GetAmplitudes()[i], amp)
+			}
+		}
+	}
+}
+
+func TestImportStateErrors(t *testing.T) {
+	tests := []struct {
+		name, content string
+	}{
+		{"state.txt", "index,re,im\n0,1,0\n"},
+		{"state.csv", "i,re,im\n0,1,0\n"},
+		{"state.csv", "index,re,im\n0,1\n"},
+		{"state.csv", "index,re,im\nzero,1,0\n"},
+		{"state.csv", "index,re,im\n8,1,0\n"},
+		{"state.csv", "index,re,im\n0,0.6,0\n"},
+		{"state.csv", "index,re,im\n0,0.6,0\n0,0.8,0\n"},
+		{"state.csv", "index,re,im\n0,NaN,0\n"},
+		{"state.json", `{"num_qubits": 2, "amplitudes": [{"index": 0, "re": 1, "im": 0}]}`},
+		{"state.json", `{"amplitudes": [{"index": -1, "re": 1, "im": 0}]}`},
+		{"state.json", `{"amplitudes": `},
+		{"missing.json", ""},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name)
+		if tt.name != "missing.json" {
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := ImportState(path, 3); err == nil {
+			t.Errorf("%s %q imported, want an error", tt.name, tt.content)
+		}
+	}
+
+	// Amplitudes not listed are zero, and num_qubits may be left out
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"amplitudes": [{"index": 5, "re": 0, "im": -1}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state, err := ImportState(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonzero := state.NonzeroAmplitudes(); len(nonzero) != 1 || nonzero[0].Index != 5 || nonzero[0].Amplitude != -1i {
+		t.Errorf("imported %v, want -i|101⟩", nonzero)
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	m := NewQuantumRISCVMachine(3)
+	if err := m.PrepareBell(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadState(NewQuantumState(2)); err == nil {
+		t.Error("LoadState accepted a 2-qubit state on a 3-qubit machine")
+	}
+	if err := m.LoadState(samplePhaseState()); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.GetGateHistory()) != 0 {
+		t.Error("LoadState kept the gate history of the replaced state")
+	}
+}
@@ -0,0 +1,63 @@
+package quantum
+
+// Stats counts the work a machine has done since it was created (or since ResetStats): unlike the
+// gate history, the counters survive state resets and imports, so they measure a whole session
+// or benchmark
+type Stats struct {
+	ClassicalInstructions int            // Base RISC-V instructions executed (ALU, branches, jumps, memory, ecall)
+	QuantumInstructions   int            // Q-RISC-V extension instructions executed (qgate.*, qapply, ...)
+	Gates                 map[string]int // Gates applied, by name ("H", "CNOT", "RZ", "SWAP", ...)
+	Measurements          int            // Qubit, quantum register and parity measurements
+}
+
+// TotalGates returns the number of gates applied, over every gate type
+func (s Stats) TotalGates() int {
+	total := 0
+	for _, count := range s.Gates {
+		total += count
+	}
+	return total
+}
+
+// GetStats returns a copy of the machine's execution counters
+func (m *QuantumRISCVMachine) GetStats() Stats {
+	stats := m.stats
+	stats.Gates = make(map[string]int, len(m.stats.Gates))
+	for name, count := range m.stats.Gates {
+		stats.Gates[name] = count
+	}
+	return stats
+}
+
+// ResetStats zeroes the execution counters
+func (m *QuantumRISCVMachine) ResetStats() {
+	m.stats = Stats{}
+}
+
+// countInstruction counts one executed instruction as quantum or classical
+func (m *QuantumRISCVMachine) countInstruction(opcode string) {
+	if instructionTable[opcode].kind == "quantum" {
+		m.stats.QuantumInstructions++
+	} else {
+		m.stats.ClassicalInstructions++
+	}
+}
+
+// countGate counts one applied gate, or a measurement for MEASURE, PARITY and COLLAPSE
+func (m *QuantumRISCVMachine) countGate(name string) {
+	if isMeasurement(name) {
+		m.stats.Measurements++
+		return
+	}
+	if m.stats.Gates == nil {
+		m.stats.Gates = make(map[string]int)
+	}
+	m.stats.Gates[name]++
+}
+
+// record appends an applied operation to the history, counts it and applies any gate noise
+func (m *QuantumRISCVMachine) record(r GateRecord) {
+	m.history = append(m.history, r)
+	m.countGate(r.Name)
+	m.applyNoise(r)
+}
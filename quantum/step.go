@@ -0,0 +1,236 @@
+package quantum
+
+import (
+	"fmt"
+	"sort"
+)
+
+// errProgramFinished is returned when stepping past the last instruction of the program
+func errProgramFinished(pc uint32) error {
+	return fmt.Errorf("program has finished (PC %d is past the last instruction)", pc)
+}
+
+// PC returns the index of the next instruction StepOnce will execute
+func (m *QuantumRISCVMachine) PC() uint32 {
+	return m.pc
+}
+
+// Finished reports whether the PC has run past the end of the loaded program
+func (m *QuantumRISCVMachine) Finished() bool {
+	return m.pc >= uint32(len(m.riscProgram))
+}
+
+// StepOnce executes the instruction at the PC and advances the PC. Unknown instructions loaded
+// with SetSkipUnknown are skipped as no-ops.
+func (m *QuantumRISCVMachine) StepOnce() error {
+	if m.Finished() {
+		return errProgramFinished(m.pc)
+	}
+	pc, inst := m.pc, m.riscProgram[m.pc]
+	if !IsKnownInstruction(inst.Opcode) {
+		m.skipped++
+		m.pc++
+		return nil
+	}
+	m.executed++
+	m.jumped = false
+	if err := m.executeRISCInstruction(inst); err != nil {
+		return fmt.Errorf("error at %s: %v", inst.Position(pc), err)
+	}
+	if !m.jumped {
+		m.pc++
+	}
+	return nil
+}
+
+// jumpTo moves the PC to the instruction index target for a taken branch or jump, so StepOnce
+// does not also advance it. A target outside the program (including a negative one) ends the run.
+func (m *QuantumRISCVMachine) jumpTo(target int64) {
+	m.pc = uint32(target)
+	m.jumped = true
+}
+
+// breakpointSet holds the instruction indices where Continue stops; both machines keep one
+type breakpointSet map[uint32]bool
+
+// set adds a breakpoint at index pc of a program with size instructions
+func (b *breakpointSet) set(pc uint32, size int) error {
+	if pc >= uint32(size) {
+		return fmt.Errorf("no instruction at index %d (the program has %d)", pc, size)
+	}
+	if *b == nil {
+		*b = make(breakpointSet)
+	}
+	(*b)[pc] = true
+	return nil
+}
+
+// clear removes the breakpoint at index pc, reporting whether there was one
+func (b breakpointSet) clear(pc uint32) bool {
+	had := b[pc]
+	delete(b, pc)
+	return had
+}
+
+// sorted returns the instruction indices with a breakpoint, in increasing order
+func (b breakpointSet) sorted() []uint32 {
+	pcs := make([]uint32, 0, len(b))
+	for pc := range b {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	return pcs
+}
+
+// stepper is a machine that executes a loaded program one instruction at a time
+type stepper interface {
+	PC() uint32
+	Finished() bool
+	StepOnce() error
+}
+
+// continueTo executes instructions of m from its PC until the program finishes or the PC reaches
+// one of breakpoints, and reports whether it stopped at a breakpoint. The instruction at the
+// starting PC always runs, so continuing from a breakpoint moves past it.
+func continueTo(m stepper, breakpoints breakpointSet) (bool, error) {
+	for first := true; !m.Finished(); first = false {
+		if !first && breakpoints[m.PC()] {
+			return true, nil
+		}
+		if err := m.StepOnce(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// SetBreakpoint makes Continue stop before executing the instruction at index pc
+func (m *QuantumRISCVMachine) SetBreakpoint(pc uint32) error {
+	return m.breakpoints.set(pc, len(m.riscProgram))
+}
+
+// ClearBreakpoint removes the breakpoint at index pc, reporting whether there was one
+func (m *QuantumRISCVMachine) ClearBreakpoint(pc uint32) bool {
+	return m.breakpoints.clear(pc)
+}
+
+// Breakpoints returns the instruction indices with a breakpoint, in increasing order
+func (m *QuantumRISCVMachine) Breakpoints() []uint32 {
+	return m.breakpoints.sorted()
+}
+
+// Continue executes instructions from the PC until the program finishes or the PC reaches a
+// breakpoint, and reports whether it stopped at a breakpoint. The instruction at the starting
+// PC always runs, so continuing from a breakpoint moves past it.
+func (m *QuantumRISCVMachine) Continue() (bool, error) {
+	return continueTo(m, m.breakpoints)
+}
+
+// LoadProgram installs an assembled program, such as QuantumRISCVMachine.GetRISCProgram after
+// LoadRISCProgram, and moves the PC to entry
+func (m *HostQuantumMachine) LoadProgram(program []RISCInstruction, entry uint32) {
+	m.program = program
+	m.entryPC = entry
+	m.pc = entry
+	m.breakpoints = nil // Indices of the previous program mean nothing in the new one
+}
+
+// GetRISCProgram returns the program installed by LoadProgram
+func (m *HostQuantumMachine) GetRISCProgram() []RISCInstruction {
+	return m.program
+}
+
+// PC returns the index of the next instruction StepOnce will execute
+func (m *HostQuantumMachine) PC() uint32 {
+	return m.pc
+}
+
+// Finished reports whether the PC has run past the end of the loaded program
+func (m *HostQuantumMachine) Finished() bool {
+	return m.pc >= uint32(len(m.program))
+}
+
+// SetBreakpoint makes Continue stop before executing the instruction at index pc
+func (m *HostQuantumMachine) SetBreakpoint(pc uint32) error {
+	return m.breakpoints.set(pc, len(m.program))
+}
+
+// ClearBreakpoint removes the breakpoint at index pc, reporting whether there was one
+func (m *HostQuantumMachine) ClearBreakpoint(pc uint32) bool {
+	return m.breakpoints.clear(pc)
+}
+
+// Breakpoints returns the instruction indices with a breakpoint, in increasing order
+func (m *HostQuantumMachine) Breakpoints() []uint32 {
+	return m.breakpoints.sorted()
+}
+
+// Continue executes instructions from the PC with host-native execution until the program
+// finishes or the PC reaches a breakpoint, and reports whether it stopped at a breakpoint
+func (m *HostQuantumMachine) Continue() (bool, error) {
+	return continueTo(m, m.breakpoints)
+}
+
+// SkippedInstructions returns how many unknown instructions the last run skipped
+func (m *HostQuantumMachine) SkippedInstructions() int {
+	return m.skipped
+}
+
+// SetLoopDetection enables infinite-loop detection in ExecuteProgram
+func (m *HostQuantumMachine) SetLoopDetection(enabled bool) {
+	m.detectLoops = enabled
+}
+
+// ExecuteProgram runs the loaded program from its entry point until the PC leaves the program
+func (m *HostQuantumMachine) ExecuteProgram() error {
+	if m.freshRun {
+		m.ResetQuantumState()
+	}
+	m.pc = m.entryPC
+	m.skipped = 0
+	var loops *LoopDetector
+	if m.detectLoops {
+		loops = NewLoopDetector()
+	}
+	for !m.Finished() {
+		prev := m.pc
+		if err := m.StepOnce(); err != nil {
+			return err
+		}
+		if loops != nil && m.pc <= prev { // Backward branch or jump taken
+			if err := loops.Check(m.pc, m.StateHash(m.pc)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StepOnce executes the instruction at the PC with host-native execution and advances the PC.
+// Unknown instructions loaded with skip-unknown are skipped as no-ops.
+func (m *HostQuantumMachine) StepOnce() error {
+	if m.Finished() {
+		return errProgramFinished(m.pc)
+	}
+	pc, inst := m.pc, m.program[m.pc]
+	if !IsKnownInstruction(inst.Opcode) {
+		m.skipped++
+		m.pc++
+		return nil
+	}
+	if err := CheckRegisters(inst); err != nil {
+		return fmt.Errorf("error at %s: %v", inst.Position(pc), err)
+	}
+
+	if instructionTable[inst.Opcode].kind == "quantum" {
+		if err := m.ExecuteQuantumRISCV(inst); err != nil {
+			return fmt.Errorf("error executing quantum instruction on host at %s: %v", inst.Position(pc), err)
+		}
+		m.pc++
+		return nil
+	}
+	if err := m.executeClassical(inst); err != nil {
+		return fmt.Errorf("error at %s: %v", inst.Position(pc), err)
+	}
+	return nil
+}
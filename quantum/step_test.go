@@ -0,0 +1,107 @@
+package quantum
+
+import "testing"
+
+const stepSource = `addi x5, x0, 3
+loop: addi x6, x6, 2
+      addi x5, x5, -1
+      bne  x5, x0, loop
+      add  x7, x6, x5
+`
+
+func TestStepOnceMatchesRun(t *testing.T) {
+	run := runMachine(t, stepSource, 1)
+
+	vm := NewQuantumRISCVMachine(1)
+	if err := vm.LoadRISCSource(stepSource); err != nil {
+		t.Fatal(err)
+	}
+	host := NewHostQuantumMachine(1)
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+
+	wantPCs := []uint32{0, 1, 2, 3, 1, 2, 3, 1, 2, 3, 4}
+	for i, want := range wantPCs {
+		if vm.PC() != want || host.PC() != want {
+			t.Fatalf("step %d: VM PC %d, host PC %d, want %d", i, vm.PC(), host.PC(), want)
+		}
+		if err := vm.StepOnce(); err != nil {
+			t.Fatalf("VM step %d: %v", i, err)
+		}
+		if err := host.StepOnce(); err != nil {
+			t.Fatalf("host step %d: %v", i, err)
+		}
+	}
+	if !vm.Finished() || !host.Finished() {
+		t.Fatalf("not finished after %d steps", len(wantPCs))
+	}
+	regs := vm.GetRegisters()
+	for reg, want := range run.GetRegisters() {
+		if got := host.GetRegister(uint8(reg)); regs[reg] != want || got != want {
+			t.Errorf("x%d: VM stepped %d, host stepped %d, run %d", reg, regs[reg], got, want)
+		}
+	}
+
+	if err := vm.StepOnce(); err == nil {
+		t.Error("VM stepped past the end of the program")
+	}
+	if err := host.StepOnce(); err == nil {
+		t.Error("host stepped past the end of the program")
+	}
+}
+
+func TestStepOnceReportsPosition(t *testing.T) {
+	vm := NewQuantumRISCVMachine(1)
+	if err := vm.LoadRISCSource("addi x1, x0, 1\nqgate.r X, x1\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.StepOnce(); err != nil {
+		t.Fatal(err)
+	}
+	err := vm.StepOnce()
+	if err == nil {
+		t.Fatal("qgate.r on qubit 1 of a 1-qubit machine succeeded")
+	}
+	if vm.PC() != 1 {
+		t.Errorf("PC moved to %d after a failed step, want 1", vm.PC())
+	}
+}
+
+func TestContinueStopsAtBreakpoints(t *testing.T) {
+	vm := NewQuantumRISCVMachine(1)
+	if err := vm.LoadRISCSource(stepSource); err != nil {
+		t.Fatal(err)
+	}
+	host := NewHostQuantumMachine(1)
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+
+	machines := map[string]interface {
+		stepper
+		SetBreakpoint(pc uint32) error
+		Continue() (bool, error)
+	}{"VM": vm, "host": host}
+	for name, m := range machines {
+		if err := m.SetBreakpoint(5); err == nil {
+			t.Errorf("%s: breakpoint past the program accepted", name)
+		}
+		if err := m.SetBreakpoint(2); err != nil {
+			t.Fatal(err)
+		}
+		// The loop body runs three times, stopping at the breakpoint each time, then the program ends
+		for i, want := range []bool{true, true, true, false} {
+			stopped, err := m.Continue()
+			if err != nil {
+				t.Fatalf("%s: continue %d: %v", name, i, err)
+			}
+			if stopped != want || (stopped && m.PC() != 2) {
+				t.Errorf("%s: continue %d stopped = %v at PC %d, want %v", name, i, stopped, m.PC(), want)
+			}
+		}
+		if !m.Finished() {
+			t.Errorf("%s: not finished after the last continue", name)
+		}
+	}
+	host.LoadProgram(vm.GetRISCProgram(), vm.EntryPC())
+	if len(host.Breakpoints()) != 0 {
+		t.Errorf("host kept breakpoints %v across LoadProgram", host.Breakpoints())
+	}
+}
@@ -0,0 +1,67 @@
+package quantum
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// System calls made with ecall: the call number goes in a7 and its argument in a0, following
+// the numbering of the RARS and SPIM simulators
+const (
+	SyscallPrintInt    = 1  // Print a0 as a signed decimal integer
+	SyscallPrintString = 4  // Print the NUL-terminated string at the address in a0
+	SyscallExit        = 10 // Halt the program
+	SyscallPrintChar   = 11 // Print the low byte of a0 as a character
+)
+
+// Registers the syscall ABI reads (a0 and a7)
+const (
+	syscallArgReg    = 10
+	syscallNumberReg = 17
+)
+
+// syscall performs the ecall selected by a7 using the machine's registers and memory, writing
+// any output to out. It reports whether the program should halt.
+func syscall(registers *[128]uint64, memory []byte, xlen int, out io.Writer) (bool, error) {
+	arg := registers[syscallArgReg]
+	switch number := registers[syscallNumberReg]; number {
+	case SyscallPrintInt:
+		fmt.Fprint(out, signedXLEN(arg, xlen))
+	case SyscallPrintString:
+		if arg >= uint64(len(memory)) {
+			return false, fmt.Errorf("ecall print string: address 0x%X is outside memory", arg)
+		}
+		end := arg
+		for end < uint64(len(memory)) && memory[end] != 0 {
+			end++
+		}
+		fmt.Fprint(out, string(memory[arg:end]))
+	case SyscallExit:
+		return true, nil
+	case SyscallPrintChar:
+		fmt.Fprintf(out, "%c", byte(arg))
+	default:
+		return false, fmt.Errorf("unknown ecall number %d in a7 (supported: 1 print int, 4 print string, "+
+			"10 exit, 11 print char)", number)
+	}
+	return false, nil
+}
+
+// outputOrStdout returns the writer ecall output goes to, defaulting to standard output
+func outputOrStdout(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// SetOutput redirects the output of ecall print syscalls (nil restores standard output)
+func (m *QuantumRISCVMachine) SetOutput(w io.Writer) {
+	m.output = w
+}
+
+// SetOutput redirects the output of ecall print syscalls (nil restores standard output)
+func (m *HostQuantumMachine) SetOutput(w io.Writer) {
+	m.output = w
+}
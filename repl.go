@@ -220,36 +220,36 @@ func (r *REPL) handleGateCommand(args []string) {
 		return
 	}
 
-	var controls []uint8
+	var controls []int
 	for _, arg := range args[2:] {
 		control, err := strconv.Atoi(arg)
 		if err != nil {
 			fmt.Printf("Invalid control qubit: %v\n", err)
 			return
 		}
-		controls = append(controls, uint8(control))
+		controls = append(controls, control)
 	}
 
 	var instruction quantum.Instruction
 	switch gateType {
 	case "X":
-		instruction = quantum.Instruction{Opcode: 0x00, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x00, Target: target, Controls: controls}
 	case "Y":
-		instruction = quantum.Instruction{Opcode: 0x01, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x01, Target: target, Controls: controls}
 	case "Z":
-		instruction = quantum.Instruction{Opcode: 0x02, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x02, Target: target, Controls: controls}
 	case "H":
-		instruction = quantum.Instruction{Opcode: 0x03, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x03, Target: target, Controls: controls}
 	case "S":
-		instruction = quantum.Instruction{Opcode: 0x04, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x04, Target: target, Controls: controls}
 	case "T":
-		instruction = quantum.Instruction{Opcode: 0x05, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x05, Target: target, Controls: controls}
 	case "CNOT":
 		if len(controls) != 1 {
 			fmt.Println("CNOT gate requires exactly one control qubit")
 			return
 		}
-		instruction = quantum.Instruction{Opcode: 0x06, Target: uint8(target), Controls: controls}
+		instruction = quantum.Instruction{Opcode: 0x06, Target: target, Controls: controls}
 	default:
 		fmt.Println("Unknown gate type. Available gates: X, Y, Z, H, S, T, CNOT")
 		return
@@ -282,12 +282,13 @@ func (r *REPL) handleMeasureCommand(args []string) {
 		return
 	}
 
-	if err := r.machine.MeasureQubit(target); err != nil {
+	result, err := r.machine.MeasureQubit(target)
+	if err != nil {
 		fmt.Printf("Error measuring qubit: %v\n", err)
 		return
 	}
 
-	fmt.Printf("Measured qubit %d\n", target)
+	fmt.Printf("Measured qubit %d: %d (p=%.6f)\n", target, result.Outcome, result.Probability)
 }
 
 func (r *REPL) handleStateCommand() {
@@ -8,12 +8,16 @@ import (
 	"strings"
 
 	"qmachine/commands"
+	"qmachine/quantum"
 )
 
 // REPL represents the quantum computer REPL
 type REPL struct {
-	handler *commands.Handler
-	reader  *bufio.Reader
+	handler   *commands.Handler
+	reader    *bufio.Reader
+	session   []string // Successful commands, for export-session
+	seed      int64    // Measurement seed the logged session started from
+	replaying bool     // A replay script is running
 }
 
 // New creates a new REPL instance
@@ -24,6 +28,17 @@ func New(numQubits int) *REPL {
 	}
 }
 
+// SetSeed seeds the measurement RNG of the session, as the seed command does
+func (r *REPL) SetSeed(seed int64) {
+	r.handler.SetSeed(seed)
+}
+
+// SetMachines makes the session drive the given machines instead of default ones, so it runs
+// with their XLEN, RNG, seed, noise and state settings
+func (r *REPL) SetMachines(machine *quantum.QuantumRISCVMachine, hostMachine *quantum.HostQuantumMachine) {
+	r.handler.SetMachines(machine, hostMachine)
+}
+
 // Start begins the REPL session
 func (r *REPL) Start() {
 	fmt.Printf("QMachine Quantum Computer Simulator\n")
@@ -43,12 +58,7 @@ func (r *REPL) Start() {
 			continue
 		}
 
-		// Split input into command and arguments
-		parts := strings.Fields(input)
-		command := parts[0]
-		args := parts[1:]
-
-		if err := r.processCommand(command, args); err != nil {
+		if err := r.execute(input); err != nil {
 			fmt.Printf("Error: %v\n", err)
 		}
 	}
@@ -61,28 +71,132 @@ func (r *REPL) processCommand(command string, args []string) error {
 		fmt.Println("Goodbye!")
 		os.Exit(0)
 	case "help":
+		if len(args) > 0 {
+			return r.handler.HandleDescribe(args)
+		}
 		r.handler.ShowHelp()
+	case "describe":
+		return r.handler.HandleDescribe(args)
 	case "gate":
 		return r.handler.HandleGate(args)
+	case "bell":
+		return r.handler.HandleBell(args)
+	case "ghz":
+		return r.handler.HandleGHZ(args)
+	case "qft":
+		return r.handler.HandleQFT(args, false)
+	case "iqft":
+		return r.handler.HandleQFT(args, true)
+	case "zz-interaction":
+		return r.handler.HandleZZInteraction(args)
+	case "mixer":
+		return r.handler.HandleMixer(args)
+	case "global-phase":
+		return r.handler.HandleGlobalPhase(args)
+	case "cbits":
+		r.handler.HandleClassicalBits()
 	case "measure":
 		return r.handler.HandleMeasure(args)
+	case "reset-qubits":
+		return r.handler.HandleResetQubits(args)
+	case "parity":
+		return r.handler.HandleParity(args)
+	case "force-collapse":
+		return r.handler.HandleForceCollapse(args)
+	case "norm":
+		return r.handler.HandleNorm()
+	case "support-size":
+		return r.handler.HandleSupportSize()
+	case "argmax":
+		return r.handler.HandleArgmax(args)
+	case "probs":
+		return r.handler.HandleProbs(args)
+	case "histogram":
+		return r.handler.HandleHistogram(args)
+	case "measure-shots":
+		return r.handler.HandleMeasureShots(args)
+	case "histogram-all":
+		return r.handler.HandleHistogramAll(args)
+	case "prob-pattern":
+		return r.handler.HandleProbPattern(args)
+	case "seed", "reset-seed":
+		return r.handler.HandleSeed(args)
+	case "entanglement-map":
+		return r.handler.HandleEntanglementMap(args)
+	case "explain-state":
+		return r.handler.HandleExplainState(args)
+	case "phases":
+		return r.handler.HandlePhases(args)
+	case "threshold":
+		return r.handler.HandleThreshold(args)
+	case "precision":
+		return r.handler.HandlePrecision(args)
+	case "bench-gates":
+		return r.handler.HandleBenchGates(args)
+	case "randomized-benchmark":
+		return r.handler.HandleRandomizedBenchmark(args)
+	case "noise":
+		return r.handler.HandleNoise(args)
 	case "state":
-		return r.handler.HandleState()
+		return r.handler.HandleState(args)
+	case "export-qasm":
+		return r.handler.HandleExportQASM(args)
+	case "export-state":
+		return r.handler.HandleExportState(args)
+	case "import-state":
+		return r.handler.HandleImportState(args)
+	case "save-state":
+		return r.handler.HandleSaveState(args)
+	case "load-state":
+		return r.handler.HandleLoadState(args)
 	case "reset":
 		return r.handler.HandleReset()
+	case "circuit":
+		r.handler.HandleCircuit()
+	case "resource-estimate":
+		return r.handler.HandleResourceEstimate(args)
+	case "calc":
+		return r.handler.HandleCalc(args)
+	case "invert":
+		return r.handler.HandleInvert()
 	case "riscv":
 		return r.handler.HandleRISC(args)
 	case "load":
 		return r.handler.HandleLoad(args)
+	case "examples":
+		r.handler.HandleExamples()
+	case "load-example":
+		return r.handler.HandleLoadExample(args)
 	case "run":
 		return r.handler.HandleRun()
+	case "run-value":
+		return r.handler.HandleRunValue(args)
+	case "run-state":
+		return r.handler.HandleRunState(args)
 	case "run-host":
-		r.handler.HandleMode()
-		return r.handler.HandleRun()
+		return r.handler.HandleRunHost()
+	case "export-session":
+		return r.exportSession(args)
+	case "replay":
+		return r.replay(args)
+	case "step":
+		return r.handler.HandleStep(args)
+	case "break":
+		return r.handler.HandleBreak(args)
+	case "continue":
+		return r.handler.HandleContinue()
+	case "list":
+		return r.handler.HandleList()
+	case "stats":
+		return r.handler.HandleStats(args)
 	case "mode":
 		r.handler.HandleMode()
+	case "diff-registers":
+		return r.handler.HandleDiffRegisters(args)
 	case "registers":
 		r.handler.HandleRegisters()
+	case "list-ops":
+		return r.handler.HandleListOps(args)
 	default:
 		return fmt.Errorf("unknown command. Type 'help' for available commands")
 	}
@@ -0,0 +1,114 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// observationalCommands only display state, so exported sessions keep them as comments
+var observationalCommands = map[string]bool{
+	"help": true, "describe": true, "state": true, "registers": true, "phases": true, "norm": true,
+	"prob-pattern": true, "circuit": true, "list-ops": true, "entanglement-map": true, "precision": true,
+	"bench-gates": true, "explain-state": true, "diff-registers": true, "cbits": true, "threshold": true,
+	"export-state": true, "support-size": true, "examples": true, "resource-estimate": true, "argmax": true,
+	"probs": true, "export-qasm": true, "save-state": true, "list": true, "stats": true,
+}
+
+// execute runs one input line and, when it succeeds, appends it to the session log
+func (r *REPL) execute(input string) error {
+	parts := strings.Fields(input)
+	command, args := parts[0], parts[1:]
+	if len(r.session) == 0 {
+		// The logged session starts from a freshly seeded RNG in both modes, so export-session can
+		// reproduce its measurements with a single seed command
+		r.seed = r.handler.Seed()
+		r.handler.SetSeed(r.seed)
+	}
+	if err := r.processCommand(command, args); err != nil {
+		return err
+	}
+	switch {
+	case command == "export-session" || command == "replay":
+		// Session management is not part of the computation
+	case observationalCommands[command]:
+		r.session = append(r.session, "# "+input)
+	default:
+		r.session = append(r.session, input)
+	}
+	return nil
+}
+
+// exportSession writes every successful command of the session to a replayable script, led by
+// the measurement seed the session started from
+func (r *REPL) exportSession(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: export-session <file>")
+	}
+	var script strings.Builder
+	fmt.Fprintf(&script, "# qmachine session exported %s\n", time.Now().Format(time.RFC3339))
+	script.WriteString("# Replay with: replay <file>. Display-only commands are kept as comments.\n")
+	if len(r.session) > 0 {
+		fmt.Fprintf(&script, "seed %d\n", r.seed)
+	}
+	for _, line := range r.session {
+		script.WriteString(line + "\n")
+	}
+	if err := os.WriteFile(args[0], []byte(script.String()), 0644); err != nil {
+		return fmt.Errorf("error writing session: %v", err)
+	}
+	fmt.Printf("Exported %d command(s) to %s\n", len(r.session), args[0])
+	return nil
+}
+
+// replay runs the commands of a script written by export-session (or by hand), stopping at the
+// first failing command
+func (r *REPL) replay(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: replay <file>")
+	}
+	return r.RunScript(args[0], false)
+}
+
+// RunScript runs a file of REPL commands through the same path as typed input, echoing each
+// one. Blank lines and # comments are skipped. It stops at the first failing command unless
+// continueOnError is set; then each failure is printed and the returned error counts them.
+func (r *REPL) RunScript(filename string, continueOnError bool) error {
+	if r.replaying {
+		return fmt.Errorf("replay cannot be nested inside a replayed script")
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error opening script: %v", err)
+	}
+	defer file.Close()
+
+	r.replaying = true
+	defer func() { r.replaying = false }()
+	failed := 0
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" || strings.HasPrefix(input, "#") {
+			continue
+		}
+		fmt.Printf("qmachine> %s\n", input)
+		err := r.execute(input)
+		switch {
+		case err != nil && !continueOnError:
+			return fmt.Errorf("script stopped at %s line %d: %v", filename, line, err)
+		case err != nil:
+			fmt.Printf("Error at %s line %d: %v\n", filename, line, err)
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading script: %v", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d command(s) in %s failed", failed, filename)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package repl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"qmachine/quantum"
+)
+
+// measuredSession runs a session that measures four qubits in superposition on machines seeded
+// with vmSeed and hostSeed, and returns the REPL and the basis state the measurements produced
+func measuredSession(t *testing.T, vmSeed, hostSeed int64, commands ...string) (*REPL, int) {
+	t.Helper()
+	machine, hostMachine := quantum.NewQuantumRISCVMachine(4), quantum.NewHostQuantumMachine(4)
+	machine.SetSeed(vmSeed)
+	hostMachine.SetSeed(hostSeed)
+	r := New(4)
+	r.SetMachines(machine, hostMachine)
+	for _, command := range commands {
+		if err := r.execute(command); err != nil {
+			t.Fatalf("%s: %v", command, err)
+		}
+	}
+	for _, entry := range machine.GetState().NonzeroAmplitudes() {
+		return r, entry.Index
+	}
+	t.Fatal("state has no nonzero amplitude")
+	return r, 0
+}
+
+func TestExportSessionReproducesMeasurements(t *testing.T) {
+	session := []string{"gate H 0", "gate H 1", "gate H 2", "gate H 3", "state",
+		"measure 0", "measure 1", "measure 2", "measure 3"}
+	for seed := int64(1); seed <= 8; seed++ {
+		r, want := measuredSession(t, seed, seed+100, session...)
+		script := filepath.Join(t.TempDir(), "session.txt")
+		if err := r.execute("export-session " + script); err != nil {
+			t.Fatal(err)
+		}
+
+		// The replaying machines start from an unrelated seed
+		_, got := measuredSession(t, 12345, 54321, "replay "+script)
+		if got != want {
+			t.Errorf("seed %d: replay measured |%04b⟩, the session measured |%04b⟩", seed, got, want)
+		}
+	}
+}